@@ -4,7 +4,6 @@ go 1.24.3
 
 require (
 	github.com/go-faker/faker/v4 v4.6.1
-	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/gorilla/mux v1.8.1
 	github.com/jackc/pgx/v5 v5.7.4
@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// Issuer is put in every access token and required when verifying one
+	Issuer = "HomeBerries"
+
+	keyFileExt = ".pem"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no private key to sign tokens with")
+	ErrUnknownKey   = errors.New("token signed with an unknown key")
+)
+
+// Signer signs access tokens with the active key
+type Signer interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+// Verifier checks the signature and the standard claims of an access token
+type Verifier interface {
+	Verify(tokenString string) (jwt.MapClaims, error)
+}
+
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// KeyStore holds every key tokens may be signed with. Only the active key signs new tokens,
+// the others are kept so that tokens issued before a rotation stay valid until they expire.
+type KeyStore struct {
+	keys   map[string]*key
+	active *key
+}
+
+// LoadKeyStore reads every *.pem file of dir. The file name without the extension is the key ID.
+// Files may hold RSA or Ed25519 private keys, or public keys of retired keys that only verify.
+// activeID selects the signing key; when empty, the private key with the greatest ID is used,
+// so naming files by date makes the newest key the active one.
+func LoadKeyStore(dir, activeID string) (*KeyStore, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExt))
+	if err != nil {
+		return nil, fmt.Errorf("error listing key files: %w", err)
+	}
+
+	ks := &KeyStore{keys: make(map[string]*key)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading key file %s: %w", path, err)
+		}
+
+		k, err := parseKey(strings.TrimSuffix(filepath.Base(path), keyFileExt), data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing key file %s: %w", path, err)
+		}
+		ks.keys[k.id] = k
+	}
+
+	if err := ks.activate(activeID); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// NewEphemeralKeyStore generates a single Ed25519 key kept in memory only.
+// Tokens it signs become invalid on restart, so it is meant for development and tests.
+func NewEphemeralKeyStore() (*KeyStore, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generating key: %w", err)
+	}
+
+	k := &key{id: "ephemeral", method: jwt.SigningMethodEdDSA, private: private, public: public}
+	return &KeyStore{keys: map[string]*key{k.id: k}, active: k}, nil
+}
+
+func (ks *KeyStore) activate(activeID string) error {
+	if activeID != "" {
+		k, ok := ks.keys[activeID]
+		if !ok || k.private == nil {
+			return fmt.Errorf("%w: %q", ErrNoSigningKey, activeID)
+		}
+		ks.active = k
+		return nil
+	}
+
+	ids := make([]string, 0, len(ks.keys))
+	for id, k := range ks.keys {
+		if k.private != nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return ErrNoSigningKey
+	}
+
+	sort.Strings(ids)
+	ks.active = ks.keys[ids[len(ids)-1]]
+
+	return nil
+}
+
+// ActiveKeyID returns the ID of the key new tokens are signed with
+func (ks *KeyStore) ActiveKeyID() string {
+	return ks.active.id
+}
+
+func (ks *KeyStore) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.id
+
+	return token.SignedString(ks.active.private)
+}
+
+func (ks *KeyStore) Verify(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, ks.keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (ks *KeyStore) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	// A key only verifies tokens of its own algorithm
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+
+	return k.public, nil
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public part of every key, ordered by key ID
+func (ks *KeyStore) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		jwk := JWK{KeyID: k.id, Use: "sig", Algorithm: k.method.Alg()}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+
+	return set
+}
+
+func parseKey(id string, data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	k := &key{id: id}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", parsed)
+		}
+		k.private = signer
+		k.public = signer.Public()
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k.private = parsed
+		k.public = parsed.Public()
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k.public = parsed
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	switch k.public.(type) {
+	case *rsa.PublicKey:
+		k.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, only RSA and Ed25519 keys are allowed", k.public)
+	}
+
+	return k, nil
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name+keyFileExt), data, 0o600))
+}
+
+func writeRSAKey(t *testing.T, dir, name string) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	writePEM(t, dir, name, "PRIVATE KEY", der)
+}
+
+func writeEd25519Key(t *testing.T, dir, name string) ed25519.PublicKey {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	writePEM(t, dir, name, "PRIVATE KEY", der)
+	return public
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"email": "user@example.com",
+		"iss":   Issuer,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}
+}
+
+func TestKeyStoreSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "2026-01-rsa")
+	writeEd25519Key(t, dir, "2026-02-ed")
+
+	ks, err := LoadKeyStore(dir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-02-ed", ks.ActiveKeyID(), "the greatest key ID is active by default")
+
+	token, err := ks.Sign(testClaims())
+	assert.NoError(t, err)
+
+	claims, err := ks.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", claims["email"])
+
+	// Tokens signed before a rotation stay valid while the old key is loaded
+	rsaStore, err := LoadKeyStore(dir, "2026-01-rsa")
+	assert.NoError(t, err)
+	oldToken, err := rsaStore.Sign(testClaims())
+	assert.NoError(t, err)
+	_, err = ks.Verify(oldToken)
+	assert.NoError(t, err)
+}
+
+func TestKeyStoreRejectsInvalidTokens(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "current")
+
+	ks, err := LoadKeyStore(dir, "")
+	assert.NoError(t, err)
+
+	other, err := NewEphemeralKeyStore()
+	assert.NoError(t, err)
+	foreign, err := other.Sign(testClaims())
+	assert.NoError(t, err)
+	_, err = ks.Verify(foreign)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// A shared secret must not be accepted in place of a key pair
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	hmacToken.Header["kid"] = "current"
+	signed, err := hmacToken.SignedString([]byte("secret"))
+	assert.NoError(t, err)
+	_, err = ks.Verify(signed)
+	assert.Error(t, err)
+
+	expired := testClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	token, err := ks.Sign(expired)
+	assert.NoError(t, err)
+	_, err = ks.Verify(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	wrongIssuer := testClaims()
+	wrongIssuer["iss"] = "someone-else"
+	token, err = ks.Sign(wrongIssuer)
+	assert.NoError(t, err)
+	_, err = ks.Verify(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+}
+
+func TestKeyStoreRetiredPublicKey(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "b-current")
+
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(public)
+	assert.NoError(t, err)
+	writePEM(t, dir, "c-retired", "PUBLIC KEY", der)
+
+	ks, err := LoadKeyStore(dir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b-current", ks.ActiveKeyID(), "public keys are never used for signing")
+
+	_, err = LoadKeyStore(dir, "c-retired")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+
+	_, err = LoadKeyStore(t.TempDir(), "")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestKeyStoreJWKS(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "a-rsa")
+	public := writeEd25519Key(t, dir, "b-ed")
+
+	ks, err := LoadKeyStore(dir, "")
+	assert.NoError(t, err)
+
+	set := ks.JWKS()
+	if assert.Len(t, set.Keys, 2) {
+		assert.Equal(t, "a-rsa", set.Keys[0].KeyID)
+		assert.Equal(t, "RSA", set.Keys[0].KeyType)
+		assert.Equal(t, "RS256", set.Keys[0].Algorithm)
+		assert.Equal(t, "AQAB", set.Keys[0].E)
+		assert.NotEmpty(t, set.Keys[0].N)
+
+		assert.Equal(t, "b-ed", set.Keys[1].KeyID)
+		assert.Equal(t, "OKP", set.Keys[1].KeyType)
+		assert.Equal(t, "Ed25519", set.Keys[1].Curve)
+		assert.Equal(t, "EdDSA", set.Keys[1].Algorithm)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(public), set.Keys[1].X)
+	}
+}
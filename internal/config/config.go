@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,10 +15,20 @@ import (
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Auth     AuthConfig
+	Mailer   MailerConfig
+	Account  AccountConfig
+	OIDC     OIDCConfig
+	Storage  StorageConfig
+	Cart     CartConfig
+	Orders   OrderConfig
+	Jobs     JobsConfig
 }
 
 type ServerConfig struct {
 	Port string
+	// TrustedProxies lists the peers whose X-Real-IP header is taken as the client address
+	TrustedProxies []netip.Prefix
 }
 
 type DatabaseConfig struct {
@@ -25,6 +38,88 @@ type DatabaseConfig struct {
 	MaxConnLifetime time.Duration
 }
 
+type AuthConfig struct {
+	AccessTokenTTL           time.Duration
+	RefreshTokenTTL          time.Duration
+	VerificationTokenTTL     time.Duration
+	PasswordResetTokenTTL    time.Duration
+	RequireEmailVerification bool
+	AppBaseURL               string
+	JWTKeysDir               string
+	JWTActiveKeyID           string
+	LoginMaxAttempts         int
+	LoginMaxAttemptsPerIP    int
+	LoginAttemptWindow       time.Duration
+	LoginLockoutBase         time.Duration
+	LoginLockoutMax          time.Duration
+}
+
+type MailerConfig struct {
+	Driver string
+	Dir    string
+	From   string
+}
+
+// What happens to the products of a seller who deletes their account
+const (
+	DeletedSellerProductsHide   = "hide"
+	DeletedSellerProductsDelete = "delete"
+)
+
+type AccountConfig struct {
+	DeletedSellerProducts string
+}
+
+// OIDCProviderConfig describes an external OpenID Connect provider users can sign in with.
+// Endpoints are discovered from IssuerURL.
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	// LoginTTL is how long a user has to complete the login at the provider
+	LoginTTL time.Duration
+}
+
+// StorageConfig describes where uploaded files are kept. The local driver writes them
+// to Dir, which must be served at PublicURL.
+type StorageConfig struct {
+	Driver    string
+	Dir       string
+	PublicURL string
+	// MaxImageSize is the largest product image accepted, in bytes
+	MaxImageSize int64
+}
+
+type CartConfig struct {
+	// TTL is how long a cart is kept after its last change
+	TTL time.Duration
+}
+
+type OrderConfig struct {
+	// ReservationTTL is how long checkout holds the stock of a pending order for its payment
+	ReservationTTL time.Duration
+}
+
+// JobsConfig sets how often the background jobs run
+type JobsConfig struct {
+	// PublishInterval is how often scheduled products are published, and so how late they can be
+	PublishInterval time.Duration
+	// PurgeInterval is how often deleted products past their retention are removed for good
+	PurgeInterval time.Duration
+	// DeletedProductRetention is how long a deleted product can still be restored
+	DeletedProductRetention time.Duration
+	// ReleaseInterval is how often pending orders past their reservation are cancelled,
+	// so their stock can be sold again
+	ReleaseInterval time.Duration
+}
+
 type Option func(*Config)
 
 func LoadConfig() (*Config, error) {
@@ -43,8 +138,84 @@ func LoadConfig() (*Config, error) {
 		WithMaxConnections(parseInt32(getEnv("DB_MAX_CONNECTIONS", "10"))),
 		WithMinConnections(parseInt32(getEnv("DB_MIN_CONNECTIONS", "2"))),
 		WithMaxConnLifetime(parseDuration(getEnv("DB_MAX_CONN_LIFETIME", "1h"))),
+		WithAccessTokenTTL(parseDuration(getEnv("ACCESS_TOKEN_TTL", "15m"))),
+		WithRefreshTokenTTL(parseDuration(getEnv("REFRESH_TOKEN_TTL", "168h"))),
+		WithVerificationTokenTTL(parseDuration(getEnv("VERIFICATION_TOKEN_TTL", "24h"))),
+		WithPasswordResetTokenTTL(parseDuration(getEnv("PASSWORD_RESET_TOKEN_TTL", "1h"))),
+		WithRequireEmailVerification(parseBool(getEnv("REQUIRE_EMAIL_VERIFICATION", "false"))),
+		WithAppBaseURL(getEnv("APP_BASE_URL", "http://localhost")),
+		WithJWTKeys(getEnv("JWT_KEYS_DIR", ""), getEnv("JWT_ACTIVE_KID", "")),
+		WithLoginLimits(
+			int(parseInt32(getEnv("LOGIN_MAX_ATTEMPTS", "5"))),
+			int(parseInt32(getEnv("LOGIN_MAX_ATTEMPTS_PER_IP", "50"))),
+		),
+		WithLoginLockout(
+			parseDuration(getEnv("LOGIN_ATTEMPT_WINDOW", "1h")),
+			parseDuration(getEnv("LOGIN_LOCKOUT_BASE", "1m")),
+			parseDuration(getEnv("LOGIN_LOCKOUT_MAX", "1h")),
+		),
+		WithMailer(getEnv("MAILER_DRIVER", "log"), getEnv("MAILER_DIR", "./mail"), getEnv("MAIL_FROM", "noreply@localhost")),
+		WithDeletedSellerProducts(getEnv("DELETED_SELLER_PRODUCTS", DeletedSellerProductsHide)),
+		WithOIDCLoginTTL(parseDuration(getEnv("OIDC_LOGIN_TTL", "10m"))),
+		WithStorage(getEnv("STORAGE_DRIVER", "local"), getEnv("STORAGE_DIR", "./static"), getEnv("STORAGE_PUBLIC_URL", "")),
+		WithMaxImageSize(int64(parseInt32(getEnv("MAX_IMAGE_SIZE", "5242880")))),
+		WithCartTTL(parseDuration(getEnv("CART_TTL", "168h"))),
+		WithPublishInterval(parseDuration(getEnv("PUBLISH_SCHEDULED_INTERVAL", "1m"))),
+		WithDeletedProductPurge(
+			parseDuration(getEnv("PURGE_DELETED_INTERVAL", "1h")),
+			parseDuration(getEnv("DELETED_PRODUCT_RETENTION", "720h")),
+		),
+		WithStockReservation(
+			parseDuration(getEnv("STOCK_RESERVATION_TTL", "15m")),
+			parseDuration(getEnv("RELEASE_RESERVATIONS_INTERVAL", "1m")),
+		),
 	)
 
+	// nginx serves the static directory under /static
+	if cfg.Storage.PublicURL == "" {
+		cfg.Storage.PublicURL = strings.TrimSuffix(cfg.Auth.AppBaseURL, "/") + "/static"
+	}
+
+	proxies, err := loadTrustedProxies()
+	if err != nil {
+		return nil, err
+	}
+	WithTrustedProxies(proxies)(cfg)
+
+	providers, err := loadOIDCProviders(cfg.Auth.AppBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	for _, provider := range providers {
+		WithOIDCProvider(provider)(cfg)
+	}
+
+	if cfg.Cart.TTL <= 0 {
+		return nil, fmt.Errorf("invalid CART_TTL: must be positive")
+	}
+	if cfg.Jobs.PublishInterval <= 0 {
+		return nil, fmt.Errorf("invalid PUBLISH_SCHEDULED_INTERVAL: must be positive")
+	}
+	if cfg.Jobs.PurgeInterval <= 0 {
+		return nil, fmt.Errorf("invalid PURGE_DELETED_INTERVAL: must be positive")
+	}
+	if cfg.Jobs.DeletedProductRetention <= 0 {
+		return nil, fmt.Errorf("invalid DELETED_PRODUCT_RETENTION: must be positive")
+	}
+	if cfg.Orders.ReservationTTL <= 0 {
+		return nil, fmt.Errorf("invalid STOCK_RESERVATION_TTL: must be positive")
+	}
+	if cfg.Jobs.ReleaseInterval <= 0 {
+		return nil, fmt.Errorf("invalid RELEASE_RESERVATIONS_INTERVAL: must be positive")
+	}
+
+	switch cfg.Account.DeletedSellerProducts {
+	case DeletedSellerProductsHide, DeletedSellerProductsDelete:
+	default:
+		return nil, fmt.Errorf("invalid DELETED_SELLER_PRODUCTS %q: must be %q or %q",
+			cfg.Account.DeletedSellerProducts, DeletedSellerProductsHide, DeletedSellerProductsDelete)
+	}
+
 	return cfg, nil
 }
 
@@ -64,6 +235,12 @@ func WithServerPort(port string) Option {
 	}
 }
 
+func WithTrustedProxies(proxies []netip.Prefix) Option {
+	return func(c *Config) {
+		c.Server.TrustedProxies = proxies
+	}
+}
+
 func WithDatabaseURL(url string) Option {
 	return func(c *Config) {
 		c.Database.URL = url
@@ -88,6 +265,196 @@ func WithMaxConnLifetime(duration time.Duration) Option {
 	}
 }
 
+func WithAccessTokenTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.Auth.AccessTokenTTL = ttl
+	}
+}
+
+func WithRefreshTokenTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.Auth.RefreshTokenTTL = ttl
+	}
+}
+
+func WithVerificationTokenTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.Auth.VerificationTokenTTL = ttl
+	}
+}
+
+func WithPasswordResetTokenTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.Auth.PasswordResetTokenTTL = ttl
+	}
+}
+
+func WithRequireEmailVerification(required bool) Option {
+	return func(c *Config) {
+		c.Auth.RequireEmailVerification = required
+	}
+}
+
+func WithAppBaseURL(url string) Option {
+	return func(c *Config) {
+		c.Auth.AppBaseURL = url
+	}
+}
+
+// WithJWTKeys sets the directory of the token signing keys and the ID of the key
+// new tokens are signed with. An empty ID selects the newest key.
+func WithJWTKeys(dir, activeID string) Option {
+	return func(c *Config) {
+		c.Auth.JWTKeysDir = dir
+		c.Auth.JWTActiveKeyID = activeID
+	}
+}
+
+// WithLoginLimits sets how many failed logins in a row are allowed per email and per client IP
+func WithLoginLimits(perEmail, perIP int) Option {
+	return func(c *Config) {
+		c.Auth.LoginMaxAttempts = perEmail
+		c.Auth.LoginMaxAttemptsPerIP = perIP
+	}
+}
+
+// WithLoginLockout sets how long failures are remembered and the bounds of the lockout,
+// which doubles with every failure over the limit
+func WithLoginLockout(window, base, max time.Duration) Option {
+	return func(c *Config) {
+		c.Auth.LoginAttemptWindow = window
+		c.Auth.LoginLockoutBase = base
+		c.Auth.LoginLockoutMax = max
+	}
+}
+
+func WithMailer(driver, dir, from string) Option {
+	return func(c *Config) {
+		c.Mailer.Driver = driver
+		c.Mailer.Dir = dir
+		c.Mailer.From = from
+	}
+}
+
+func WithDeletedSellerProducts(mode string) Option {
+	return func(c *Config) {
+		c.Account.DeletedSellerProducts = mode
+	}
+}
+
+// WithOIDCProvider adds a provider to sign in with. Providers are told apart by name.
+func WithOIDCProvider(provider OIDCProviderConfig) Option {
+	return func(c *Config) {
+		c.OIDC.Providers = append(c.OIDC.Providers, provider)
+	}
+}
+
+func WithOIDCLoginTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.OIDC.LoginTTL = ttl
+	}
+}
+
+func WithStorage(driver, dir, publicURL string) Option {
+	return func(c *Config) {
+		c.Storage.Driver = driver
+		c.Storage.Dir = dir
+		c.Storage.PublicURL = publicURL
+	}
+}
+
+func WithMaxImageSize(size int64) Option {
+	return func(c *Config) {
+		c.Storage.MaxImageSize = size
+	}
+}
+
+func WithCartTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.Cart.TTL = ttl
+	}
+}
+
+func WithPublishInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.Jobs.PublishInterval = interval
+	}
+}
+
+// WithDeletedProductPurge sets how often deleted products are purged and how long they are kept before
+func WithDeletedProductPurge(interval, retention time.Duration) Option {
+	return func(c *Config) {
+		c.Jobs.PurgeInterval = interval
+		c.Jobs.DeletedProductRetention = retention
+	}
+}
+
+// WithStockReservation sets how long checkout reserves stock and how often expired reservations are released
+func WithStockReservation(ttl, releaseInterval time.Duration) Option {
+	return func(c *Config) {
+		c.Orders.ReservationTTL = ttl
+		c.Jobs.ReleaseInterval = releaseInterval
+	}
+}
+
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS. Every provider is configured
+// with OIDC_<NAME>_ISSUER_URL, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and _SCOPES.
+func loadOIDCProviders(appBaseURL string) ([]OIDCProviderConfig, error) {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER_URL", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", appBaseURL+"/auth/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		}
+
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs %sISSUER_URL and %sCLIENT_ID", name, prefix, prefix)
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+// loadTrustedProxies reads TRUSTED_PROXIES, a comma separated list of addresses or CIDR ranges.
+func loadTrustedProxies() ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, entry := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", entry, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", entry, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+
+	return proxies, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -104,6 +471,14 @@ func parseInt32(s string) int32 {
 	return i
 }
 
+func parseBool(s string) bool {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false
+	}
+	return b
+}
+
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigStockReservation(t *testing.T) {
+	tests := []struct {
+		name            string
+		env             map[string]string
+		expectedTTL     time.Duration
+		expectedRelease time.Duration
+		expectedErr     string
+	}{
+		{
+			name:            "Defaults",
+			expectedTTL:     15 * time.Minute,
+			expectedRelease: time.Minute,
+		},
+		{
+			name:            "Custom values",
+			env:             map[string]string{"STOCK_RESERVATION_TTL": "30m", "RELEASE_RESERVATIONS_INTERVAL": "30s"},
+			expectedTTL:     30 * time.Minute,
+			expectedRelease: 30 * time.Second,
+		},
+		{
+			name:        "Zero reservation TTL",
+			env:         map[string]string{"STOCK_RESERVATION_TTL": "0s"},
+			expectedErr: "invalid STOCK_RESERVATION_TTL: must be positive",
+		},
+		{
+			name:        "Negative release interval",
+			env:         map[string]string{"RELEASE_RESERVATIONS_INTERVAL": "-1m"},
+			expectedErr: "invalid RELEASE_RESERVATIONS_INTERVAL: must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := LoadConfig()
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expectedTTL, cfg.Orders.ReservationTTL)
+			assert.Equal(t, tt.expectedRelease, cfg.Jobs.ReleaseInterval)
+		})
+	}
+}
+
+func TestLoadConfigTrustedProxies(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("TRUSTED_PROXIES", "172.16.0.0/12, 10.0.0.7")
+
+	cfg, err := LoadConfig()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("172.16.0.0/12"),
+		netip.MustParsePrefix("10.0.0.7/32"),
+	}, cfg.Server.TrustedProxies)
+
+	t.Setenv("TRUSTED_PROXIES", "nginx")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, `invalid TRUSTED_PROXIES entry "nginx"`)
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type AccountController struct {
+	accSrvc service.AccountService
+	usrSrvc service.UserService
+}
+
+func NewAccountController(serviceAcc service.AccountService, serviceUs service.UserService) *AccountController {
+	return &AccountController{
+		accSrvc: serviceAcc,
+		usrSrvc: serviceUs,
+	}
+}
+
+func (c *AccountController) protectedRoutes() []route {
+	return []route{
+		{"/user/me/export", "GET", c.ExportUserData, anyRole, noAPIKey},
+		{"/user/me", "DELETE", c.DeleteAccount, anyRole, noAPIKey},
+	}
+}
+
+func (c *AccountController) RegisterRoutes(router *mux.Router, authMiddleware mux.MiddlewareFunc) {
+	accountRouter := router.PathPrefix("").Subrouter()
+	accountRouter.Use(authMiddleware)
+
+	for _, rt := range c.protectedRoutes() {
+		accountRouter.Handle(rt.path, rt.guarded()).Methods(rt.method)
+	}
+}
+
+func (c *AccountController) ExportUserData(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ExportUserData"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	export, err := c.accSrvc.ExportUserData(ctx, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="user-%d-export.json"`, curUser.ID))
+	utils.RespondWithJSON(w, http.StatusOK, export)
+}
+
+func (c *AccountController) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.DeleteAccount"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	var deleteReq model.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&deleteReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if deleteReq.Password == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Password is required")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	err = c.accSrvc.DeleteAccount(ctx, *curUser, deleteReq.Password)
+	if errors.Is(err, service.ErrWrongPassword) {
+		utils.RespondWithError(w, http.StatusForbidden, "Password is incorrect")
+		return
+	}
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Account deleted"})
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+)
+
+func TestExportUserData(t *testing.T) {
+	mockAccountService := service.NewMockAccountService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAccountController(mockAccountService, mockUserService)
+
+	testUser := UserFactory{Role: "seller"}.Build()
+	returnID, itemID := int64(3), int64(5)
+	export := &model.UserExport{
+		Profile: *testUser,
+		Orders: []model.Order{{
+			ID:     10,
+			UserID: testUser.ID,
+			Status: model.OrderStatusDelivered,
+			Items:  []model.OrderItem{{ID: itemID, Title: "Lamp", Quantity: 2, ReturnedQuantity: 1}},
+			Refunds: []model.Refund{{ID: 4, OrderID: 10, ReturnID: &returnID, OrderItemID: &itemID,
+				Amount: 1500}},
+		}},
+		Returns: []model.OrderReturn{{ID: returnID, OrderID: 10, OrderItemID: itemID, Title: "Lamp",
+			Quantity: 1}},
+	}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Success - export downloaded",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockAccountService.On("ExportUserData", mock.Anything, *testUser).
+					Return(export, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Service error",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockAccountService.On("ExportUserData", mock.Anything, *testUser).
+					Return(nil, errors.New("redis is down")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("GET", "/user/me/export", nil)
+			claims := jwt.MapClaims{"email": testUser.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.ExportUserData(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+
+				var body model.UserExport
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+				assert.Equal(t, export.Orders, body.Orders)
+				assert.Equal(t, export.Returns, body.Returns)
+			}
+			mockAccountService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	mockAccountService := service.NewMockAccountService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAccountController(mockAccountService, mockUserService)
+
+	testUser := UserFactory{Role: "customer"}.Build()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - account deleted",
+			requestBody: `{"password": "secret"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockAccountService.On("DeleteAccount", mock.Anything, *testUser, "secret").
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Wrong password",
+			requestBody: `{"password": "wrong"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockAccountService.On("DeleteAccount", mock.Anything, *testUser, "wrong").
+					Return(service.ErrWrongPassword).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Missing password",
+			requestBody:    `{}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("DELETE", "/user/me", bytes.NewBufferString(tt.requestBody))
+			claims := jwt.MapClaims{"email": testUser.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.DeleteAccount(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockAccountService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+var adminRoles = []string{model.RoleAdmin}
+
+type AdminController struct {
+	admSrvc service.AdminService
+	usrSrvc service.UserService
+}
+
+func NewAdminController(serviceAdm service.AdminService, serviceUs service.UserService) *AdminController {
+	return &AdminController{
+		admSrvc: serviceAdm,
+		usrSrvc: serviceUs,
+	}
+}
+
+func (c *AdminController) protectedRoutes() []route {
+	return []route{
+		{"/admin/users", "GET", c.ListUsers, adminRoles, noAPIKey},
+		{"/admin/users/{id}/ban", "POST", c.BanUser, adminRoles, noAPIKey},
+		{"/admin/users/{id}/unban", "POST", c.UnbanUser, adminRoles, noAPIKey},
+		{"/admin/users/{id}/role", "PUT", c.ChangeUserRole, adminRoles, noAPIKey},
+		{"/admin/users/{id}/unlock", "POST", c.UnlockUser, adminRoles, noAPIKey},
+		{"/admin/users/{id}/cart", "GET", c.GetUserCart, adminRoles, noAPIKey},
+
+		{"/admin/products", "GET", c.ListProducts, adminRoles, noAPIKey},
+		{"/admin/products/{id}", "DELETE", c.DeleteProduct, adminRoles, noAPIKey},
+		{"/admin/products/{id}/hide", "POST", c.HideProduct, adminRoles, noAPIKey},
+		{"/admin/products/{id}/unhide", "POST", c.UnhideProduct, adminRoles, noAPIKey},
+	}
+}
+
+func (c *AdminController) RegisterRoutes(router *mux.Router, authMiddleware mux.MiddlewareFunc) {
+	adminRouter := router.PathPrefix("").Subrouter()
+	adminRouter.Use(authMiddleware)
+
+	for _, rt := range c.protectedRoutes() {
+		adminRouter.Handle(rt.path, rt.guarded()).Methods(rt.method)
+	}
+}
+
+func (c *AdminController) ListUsers(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ListUsers"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := model.UserFilter{
+		Query:  r.URL.Query().Get("q"),
+		Role:   r.URL.Query().Get("role"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	users, err := c.admSrvc.ListUsers(ctx, filter)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if users == nil {
+		users = []model.User{}
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, users)
+}
+
+func (c *AdminController) BanUser(w http.ResponseWriter, r *http.Request) {
+	c.setUserBanned(w, r, true)
+}
+
+func (c *AdminController) UnbanUser(w http.ResponseWriter, r *http.Request) {
+	c.setUserBanned(w, r, false)
+}
+
+func (c *AdminController) setUserBanned(w http.ResponseWriter, r *http.Request, banned bool) {
+
+	const op = "controller.setUserBanned"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	userID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	admin, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	if banned {
+		err = c.admSrvc.BanUser(ctx, *admin, userID)
+	} else {
+		err = c.admSrvc.UnbanUser(ctx, *admin, userID)
+	}
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":     userID,
+		"banned": banned,
+	})
+}
+
+func (c *AdminController) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ChangeUserRole"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	userID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	var roleReq model.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&roleReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	admin, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	err = c.admSrvc.ChangeUserRole(ctx, *admin, userID, roleReq.Role)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":   userID,
+		"role": roleReq.Role,
+	})
+}
+
+func (c *AdminController) UnlockUser(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UnlockUser"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	userID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	err = c.admSrvc.UnlockUser(ctx, userID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":       userID,
+		"unlocked": true,
+	})
+}
+
+func (c *AdminController) GetUserCart(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.GetUserCart"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	userID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	items, err := c.admSrvc.GetUserCart(ctx, userID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	if items == nil {
+		items = []model.CartItem{}
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, items)
+}
+
+func (c *AdminController) ListProducts(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.AdminListProducts"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := model.AdminProductFilter{
+		Query:      r.URL.Query().Get("q"),
+		HiddenOnly: r.URL.Query().Get("hidden") == "true",
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	if v := r.URL.Query().Get("seller_id"); v != "" {
+		filter.SellerID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid seller id")
+			return
+		}
+	}
+
+	products, err := c.admSrvc.ListProducts(ctx, filter)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if products == nil {
+		products = []model.Product{}
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, products)
+}
+
+func (c *AdminController) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.AdminDeleteProduct"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	err = c.admSrvc.DeleteProduct(ctx, productID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product deleted successfully"})
+}
+
+func (c *AdminController) HideProduct(w http.ResponseWriter, r *http.Request) {
+	c.setProductHidden(w, r, true)
+}
+
+func (c *AdminController) UnhideProduct(w http.ResponseWriter, r *http.Request) {
+	c.setProductHidden(w, r, false)
+}
+
+func (c *AdminController) setProductHidden(w http.ResponseWriter, r *http.Request, hidden bool) {
+
+	const op = "controller.setProductHidden"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	err = c.admSrvc.SetProductHidden(ctx, productID, hidden)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":     productID,
+		"hidden": hidden,
+	})
+}
@@ -0,0 +1,352 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+)
+
+func TestAdminListUsers(t *testing.T) {
+	mockAdminService := service.NewMockAdminService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAdminController(mockAdminService, mockUserService)
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:  "Success - search sellers",
+			query: "?q=elon&role=seller&limit=10&offset=20",
+			mockSetup: func() {
+				filter := model.UserFilter{Query: "elon", Role: "seller", Limit: 10, Offset: 20}
+				mockAdminService.On("ListUsers", mock.Anything, filter).
+					Return([]model.User{*UserFactory{Role: "seller"}.Build()}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Limit is capped",
+			query: "?limit=100000",
+			mockSetup: func() {
+				filter := model.UserFilter{Limit: maxPageLimit}
+				mockAdminService.On("ListUsers", mock.Anything, filter).
+					Return(nil, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid offset",
+			query:          "?offset=-1",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("GET", "/admin/users"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			controller.ListUsers(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockAdminService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminBanUser(t *testing.T) {
+	mockAdminService := service.NewMockAdminService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAdminController(mockAdminService, mockUserService)
+
+	testAdmin := UserFactory{Role: "admin"}.Build()
+
+	tests := []struct {
+		name           string
+		userID         string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "Success - ban user",
+			userID: "3",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testAdmin.Email).
+					Return(testAdmin, nil).Once()
+				mockAdminService.On("BanUser", mock.Anything, *testAdmin, int64(3)).
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Cannot ban yourself",
+			userID: "1",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testAdmin.Email).
+					Return(testAdmin, nil).Once()
+				mockAdminService.On("BanUser", mock.Anything, *testAdmin, int64(1)).
+					Return(service.ErrCannotModifySelf).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "User not found",
+			userID: "404",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testAdmin.Email).
+					Return(testAdmin, nil).Once()
+				mockAdminService.On("BanUser", mock.Anything, *testAdmin, int64(404)).
+					Return(service.ErrUserNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid user ID",
+			userID:         "abc",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/admin/users/"+tt.userID+"/ban", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.userID})
+
+			claims := jwt.MapClaims{"email": testAdmin.Email}
+			ctx := context.WithValue(req.Context(), "userClaims", claims)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			controller.BanUser(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockAdminService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminChangeUserRole(t *testing.T) {
+	mockAdminService := service.NewMockAdminService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAdminController(mockAdminService, mockUserService)
+
+	testAdmin := UserFactory{Role: "admin"}.Build()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - promote to seller",
+			requestBody: `{"role": "seller"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testAdmin.Email).
+					Return(testAdmin, nil).Once()
+				mockAdminService.On("ChangeUserRole", mock.Anything, *testAdmin, int64(5), "seller").
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Invalid role",
+			requestBody: `{"role": "superuser"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testAdmin.Email).
+					Return(testAdmin, nil).Once()
+				mockAdminService.On("ChangeUserRole", mock.Anything, *testAdmin, int64(5), "superuser").
+					Return(service.ErrInvalidRole).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid JSON",
+			requestBody:    `{ invalid json }`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("PUT", "/admin/users/5/role", bytes.NewBufferString(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+			claims := jwt.MapClaims{"email": testAdmin.Email}
+			ctx := context.WithValue(req.Context(), "userClaims", claims)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			controller.ChangeUserRole(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockAdminService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHideProduct(t *testing.T) {
+	mockAdminService := service.NewMockAdminService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAdminController(mockAdminService, mockUserService)
+
+	tests := []struct {
+		name           string
+		productID      string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:      "Success - hide product",
+			productID: "2",
+			mockSetup: func() {
+				mockAdminService.On("SetProductHidden", mock.Anything, int64(2), true).
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "Product not found",
+			productID: "999",
+			mockSetup: func() {
+				mockAdminService.On("SetProductHidden", mock.Anything, int64(999), true).
+					Return(service.ErrProductNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/admin/products/"+tt.productID+"/hide", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+			rr := httptest.NewRecorder()
+			controller.HideProduct(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockAdminService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminGetUserCart(t *testing.T) {
+	mockAdminService := service.NewMockAdminService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAdminController(mockAdminService, mockUserService)
+
+	tests := []struct {
+		name           string
+		userID         string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "Success - cart contents",
+			userID: "3",
+			mockSetup: func() {
+				mockAdminService.On("GetUserCart", mock.Anything, int64(3)).
+					Return([]model.CartItem{{ProductID: 1, Title: "TV", Price: 100, Quantity: 1}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Service error",
+			userID: "4",
+			mockSetup: func() {
+				mockAdminService.On("GetUserCart", mock.Anything, int64(4)).
+					Return(nil, errors.New("redis is down")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("GET", "/admin/users/"+tt.userID+"/cart", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.userID})
+			rr := httptest.NewRecorder()
+			controller.GetUserCart(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockAdminService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminUnlockUser(t *testing.T) {
+	mockAdminService := service.NewMockAdminService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAdminController(mockAdminService, mockUserService)
+
+	tests := []struct {
+		name           string
+		userID         string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "Success - lockout cleared",
+			userID: "3",
+			mockSetup: func() {
+				mockAdminService.On("UnlockUser", mock.Anything, int64(3)).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "User not found",
+			userID: "404",
+			mockSetup: func() {
+				mockAdminService.On("UnlockUser", mock.Anything, int64(404)).
+					Return(service.ErrUserNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			userID:         "abc",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/admin/users/"+tt.userID+"/unlock", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.userID})
+			rr := httptest.NewRecorder()
+			controller.UnlockUser(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockAdminService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type APIKeyController struct {
+	keySrvc service.APIKeyService
+	usrSrvc service.UserService
+}
+
+func NewAPIKeyController(serviceKey service.APIKeyService, serviceUs service.UserService) *APIKeyController {
+	return &APIKeyController{
+		keySrvc: serviceKey,
+		usrSrvc: serviceUs,
+	}
+}
+
+// API keys are managed with an access token only, a leaked key must not be able to mint new ones
+func (c *APIKeyController) protectedRoutes() []route {
+	return []route{
+		{"/user/api-keys", "POST", c.CreateAPIKey, sellerRoles, noAPIKey},
+		{"/user/api-keys", "GET", c.ListAPIKeys, sellerRoles, noAPIKey},
+		{"/user/api-keys/{id}", "DELETE", c.RevokeAPIKey, sellerRoles, noAPIKey},
+	}
+}
+
+func (c *APIKeyController) RegisterRoutes(router *mux.Router, authMiddleware mux.MiddlewareFunc) {
+	apiKeyRouter := router.PathPrefix("").Subrouter()
+	apiKeyRouter.Use(authMiddleware)
+
+	for _, rt := range c.protectedRoutes() {
+		apiKeyRouter.Handle(rt.path, rt.guarded()).Methods(rt.method)
+	}
+}
+
+func (c *APIKeyController) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.CreateAPIKey"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	var keyReq model.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&keyReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if keyReq.Name == "" || len(keyReq.Name) > 100 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Name is required and must be at most 100 characters")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	created, err := c.keySrvc.CreateAPIKey(ctx, *curUser, keyReq)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	// The key is shown only once, only its hash is stored
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+func (c *APIKeyController) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ListAPIKeys"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	keys, err := c.keySrvc.ListAPIKeys(ctx, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, keys)
+}
+
+func (c *APIKeyController) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.RevokeAPIKey"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	keyID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid api key id")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	err = c.keySrvc.RevokeAPIKey(ctx, *curUser, keyID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      keyID,
+		"revoked": true,
+	})
+}
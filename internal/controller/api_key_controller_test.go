@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	mockAPIKeyService := service.NewMockAPIKeyService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAPIKeyController(mockAPIKeyService, mockUserService)
+
+	testUser := UserFactory{Role: "seller"}.Build()
+	created := &model.CreatedAPIKey{
+		APIKey: model.APIKey{ID: 1, Name: "sync", Prefix: "hb_abcdefgh", Scopes: []string{model.ScopeProductsRead}},
+		Key:    "hb_abcdefghijklmnop",
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - key created",
+			requestBody: `{"name": "sync", "scopes": ["products:read"]}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockAPIKeyService.On("CreateAPIKey", mock.Anything, *testUser, model.CreateAPIKeyRequest{
+					Name: "sync", Scopes: []string{model.ScopeProductsRead},
+				}).Return(created, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:        "Unknown scope",
+			requestBody: `{"name": "sync", "scopes": ["admin"]}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockAPIKeyService.On("CreateAPIKey", mock.Anything, *testUser, mock.Anything).
+					Return(nil, service.ErrInvalidScope).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing name",
+			requestBody:    `{"scopes": ["products:read"]}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/user/api-keys", bytes.NewBufferString(tt.requestBody))
+			claims := jwt.MapClaims{"email": testUser.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.CreateAPIKey(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				assert.Contains(t, rr.Body.String(), created.Key)
+			}
+			mockAPIKeyService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	mockAPIKeyService := service.NewMockAPIKeyService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewAPIKeyController(mockAPIKeyService, mockUserService)
+
+	testUser := UserFactory{Role: "seller"}.Build()
+
+	tests := []struct {
+		name           string
+		keyID          string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:  "Success - key revoked",
+			keyID: "1",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockAPIKeyService.On("RevokeAPIKey", mock.Anything, *testUser, int64(1)).
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Key of another user",
+			keyID: "2",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockAPIKeyService.On("RevokeAPIKey", mock.Anything, *testUser, int64(2)).
+					Return(service.ErrAPIKeyNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			keyID:          "abc",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("DELETE", "/user/api-keys/"+tt.keyID, nil)
+			claims := jwt.MapClaims{"email": testUser.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.keyID})
+
+			rr := httptest.NewRecorder()
+			controller.RevokeAPIKey(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockAPIKeyService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
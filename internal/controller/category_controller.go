@@ -0,0 +1,317 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type CategoryController struct {
+	catSrvc service.CategoryService
+}
+
+func NewCategoryController(serviceCat service.CategoryService) *CategoryController {
+	return &CategoryController{catSrvc: serviceCat}
+}
+
+func (c *CategoryController) protectedRoutes() []route {
+	return []route{
+		{"/admin/categories", "POST", c.CreateCategory, adminRoles, noAPIKey},
+		{"/admin/categories/{id}", "PUT", c.UpdateCategory, adminRoles, noAPIKey},
+		{"/admin/categories/{id}", "DELETE", c.DeleteCategory, adminRoles, noAPIKey},
+		{"/admin/categories/{id}/attributes", "POST", c.CreateAttribute, adminRoles, noAPIKey},
+		{"/admin/categories/{id}/attributes/{attribute_id}", "PUT", c.UpdateAttribute, adminRoles, noAPIKey},
+		{"/admin/categories/{id}/attributes/{attribute_id}", "DELETE", c.DeleteAttribute, adminRoles, noAPIKey},
+	}
+}
+
+func (c *CategoryController) RegisterRoutes(router *mux.Router, authMiddleware mux.MiddlewareFunc) {
+	router.HandleFunc("/categories", c.GetCategoryTree).Methods("GET")
+	router.HandleFunc("/categories/{id}/attributes", c.ListAttributes).Methods("GET")
+
+	categoryRouter := router.PathPrefix("").Subrouter()
+	categoryRouter.Use(authMiddleware)
+
+	for _, rt := range c.protectedRoutes() {
+		categoryRouter.Handle(rt.path, rt.guarded()).Methods(rt.method)
+	}
+}
+
+func (c *CategoryController) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.GetCategoryTree"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	tree, err := c.catSrvc.GetCategoryTree(ctx)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, tree)
+}
+
+func (c *CategoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.CreateCategory"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	var catReq model.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&catReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	category, err := c.catSrvc.CreateCategory(ctx, catReq)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, category)
+}
+
+func (c *CategoryController) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UpdateCategory"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	categoryID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category id")
+		return
+	}
+
+	var catReq model.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&catReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	category, err := c.catSrvc.UpdateCategory(ctx, categoryID, catReq)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, category)
+}
+
+func (c *CategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.DeleteCategory"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	categoryID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category id")
+		return
+	}
+
+	err = c.catSrvc.DeleteCategory(ctx, categoryID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      categoryID,
+		"deleted": true,
+	})
+}
+
+func (c *CategoryController) ListAttributes(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ListAttributes"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	categoryID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category id")
+		return
+	}
+
+	attrs, err := c.catSrvc.ListAttributes(ctx, categoryID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, attrs)
+}
+
+func (c *CategoryController) CreateAttribute(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.CreateAttribute"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	categoryID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category id")
+		return
+	}
+
+	var attrReq model.AttributeDefinitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&attrReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	attr, err := c.catSrvc.CreateAttribute(ctx, categoryID, attrReq)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, attr)
+}
+
+func (c *CategoryController) UpdateAttribute(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UpdateAttribute"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	categoryID, err := pathID(vars, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category id")
+		return
+	}
+	attributeID, err := pathID(vars, "attribute_id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid attribute id")
+		return
+	}
+
+	var attrReq model.AttributeDefinitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&attrReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	attr, err := c.catSrvc.UpdateAttribute(ctx, categoryID, attributeID, attrReq)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, attr)
+}
+
+func (c *CategoryController) DeleteAttribute(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.DeleteAttribute"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	categoryID, err := pathID(vars, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category id")
+		return
+	}
+	attributeID, err := pathID(vars, "attribute_id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid attribute id")
+		return
+	}
+
+	err = c.catSrvc.DeleteAttribute(ctx, categoryID, attributeID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      attributeID,
+		"deleted": true,
+	})
+}
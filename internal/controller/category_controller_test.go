@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+)
+
+func TestUpdateCategory(t *testing.T) {
+	mockCategoryService := service.NewMockCategoryService(t)
+	controller := NewCategoryController(mockCategoryService)
+
+	parentID := int64(1)
+
+	tests := []struct {
+		name           string
+		id             string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - category moved",
+			id:          "2",
+			requestBody: `{"name": "Phones", "parent_id": 1}`,
+			mockSetup: func() {
+				mockCategoryService.On("UpdateCategory", mock.Anything, int64(2), model.CategoryRequest{
+					ParentID: &parentID, Name: "Phones",
+				}).Return(&model.Category{ID: 2, ParentID: &parentID, Name: "Phones", Slug: "phones"}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Moved under its own subcategory",
+			id:          "1",
+			requestBody: `{"name": "Electronics", "parent_id": 1}`,
+			mockSetup: func() {
+				mockCategoryService.On("UpdateCategory", mock.Anything, int64(1), mock.Anything).
+					Return(nil, service.ErrCategoryCycle).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Slug taken",
+			id:          "2",
+			requestBody: `{"name": "Phones", "slug": "electronics"}`,
+			mockSetup: func() {
+				mockCategoryService.On("UpdateCategory", mock.Anything, int64(2), mock.Anything).
+					Return(nil, service.ErrCategoryAlreadyExists).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Category not found",
+			id:          "9",
+			requestBody: `{"name": "Phones"}`,
+			mockSetup: func() {
+				mockCategoryService.On("UpdateCategory", mock.Anything, int64(9), mock.Anything).
+					Return(nil, service.ErrCategoryNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid id",
+			id:             "abc",
+			requestBody:    `{"name": "Phones"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("PUT", "/admin/categories/"+tt.id, bytes.NewBufferString(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+
+			rr := httptest.NewRecorder()
+			controller.UpdateCategory(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockCategoryService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteCategory(t *testing.T) {
+	mockCategoryService := service.NewMockCategoryService(t)
+	controller := NewCategoryController(mockCategoryService)
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Success - category deleted",
+			mockSetup: func() {
+				mockCategoryService.On("DeleteCategory", mock.Anything, int64(3)).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Category has subcategories",
+			mockSetup: func() {
+				mockCategoryService.On("DeleteCategory", mock.Anything, int64(3)).
+					Return(service.ErrCategoryNotEmpty).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("DELETE", "/admin/categories/3", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "3"})
+
+			rr := httptest.NewRecorder()
+			controller.DeleteCategory(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockCategoryService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateAttribute(t *testing.T) {
+	mockCategoryService := service.NewMockCategoryService(t)
+	controller := NewCategoryController(mockCategoryService)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - attribute created",
+			requestBody: `{"code": "ram_gb", "name": "RAM", "type": "number", "unit": "GB", "required": true}`,
+			mockSetup: func() {
+				mockCategoryService.On("CreateAttribute", mock.Anything, int64(3), model.AttributeDefinitionRequest{
+					Code: "ram_gb", Name: "RAM", Type: model.AttributeNumber, Unit: "GB", Required: true,
+				}).Return(&model.AttributeDefinition{ID: 7, CategoryID: 3, Code: "ram_gb", Name: "RAM",
+					Type: model.AttributeNumber, Unit: "GB", Values: []string{}, Required: true}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:        "Enum without values",
+			requestBody: `{"code": "color", "name": "Color", "type": "enum"}`,
+			mockSetup: func() {
+				mockCategoryService.On("CreateAttribute", mock.Anything, int64(3), mock.Anything).
+					Return(nil, service.ErrInvalidAttribute).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Code taken",
+			requestBody: `{"code": "ram_gb", "name": "Memory", "type": "number"}`,
+			mockSetup: func() {
+				mockCategoryService.On("CreateAttribute", mock.Anything, int64(3), mock.Anything).
+					Return(nil, service.ErrAttributeAlreadyExists).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Unknown category",
+			requestBody: `{"code": "ram_gb", "name": "RAM", "type": "number"}`,
+			mockSetup: func() {
+				mockCategoryService.On("CreateAttribute", mock.Anything, int64(3), mock.Anything).
+					Return(nil, service.ErrCategoryNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/admin/categories/3/attributes", bytes.NewBufferString(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": "3"})
+			rr := httptest.NewRecorder()
+			controller.CreateAttribute(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockCategoryService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+
+	// maxUploadBodySize caps the body of an upload before the service checks the file size it allows
+	maxUploadBodySize = 32 << 20
+	// maxUploadMemory is how much of an upload is kept in memory, the rest goes to temporary files
+	maxUploadMemory = 8 << 20
+)
+
+// currentUser loads the authenticated user from the token claims.
+// On failure the error response is already written and false is returned.
+func currentUser(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	usrSrvc service.UserService) (*model.User, bool) {
+
+	claims, ok := utils.GetUserClaimsFromContext(r)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+		return nil, false
+	}
+
+	userEmail, ok := claims["email"].(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User email not found in token")
+		return nil, false
+	}
+
+	curUser, err := usrSrvc.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "User not found by email")
+		return nil, false
+	}
+
+	return curUser, true
+}
+
+// clientIP returns the address of the client. X-Real-IP is applied to RemoteAddr by
+// middleware.RealIPMiddleware, and only for requests that come from a trusted proxy.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// respondWithServiceError maps errors returned by the service layer to HTTP statuses
+func respondWithServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrUserBanned):
+		utils.RespondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrProductNotFound), errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrAPIKeyNotFound), errors.Is(err, service.ErrCategoryNotFound),
+		errors.Is(err, service.ErrVariantNotFound), errors.Is(err, service.ErrImageNotFound),
+		errors.Is(err, service.ErrAttributeNotFound), errors.Is(err, service.ErrCartItemNotFound),
+		errors.Is(err, service.ErrOrderNotFound), errors.Is(err, service.ErrReturnNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrCannotModifySelf),
+		errors.Is(err, service.ErrInvalidScope), errors.Is(err, service.ErrInvalidSort),
+		errors.Is(err, service.ErrInvalidCursor), errors.Is(err, service.ErrInvalidSearchQuery),
+		errors.Is(err, service.ErrInvalidCategory),
+		errors.Is(err, service.ErrUnknownCategory), errors.Is(err, service.ErrCategoryCycle),
+		errors.Is(err, service.ErrInvalidVariant), errors.Is(err, service.ErrVariantRequired),
+		errors.Is(err, service.ErrInvalidImage), errors.Is(err, service.ErrInvalidImageOrder),
+		errors.Is(err, service.ErrImageNotInGallery),
+		errors.Is(err, service.ErrInvalidAttribute), errors.Is(err, service.ErrInvalidAttributes),
+		errors.Is(err, service.ErrInvalidAttributeFilter), errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrInvalidPublishTime), errors.Is(err, service.ErrInvalidQuantity),
+		errors.Is(err, service.ErrCartEmpty), errors.Is(err, service.ErrInvalidOrderStatus),
+		errors.Is(err, service.ErrInvalidReason), errors.Is(err, service.ErrInvalidReturn),
+		errors.Is(err, service.ErrInvalidReturnStatus):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrUserAlreadyExists), errors.Is(err, service.ErrCategoryAlreadyExists),
+		errors.Is(err, service.ErrCategoryNotEmpty), errors.Is(err, service.ErrVariantAlreadyExists),
+		errors.Is(err, service.ErrProductHasVariants), errors.Is(err, service.ErrOutOfStock),
+		errors.Is(err, service.ErrAmountBelowReserved), errors.Is(err, service.ErrProductReserved),
+		errors.Is(err, service.ErrTooManyImages), errors.Is(err, service.ErrAttributeAlreadyExists),
+		errors.Is(err, service.ErrInvalidStatusTransition), errors.Is(err, service.ErrInvalidOrderTransition),
+		errors.Is(err, service.ErrReturnNotAllowed), errors.Is(err, service.ErrReturnResolved),
+		errors.Is(err, service.ErrReservationExpired), errors.Is(err, service.ErrOrderItemsCancelled):
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrImageTooLarge):
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, service.ErrUnsupportedImageType):
+		utils.RespondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// parsePagination reads limit and offset query parameters
+func parsePagination(r *http.Request) (int, int, error) {
+	limit, offset := defaultPageLimit, 0
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return 0, 0, errors.New("invalid limit")
+		}
+		limit = min(n, maxPageLimit)
+	}
+
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("invalid offset")
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
+
+// parseProductFilter reads the catalog query parameters. Dates are RFC 3339 timestamps or plain dates.
+func parseProductFilter(r *http.Request) (model.ProductFilter, error) {
+	q := r.URL.Query()
+	filter := model.ProductFilter{
+		Limit:  defaultPageLimit,
+		Sort:   q.Get("sort"),
+		Cursor: q.Get("cursor"),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return filter, errors.New("invalid limit")
+		}
+		filter.Limit = min(n, maxPageLimit)
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{
+		{"min_price", &filter.MinPrice},
+		{"max_price", &filter.MaxPrice},
+		{"seller_id", &filter.SellerID},
+		{"category_id", &filter.CategoryID},
+	} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return filter, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = n
+		}
+	}
+	if filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice {
+		return filter, errors.New("min_price is greater than max_price")
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *bool
+	}{
+		{"in_stock", &filter.InStock},
+		{"facets", &filter.Facets},
+	} {
+		if v := q.Get(p.name); v != "" {
+			ok, err := strconv.ParseBool(v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = ok
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+	} {
+		if v := q.Get(p.name); v != "" {
+			t, err := parseDate(v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = t
+		}
+	}
+
+	for _, v := range q["attr"] {
+		f, err := parseAttributeFilter(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Attributes = append(filter.Attributes, f)
+	}
+
+	return filter, nil
+}
+
+// attributeOps lists the operators of attribute filters, two character ones first
+var attributeOps = []string{
+	model.AttributeOpGte, model.AttributeOpLte, model.AttributeOpNe,
+	model.AttributeOpGt, model.AttributeOpLt, model.AttributeOpEq,
+}
+
+// parseAttributeFilter reads an attr parameter such as ram_gb>=16 or color=black
+func parseAttributeFilter(v string) (model.AttributeFilter, error) {
+	i := strings.IndexAny(v, "=!<>")
+	if i <= 0 {
+		return model.AttributeFilter{}, errors.New("invalid attr")
+	}
+
+	for _, op := range attributeOps {
+		if value, ok := strings.CutPrefix(v[i:], op); ok {
+			return model.AttributeFilter{Code: v[:i], Op: op, Value: value}, nil
+		}
+	}
+
+	return model.AttributeFilter{}, errors.New("invalid attr")
+}
+
+// parseDate accepts an RFC 3339 timestamp or a date, which means its midnight in UTC
+func parseDate(v string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, v)
+	}
+
+	// Timestamps are stored in UTC without a zone
+	return t.UTC(), err
+}
+
+// pathID parses a numeric route variable
+func pathID(vars map[string]string, name string) (int64, error) {
+	return strconv.ParseInt(vars[name], 10, 64)
+}
+
+// readUploadedFile returns the content of the file sent in the multipart form field.
+// A body over maxUploadBodySize fails with an *http.MaxBytesError.
+func readUploadedFile(w http.ResponseWriter, r *http.Request, field string) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBodySize)
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		return nil, err
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// variantParam reads the optional variant_id query parameter, 0 means the product itself
+func variantParam(r *http.Request) (int64, error) {
+	v := r.URL.Query().Get("variant_id")
+	if v == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid variant_id")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/auth"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// JWKSController publishes the public keys access tokens are signed with,
+// so other services can verify tokens without sharing a secret
+type JWKSController struct {
+	keys *auth.KeyStore
+}
+
+func NewJWKSController(keys *auth.KeyStore) *JWKSController {
+	return &JWKSController{keys: keys}
+}
+
+func (c *JWKSController) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/.well-known/jwks.json", c.GetJWKS).Methods("GET")
+}
+
+func (c *JWKSController) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	// Verifiers may cache the set, but not for longer than it takes to roll out a new key
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.RespondWithJSON(w, http.StatusOK, c.keys.JWKS())
+}
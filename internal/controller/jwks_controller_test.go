@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/auth"
+)
+
+func TestGetJWKS(t *testing.T) {
+	keys := newTestKeyStore(t)
+	router := mux.NewRouter()
+	NewJWKSController(keys).RegisterRoutes(router)
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var set auth.JWKSet
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&set))
+	if assert.Len(t, set.Keys, 1) {
+		assert.Equal(t, keys.ActiveKeyID(), set.Keys[0].KeyID)
+		assert.Equal(t, "EdDSA", set.Keys[0].Algorithm)
+		assert.NotEmpty(t, set.Keys[0].X)
+	}
+}
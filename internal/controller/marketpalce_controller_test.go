@@ -5,17 +5,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-faker/faker/v4"
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/middleware"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
 )
@@ -110,6 +114,7 @@ func TestLoginUser(t *testing.T) {
 		requestBody    string
 		mockSetup      func()
 		expectedStatus int
+		retryAfter     string
 	}{
 		{
 			name: "Success - valid login",
@@ -121,8 +126,8 @@ func TestLoginUser(t *testing.T) {
 				}`, userLogin.Email, userLogin.Password)
 			}(),
 			mockSetup: func() {
-				mockUserService.On("LoginUser", mock.Anything, mock.Anything).
-					Return("valid-token", nil).Once()
+				mockUserService.On("LoginUser", mock.Anything, mock.Anything, mock.Anything).
+					Return(&model.TokenPair{AccessToken: "valid-token", RefreshToken: "refresh-token"}, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -133,11 +138,36 @@ func TestLoginUser(t *testing.T) {
 				"password": "wrongpassword"
 			}`,
 			mockSetup: func() {
-				mockUserService.On("LoginUser", mock.Anything, mock.Anything).
-					Return("", errors.New("invalid credentials")).Once()
+				mockUserService.On("LoginUser", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, errors.New("invalid credentials")).Once()
 			},
 			expectedStatus: http.StatusUnauthorized,
 		},
+		{
+			name: "Email not verified",
+			requestBody: `{
+				"email": "unverified@example.com",
+				"password": "password123"
+			}`,
+			mockSetup: func() {
+				mockUserService.On("LoginUser", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, service.ErrEmailNotVerified).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Locked out after too many failures",
+			requestBody: `{
+				"email": "victim@example.com",
+				"password": "guess"
+			}`,
+			mockSetup: func() {
+				mockUserService.On("LoginUser", mock.Anything, mock.Anything, "192.0.2.1").
+					Return(nil, &service.LoginLockedError{RetryAfter: 90*time.Second + time.Millisecond}).Once()
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			retryAfter:     "91",
+		},
 		{
 			name: "Missing fields",
 			requestBody: `{
@@ -154,56 +184,109 @@ func TestLoginUser(t *testing.T) {
 			tt.mockSetup()
 
 			req := httptest.NewRequest("POST", "/user/login", bytes.NewBufferString(tt.requestBody))
+			req.RemoteAddr = "192.0.2.1:41234"
 			rr := httptest.NewRecorder()
 			controller.LoginUser(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.retryAfter, rr.Header().Get("Retry-After"))
 			mockUserService.AssertExpectations(t)
 		})
 	}
 }
 
-func TestGetProductByID(t *testing.T) {
+func TestLoginUserClientIP(t *testing.T) {
+	mockUserService := service.NewMockUserService(t)
 	mockProductService := service.NewMockProductService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+	handler := middleware.RealIPMiddleware([]netip.Prefix{netip.MustParsePrefix("172.16.0.0/12")})(
+		http.HandlerFunc(controller.LoginUser))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		expectedIP string
+	}{
+		{
+			name:       "Header from the trusted proxy",
+			remoteAddr: "172.18.0.5:52000",
+			realIP:     "203.0.113.7",
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:       "Forged header from a direct client",
+			remoteAddr: "198.51.100.9:52000",
+			realIP:     "203.0.113.7",
+			expectedIP: "198.51.100.9",
+		},
+		{
+			name:       "Invalid header from the trusted proxy",
+			remoteAddr: "172.18.0.5:52000",
+			realIP:     "not-an-ip",
+			expectedIP: "172.18.0.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserService.On("LoginUser", mock.Anything, mock.Anything, tt.expectedIP).
+				Return(&model.TokenPair{AccessToken: "valid-token", RefreshToken: "refresh-token"}, nil).Once()
+
+			req := httptest.NewRequest("POST", "/user/login",
+				bytes.NewBufferString(`{"email": "test@example.com", "password": "password123"}`))
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Real-IP", tt.realIP)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
 	mockUserService := service.NewMockUserService(t)
+	mockProductService := service.NewMockProductService(t)
 	controller := NewMarketplaceController(mockProductService, mockUserService)
 
 	tests := []struct {
 		name           string
-		productID      string
-		mockSetup      func(id int64)
+		requestBody    string
+		mockSetup      func()
 		expectedStatus int
 	}{
 		{
-			name:      "Success - product found",
-			productID: "1",
-			mockSetup: func(id int64) {
-				mockProductService.On("GetProductByID", mock.Anything, id).
-					Return(&model.Product{ID: id, Title: "Test Product"}, nil).Once()
+			name:        "Success - token rotated",
+			requestBody: `{"refresh_token": "valid-refresh"}`,
+			mockSetup: func() {
+				mockUserService.On("RefreshToken", mock.Anything, "valid-refresh").
+					Return(&model.TokenPair{AccessToken: "new-access", RefreshToken: "new-refresh"}, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:      "Product not found",
-			productID: "999",
-			mockSetup: func(id int64) {
-				mockProductService.On("GetProductByID", mock.Anything, id).
-					Return(nil, nil).Once()
+			name:        "Invalid or reused refresh token",
+			requestBody: `{"refresh_token": "used-refresh"}`,
+			mockSetup: func() {
+				mockUserService.On("RefreshToken", mock.Anything, "used-refresh").
+					Return(nil, service.ErrInvalidToken).Once()
 			},
-			expectedStatus: http.StatusNotFound,
+			expectedStatus: http.StatusUnauthorized,
 		},
 		{
-			name:           "Invalid ID",
-			productID:      "invalid",
-			mockSetup:      func(id int64) {},
+			name:           "Missing refresh token",
+			requestBody:    `{"refresh_token": ""}`,
+			mockSetup:      func() {},
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:      "Service error",
-			productID: "1",
-			mockSetup: func(id int64) {
-				mockProductService.On("GetProductByID", mock.Anything, id).
-					Return(nil, errors.New("service error")).Once()
+			name:        "Service error",
+			requestBody: `{"refresh_token": "some-refresh"}`,
+			mockSetup: func() {
+				mockUserService.On("RefreshToken", mock.Anything, "some-refresh").
+					Return(nil, errors.New("redis is down")).Once()
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -211,309 +294,1591 @@ func TestGetProductByID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.mockSetup != nil {
-				id, _ := strconv.ParseInt(tt.productID, 10, 64)
-				tt.mockSetup(id)
-			}
+			tt.mockSetup()
 
-			req := httptest.NewRequest("GET", "/products/"+tt.productID, nil)
-			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+			req := httptest.NewRequest("POST", "/user/token/refresh", bytes.NewBufferString(tt.requestBody))
 			rr := httptest.NewRecorder()
-			controller.GetProductByID(rr, req)
+			controller.RefreshToken(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
-			mockProductService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
 		})
 	}
 }
 
-func TestCreateProduct(t *testing.T) {
+func TestConfirmEmail(t *testing.T) {
+	mockUserService := service.NewMockUserService(t)
 	mockProductService := service.NewMockProductService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - email confirmed",
+			requestBody: `{"token": "valid-token"}`,
+			mockSetup: func() {
+				mockUserService.On("ConfirmEmail", mock.Anything, "valid-token").Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Invalid or expired token",
+			requestBody: `{"token": "used-token"}`,
+			mockSetup: func() {
+				mockUserService.On("ConfirmEmail", mock.Anything, "used-token").
+					Return(service.ErrInvalidToken).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing token",
+			requestBody:    `{"token": ""}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/user/verify/confirm", bytes.NewBufferString(tt.requestBody))
+			rr := httptest.NewRecorder()
+			controller.ConfirmEmail(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
 	mockUserService := service.NewMockUserService(t)
+	mockProductService := service.NewMockProductService(t)
 	controller := NewMarketplaceController(mockProductService, mockUserService)
 
-	testName := strings.Replace(faker.Name(), " ", "", -1)
-	testDomain := faker.DomainName()
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Accepted - known or unknown email",
+			requestBody: `{"email": "someone@example.com"}`,
+			mockSetup: func() {
+				mockUserService.On("RequestPasswordReset", mock.Anything, "someone@example.com").Return(nil).Once()
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Missing email",
+			requestBody:    `{"email": ""}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Service error",
+			requestBody: `{"email": "someone@example.com"}`,
+			mockSetup: func() {
+				mockUserService.On("RequestPasswordReset", mock.Anything, "someone@example.com").
+					Return(errors.New("redis is down")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/user/password/forgot", bytes.NewBufferString(tt.requestBody))
+			rr := httptest.NewRecorder()
+			controller.RequestPasswordReset(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	mockUserService := service.NewMockUserService(t)
+	mockProductService := service.NewMockProductService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - password reset",
+			requestBody: `{"token": "valid-token", "password": "new-password"}`,
+			mockSetup: func() {
+				mockUserService.On("ResetPassword", mock.Anything, "valid-token", mock.AnythingOfType("string")).
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Invalid or expired token",
+			requestBody: `{"token": "used-token", "password": "new-password"}`,
+			mockSetup: func() {
+				mockUserService.On("ResetPassword", mock.Anything, "used-token", mock.AnythingOfType("string")).
+					Return(service.ErrInvalidToken).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing password",
+			requestBody:    `{"token": "valid-token", "password": ""}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/user/password/reset", bytes.NewBufferString(tt.requestBody))
+			rr := httptest.NewRecorder()
+			controller.ResetPassword(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+// TestEmailLinks follows the links sent in the verification and password reset emails,
+// which a mail client opens with GET
+func TestEmailLinks(t *testing.T) {
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(service.NewMockProductService(t), mockUserService)
+	router := mux.NewRouter()
+	controller.RegisterRoutes(router, func(next http.Handler) http.Handler { return next })
+
+	t.Run("Verification link confirms the email", func(t *testing.T) {
+		mockUserService.On("ConfirmEmail", mock.Anything, "verify-token").Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/user/verify/confirm?token=verify-token", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Reset link shows a form posting the token", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/user/password/reset?token=reset-token", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, rr.Body.String(), `name="token" value="reset-token"`)
+	})
+
+	t.Run("Reset form sets the password", func(t *testing.T) {
+		mockUserService.On("ResetPassword", mock.Anything, "reset-token", mock.AnythingOfType("string")).
+			Return(nil).Once()
+
+		req := httptest.NewRequest("POST", "/user/password/reset?token=reset-token",
+			strings.NewReader("token=reset-token&password=new-password"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	mockUserService.AssertExpectations(t)
+}
+
+func TestLogoutUser(t *testing.T) {
+	mockUserService := service.NewMockUserService(t)
+	mockProductService := service.NewMockProductService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	exp := time.Now().Add(10 * time.Minute).Unix()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		claims         jwt.MapClaims
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - revoke access and refresh tokens",
+			requestBody: `{"refresh_token": "valid-refresh"}`,
+			claims:      jwt.MapClaims{"jti": "token-id", "exp": float64(exp), "user_id": float64(7)},
+			mockSetup: func() {
+				mockUserService.On("LogoutUser", mock.Anything, int64(7), "token-id", time.Unix(exp, 0), "valid-refresh").
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Success - without refresh token",
+			requestBody: ``,
+			claims:      jwt.MapClaims{"jti": "token-id", "exp": float64(exp), "user_id": float64(7)},
+			mockSetup: func() {
+				mockUserService.On("LogoutUser", mock.Anything, int64(7), "token-id", time.Unix(exp, 0), "").
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Refresh token of another user",
+			requestBody: `{"refresh_token": "stolen-refresh"}`,
+			claims:      jwt.MapClaims{"jti": "token-id", "exp": float64(exp), "user_id": float64(7)},
+			mockSetup: func() {
+				mockUserService.On("LogoutUser", mock.Anything, int64(7), "token-id", time.Unix(exp, 0), "stolen-refresh").
+					Return(service.ErrInvalidToken).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Token without jti",
+			requestBody:    ``,
+			claims:         jwt.MapClaims{"exp": float64(exp), "user_id": float64(7)},
+			mockSetup:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:        "Service error",
+			requestBody: ``,
+			claims:      jwt.MapClaims{"jti": "other-id", "exp": float64(exp), "user_id": float64(7)},
+			mockSetup: func() {
+				mockUserService.On("LogoutUser", mock.Anything, int64(7), "other-id", time.Unix(exp, 0), "").
+					Return(errors.New("redis is down")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/user/logout", bytes.NewBufferString(tt.requestBody))
+			ctx := context.WithValue(req.Context(), "userClaims", tt.claims)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			controller.LogoutUser(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateMe(t *testing.T) {
+	mockUserService := service.NewMockUserService(t)
+	mockProductService := service.NewMockProductService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testUser := UserFactory{Role: "customer"}.Build()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - email changed",
+			requestBody: `{"email": "new@example.com"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockUserService.On("UpdateProfile", mock.Anything, *testUser,
+					model.UpdateProfileRequest{Email: "new@example.com"}).
+					Return(&model.User{ID: testUser.ID, UserName: testUser.UserName, Email: "new@example.com"}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Name or email already taken",
+			requestBody: `{"name": "taken_name"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockUserService.On("UpdateProfile", mock.Anything, *testUser,
+					model.UpdateProfileRequest{UserName: "taken_name"}).
+					Return(nil, service.ErrUserAlreadyExists).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Nothing to update",
+			requestBody:    `{}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("PUT", "/user/me", bytes.NewBufferString(tt.requestBody))
+			claims := jwt.MapClaims{"email": testUser.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.UpdateMe(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	mockUserService := service.NewMockUserService(t)
+	mockProductService := service.NewMockProductService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testUser := UserFactory{Role: "customer"}.Build()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - password changed",
+			requestBody: `{"old_password": "old-password", "new_password": "new-password"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockUserService.On("ChangePassword", mock.Anything, *testUser, "old-password", mock.AnythingOfType("string")).
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Wrong old password",
+			requestBody: `{"old_password": "wrong", "new_password": "new-password"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testUser.Email).
+					Return(testUser, nil).Once()
+				mockUserService.On("ChangePassword", mock.Anything, *testUser, "wrong", mock.AnythingOfType("string")).
+					Return(service.ErrWrongPassword).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Missing new password",
+			requestBody:    `{"old_password": "old-password"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/user/me/password", bytes.NewBufferString(tt.requestBody))
+			claims := jwt.MapClaims{"email": testUser.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.ChangePassword(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAllProducts(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	next := "next-page"
+	page := &model.ProductPage{
+		Items:      []model.Product{{ID: 2, Title: "Test Product", Price: 1500}},
+		NextCursor: &next,
+		Total:      10,
+		Limit:      1,
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:  "Success - defaults",
+			query: "",
+			mockSetup: func() {
+				mockProductService.On("ListProducts", mock.Anything, model.ProductFilter{Limit: defaultPageLimit}).
+					Return(page, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Success - filtered page",
+			query: "?limit=1&cursor=abc&sort=price_asc&min_price=1000&max_price=2000&seller_id=4" +
+				"&in_stock=true&created_after=2026-01-01&created_before=2026-02-01T12:00:00%2B03:00",
+			mockSetup: func() {
+				mockProductService.On("ListProducts", mock.Anything, model.ProductFilter{
+					MinPrice:      1000,
+					MaxPrice:      2000,
+					SellerID:      4,
+					InStock:       true,
+					CreatedAfter:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+					CreatedBefore: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+					Sort:          model.ProductSortPriceAsc,
+					Limit:         1,
+					Cursor:        "abc",
+				}).Return(page, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Limit is capped",
+			query: "?limit=100000",
+			mockSetup: func() {
+				mockProductService.On("ListProducts", mock.Anything, model.ProductFilter{Limit: maxPageLimit}).
+					Return(page, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Inverted price range",
+			query:          "?min_price=2000&max_price=1000",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid date",
+			query:          "?created_after=yesterday",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Success - attribute filters",
+			query: "?attr=ram_gb%3E%3D16&attr=gpu%3DRTX+3070&attr=touch!%3Dtrue",
+			mockSetup: func() {
+				mockProductService.On("ListProducts", mock.Anything, model.ProductFilter{
+					Attributes: []model.AttributeFilter{
+						{Code: "ram_gb", Op: model.AttributeOpGte, Value: "16"},
+						{Code: "gpu", Op: model.AttributeOpEq, Value: "RTX 3070"},
+						{Code: "touch", Op: model.AttributeOpNe, Value: "true"},
+					},
+					Limit: defaultPageLimit,
+				}).Return(page, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Success - with facets",
+			query: "?facets=true&seller_id=4",
+			mockSetup: func() {
+				mockProductService.On("ListProducts", mock.Anything, model.ProductFilter{
+					SellerID: 4,
+					Limit:    defaultPageLimit,
+					Facets:   true,
+				}).Return(&model.ProductPage{
+					Items:      page.Items,
+					NextCursor: page.NextCursor,
+					Total:      page.Total,
+					Limit:      page.Limit,
+					Facets: &model.ProductFacets{
+						Sellers:    []model.FacetCount{{ID: 4, Name: "ronald_mcdonald", Count: 10}},
+						Categories: []model.FacetCount{},
+						Prices:     []model.PriceBucket{{Min: 0, Count: 10}},
+						Attributes: map[string][]model.AttributeValueCount{"ram_gb": {{Value: 16.0, Count: 3}}},
+					},
+				}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid facets flag",
+			query:          "?facets=maybe",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Attribute filter without operator",
+			query:          "?attr=ram_gb",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Comparison of a string",
+			query: "?attr=gpu%3ERTX",
+			mockSetup: func() {
+				mockProductService.On("ListProducts", mock.Anything, mock.Anything).
+					Return(nil, service.ErrInvalidAttributeFilter).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Cursor of another sort",
+			query: "?sort=title&cursor=abc",
+			mockSetup: func() {
+				mockProductService.On("ListProducts", mock.Anything, mock.Anything).
+					Return(nil, service.ErrInvalidCursor).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("GET", "/products"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			controller.GetAllProducts(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"next_cursor":"next-page"`)
+				assert.Contains(t, rr.Body.String(), `"total":10`)
+			}
+			mockProductService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetProductByID(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	tests := []struct {
+		name           string
+		productID      string
+		mockSetup      func(id int64)
+		expectedStatus int
+	}{
+		{
+			name:      "Success - product found",
+			productID: "1",
+			mockSetup: func(id int64) {
+				mockProductService.On("GetProductByID", mock.Anything, id).
+					Return(&model.Product{ID: id, Title: "Test Product"}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "Product not found",
+			productID: "999",
+			mockSetup: func(id int64) {
+				mockProductService.On("GetProductByID", mock.Anything, id).
+					Return(nil, nil).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			productID:      "invalid",
+			mockSetup:      func(id int64) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Service error",
+			productID: "1",
+			mockSetup: func(id int64) {
+				mockProductService.On("GetProductByID", mock.Anything, id).
+					Return(nil, errors.New("service error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				id, _ := strconv.ParseInt(tt.productID, 10, 64)
+				tt.mockSetup(id)
+			}
+
+			req := httptest.NewRequest("GET", "/products/"+tt.productID, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+			rr := httptest.NewRecorder()
+			controller.GetProductByID(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockProductService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateProduct(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testName := strings.Replace(faker.Name(), " ", "", -1)
+	testDomain := faker.DomainName()
+
+	testEmail := fmt.Sprintf("%s@%s", testName, testDomain)
+	testSeller := &model.User{ID: 1, Email: testEmail, Role: "seller"}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Success - valid product creation",
+			requestBody: `{
+                "title": "New Product",
+                "product_description": "Description",
+                "product_image": "image.jpg",
+                "price": 100,
+                "amount": 10
+            }`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testSeller, nil).Once()
+				mockProductService.On("CreateProduct", mock.Anything, mock.Anything, *testSeller).
+					Return(int64(1), nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Fail - invalid JSON",
+			requestBody:    `{ invalid json }`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/products", bytes.NewBufferString(tt.requestBody))
+
+			// Set up auth context
+			claims := jwt.MapClaims{"email": testEmail}
+			ctx := context.WithValue(req.Context(), "userClaims", claims)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			controller.CreateProduct(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockProductService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateProduct(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testName := strings.Replace(faker.Name(), " ", "", -1)
+	testDomain := faker.DomainName()
+
+	testEmail := fmt.Sprintf("%s@%s", testName, testDomain)
+	testSeller := UserFactory{Role: "seller"}.Build()
+
+	validUpdate := `{
+        "title": "Updated Product",
+        "product_description": "Updated Description",
+        "product_image": "updated_pic.jpg",
+        "price": 200,
+        "amount": 20
+    }`
+
+	tests := []struct {
+		name           string
+		productID      string
+		requestBody    string
+		setupMocks     func()
+		setupRequest   func(req *http.Request)
+		expectedStatus int
+	}{
+		{
+			name:        "Success - update product",
+			productID:   "1",
+			requestBody: validUpdate,
+			setupMocks: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testSeller, nil).Once()
+				mockProductService.On("UpdateProduct", mock.Anything, mock.Anything, int64(1), *testSeller).
+					Return(int64(1), nil).Once()
+			},
+			setupRequest: func(req *http.Request) {
+				claims := jwt.MapClaims{"email": testEmail}
+				ctx := context.WithValue(req.Context(), "userClaims", claims)
+				*req = *req.WithContext(ctx)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Forbidden - product of another seller",
+			productID:   "4",
+			requestBody: validUpdate,
+			setupMocks: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testSeller, nil).Once()
+				mockProductService.On("UpdateProduct", mock.Anything, mock.Anything, int64(4), *testSeller).
+					Return(int64(-1), service.ErrForbidden).Once()
+			},
+			setupRequest: func(req *http.Request) {
+				claims := jwt.MapClaims{"email": testEmail}
+				ctx := context.WithValue(req.Context(), "userClaims", claims)
+				*req = *req.WithContext(ctx)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:        "Image not in the gallery",
+			productID:   "1",
+			requestBody: validUpdate,
+			setupMocks: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testSeller, nil).Once()
+				mockProductService.On("UpdateProduct", mock.Anything, mock.Anything, int64(1), *testSeller).
+					Return(int64(-1), service.ErrImageNotInGallery).Once()
+			},
+			setupRequest: func(req *http.Request) {
+				claims := jwt.MapClaims{"email": testEmail}
+				ctx := context.WithValue(req.Context(), "userClaims", claims)
+				*req = *req.WithContext(ctx)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Amount below the reserved stock",
+			productID:   "1",
+			requestBody: validUpdate,
+			setupMocks: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testSeller, nil).Once()
+				mockProductService.On("UpdateProduct", mock.Anything, mock.Anything, int64(1), *testSeller).
+					Return(int64(-1), service.ErrAmountBelowReserved).Once()
+			},
+			setupRequest: func(req *http.Request) {
+				claims := jwt.MapClaims{"email": testEmail}
+				ctx := context.WithValue(req.Context(), "userClaims", claims)
+				*req = *req.WithContext(ctx)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Invalid product ID",
+			productID:   "invalid",
+			requestBody: validUpdate,
+			setupMocks: func() {
+			},
+			setupRequest: func(req *http.Request) {
+				claims := jwt.MapClaims{"email": testEmail}
+				ctx := context.WithValue(req.Context(), "userClaims", claims)
+				*req = *req.WithContext(ctx)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid update data",
+			productID:   "2",
+			requestBody: `{"title": ""}`,
+			setupMocks: func() {
+			},
+			setupRequest: func(req *http.Request) {
+				claims := jwt.MapClaims{"email": testEmail}
+				ctx := context.WithValue(req.Context(), "userClaims", claims)
+				*req = *req.WithContext(ctx)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unauthorized - no auth context",
+			productID:      "3",
+			requestBody:    validUpdate,
+			setupMocks:     func() {},
+			setupRequest:   func(req *http.Request) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mocks
+			tt.setupMocks()
+
+			// Create request
+			req := httptest.NewRequest("PUT", "/products/"+tt.productID, bytes.NewBufferString(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+
+			// Setup request context
+			tt.setupRequest(req)
+
+			rr := httptest.NewRecorder()
+			controller.UpdateProduct(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus != http.StatusUnauthorized && tt.expectedStatus != http.StatusBadRequest {
+				mockProductService.AssertExpectations(t)
+			}
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteProduct(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testSeller := UserFactory{Role: "seller"}.Build()
+
+	tests := []struct {
+		name           string
+		productID      string
+		mockSetup      func(id int64)
+		expectedStatus int
+	}{
+		{
+			name:      "Success - delete product",
+			productID: "123",
+			mockSetup: func(id int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("DeleteProduct", mock.Anything, id, *testSeller).
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid ID",
+			productID:      "invalid",
+			mockSetup:      func(id int64) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Forbidden - product of another seller",
+			productID: "7",
+			mockSetup: func(id int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("DeleteProduct", mock.Anything, id, *testSeller).
+					Return(service.ErrForbidden).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:      "Product not found",
+			productID: "8",
+			mockSetup: func(id int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("DeleteProduct", mock.Anything, id, *testSeller).
+					Return(service.ErrProductNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:      "Service error",
+			productID: "1",
+			mockSetup: func(id int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("DeleteProduct", mock.Anything, id, *testSeller).
+					Return(errors.New("service error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				id, _ := strconv.ParseInt(tt.productID, 10, 64)
+				tt.mockSetup(id)
+			}
+
+			req := httptest.NewRequest("DELETE", "/products/"+tt.productID, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+
+			// Set up auth context
+			claims := jwt.MapClaims{"email": testSeller.Email}
+			ctx := context.WithValue(req.Context(), "userClaims", claims)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			controller.DeleteProduct(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockProductService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAddToCart(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testName := strings.Replace(faker.Name(), " ", "", -1)
+	testDomain := faker.DomainName()
+
+	testEmail := fmt.Sprintf("%s@%s", testName, testDomain)
+	testCustomer := &model.User{ID: 1, Email: testEmail, Role: "customer"}
+
+	tests := []struct {
+		name           string
+		productID      string
+		mockSetup      func(productID, userID int64)
+		expectedStatus int
+	}{
+		{
+			name:      "Success - add to cart",
+			productID: "1",
+			mockSetup: func(productID, userID int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("AddToCart", mock.Anything, productID, int64(0), userID, 1).
+					Return(&model.Cart{Items: []model.CartItem{}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid product ID",
+			productID:      "invalid",
+			mockSetup:      func(productID, userID int64) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Service error",
+			productID: "2",
+			mockSetup: func(productID, userID int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("AddToCart", mock.Anything, productID, int64(0), userID, 1).
+					Return(nil, errors.New("service error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				productID, _ := strconv.ParseInt(tt.productID, 10, 64)
+				tt.mockSetup(productID, testCustomer.ID)
+			}
+
+			req := httptest.NewRequest("POST", "/products/cart/"+tt.productID, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+
+			// Set up auth context
+			claims := jwt.MapClaims{"email": testEmail}
+			ctx := context.WithValue(req.Context(), "userClaims", claims)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			controller.AddToCart(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockProductService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBuyProduct(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testName := strings.Replace(faker.Name(), " ", "", -1)
+	testDomain := faker.DomainName()
+
+	testEmail := fmt.Sprintf("%s@%s", testName, testDomain)
+	testCustomer := &model.User{
+		ID:       123,
+		Email:    testEmail,
+		Role:     "customer",
+		UserName: "ivan_ivanov",
+	}
+
+	tests := []struct {
+		name           string
+		productID      string
+		query          string
+		mockSetup      func(productID, userID int64)
+		expectedStatus int
+	}{
+		{
+			name:      "Success - buy product",
+			productID: "1",
+			mockSetup: func(productID, userID int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("BuyProduct", mock.Anything, productID, int64(0), userID).
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "Success - buy variant",
+			productID: "1",
+			query:     "?variant_id=5",
+			mockSetup: func(productID, userID int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("BuyProduct", mock.Anything, productID, int64(5), userID).
+					Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "Variant out of stock",
+			productID: "1",
+			query:     "?variant_id=5",
+			mockSetup: func(productID, userID int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("BuyProduct", mock.Anything, productID, int64(5), userID).
+					Return(service.ErrOutOfStock).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:      "Variant not chosen",
+			productID: "1",
+			mockSetup: func(productID, userID int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("BuyProduct", mock.Anything, productID, int64(0), userID).
+					Return(service.ErrVariantRequired).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid variant ID",
+			productID:      "1",
+			query:          "?variant_id=-1",
+			mockSetup:      func(productID, userID int64) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid product ID",
+			productID:      "invalid",
+			mockSetup:      func(productID, userID int64) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Service error",
+			productID: "2",
+			mockSetup: func(productID, userID int64) {
+				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("BuyProduct", mock.Anything, productID, int64(0), userID).
+					Return(errors.New("service error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				productID, _ := strconv.ParseInt(tt.productID, 10, 64)
+				tt.mockSetup(productID, testCustomer.ID)
+			}
+
+			req := httptest.NewRequest("POST", "/products/buy/"+tt.productID+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+
+			// Set up auth context
+			claims := jwt.MapClaims{"email": testEmail}
+			ctx := context.WithValue(req.Context(), "userClaims", claims)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			controller.BuyProduct(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockProductService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSearchProducts(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	page := &model.ProductSearchPage{
+		Items: []model.ProductSearchHit{{
+			Product:        model.Product{ID: 2, Title: "iPhone 15", Price: 1500},
+			Rank:           0.8,
+			TitleHighlight: "<mark>iPhone</mark> 15",
+		}},
+		Total: 1,
+		Limit: defaultPageLimit,
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:  "Success - ranked hits",
+			query: "?q=iphone&category_id=3",
+			mockSetup: func() {
+				mockProductService.On("SearchProducts", mock.Anything, model.ProductFilter{
+					Query:      "iphone",
+					CategoryID: 3,
+					Limit:      defaultPageLimit,
+				}).Return(page, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Missing query",
+			query: "",
+			mockSetup: func() {
+				mockProductService.On("SearchProducts", mock.Anything, model.ProductFilter{Limit: defaultPageLimit}).
+					Return(nil, service.ErrInvalidSearchQuery).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid filter",
+			query:          "?q=iphone&in_stock=maybe",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("GET", "/products/search"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			controller.SearchProducts(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"title_highlight":"\u003cmark\u003eiPhone\u003c/mark\u003e 15"`)
+				assert.Contains(t, rr.Body.String(), `"rank":0.8`)
+			}
+			mockProductService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateVariant(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testSeller := UserFactory{Role: "seller"}.Build()
+	price := int64(1990)
+	amount := 3
+
+	tests := []struct {
+		name           string
+		productID      string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - variant created",
+			productID:   "1",
+			requestBody: `{"sku": "TSHIRT-RED-M", "options": {"colour": "red", "size": "M"}, "price": 1990, "amount": 3}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("CreateVariant", mock.Anything, int64(1), model.VariantRequest{
+					SKU:     "TSHIRT-RED-M",
+					Options: map[string]string{"colour": "red", "size": "M"},
+					Price:   &price,
+					Amount:  &amount,
+				}, *testSeller).Return(&model.ProductVariant{ID: 5, ProductID: 1, SKU: "TSHIRT-RED-M"}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:        "Duplicate sku",
+			productID:   "1",
+			requestBody: `{"sku": "TSHIRT-RED-M", "price": 1990}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("CreateVariant", mock.Anything, int64(1), mock.Anything, *testSeller).
+					Return(nil, service.ErrVariantAlreadyExists).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Product of another seller",
+			productID:   "2",
+			requestBody: `{"sku": "MUG-1", "price": 500}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("CreateVariant", mock.Anything, int64(2), mock.Anything, *testSeller).
+					Return(nil, service.ErrForbidden).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Invalid payload",
+			productID:      "1",
+			requestBody:    `{"sku": 5}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/products/"+tt.productID+"/variants",
+				bytes.NewBufferString(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+			claims := jwt.MapClaims{"email": testSeller.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.CreateVariant(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockProductService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUploadProductImage(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewMarketplaceController(mockProductService, mockUserService)
+
+	testSeller := UserFactory{Role: "seller"}.Build()
+	png := []byte("\x89PNG\r\n\x1a\n image data")
 
-	testEmail := fmt.Sprintf("%s@%s", testName, testDomain)
-	testSeller := &model.User{ID: 1, Email: testEmail, Role: "seller"}
+	multipartBody := func(field string, content []byte) (*bytes.Buffer, string) {
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		assert.NoError(t, mw.WriteField("alt_text", "Front view"))
+		assert.NoError(t, mw.WriteField("primary", "true"))
+		part, err := mw.CreateFormFile(field, "photo.png")
+		assert.NoError(t, err)
+		_, err = part.Write(content)
+		assert.NoError(t, err)
+		assert.NoError(t, mw.Close())
+		return body, mw.FormDataContentType()
+	}
 
 	tests := []struct {
 		name           string
-		requestBody    string
+		field          string
+		content        []byte
 		mockSetup      func()
 		expectedStatus int
 	}{
 		{
-			name: "Success - valid product creation",
-			requestBody: `{
-                "title": "New Product",
-                "product_description": "Description",
-                "product_image": "image.jpg",
-                "price": 100,
-                "amount": 10
-            }`,
+			name:    "Success - image stored",
+			field:   "image",
+			content: png,
 			mockSetup: func() {
-				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
 					Return(testSeller, nil).Once()
-				mockProductService.On("CreateProduct", mock.Anything, mock.Anything, *testSeller).
-					Return(int64(1), nil).Once()
+				upload := model.ImageUpload{Data: png, AltText: "Front view", Primary: true}
+				mockProductService.On("UploadProductImage", mock.Anything, int64(1), upload, *testSeller).
+					Return(&model.ProductImage{ID: 5, ProductID: 1, URL: "http://localhost/static/products/1/abc.png",
+						AltText: "Front view", Primary: true}, nil).Once()
 			},
 			expectedStatus: http.StatusCreated,
 		},
 		{
-			name:           "Fail - invalid JSON",
-			requestBody:    `{ invalid json }`,
+			name:    "Not an image",
+			field:   "image",
+			content: []byte("#!/bin/sh"),
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("UploadProductImage", mock.Anything, int64(1), mock.Anything, *testSeller).
+					Return(nil, service.ErrUnsupportedImageType).Once()
+			},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "Missing image field",
+			field:          "file",
+			content:        png,
 			mockSetup:      func() {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "Body over the upload limit",
+			field:          "image",
+			content:        bytes.Repeat([]byte{0}, maxUploadBodySize+1),
+			mockSetup:      func() {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			req := httptest.NewRequest("POST", "/products", bytes.NewBufferString(tt.requestBody))
-
-			// Set up auth context
-			claims := jwt.MapClaims{"email": testEmail}
-			ctx := context.WithValue(req.Context(), "userClaims", claims)
-			req = req.WithContext(ctx)
+			body, contentType := multipartBody(tt.field, tt.content)
+			req := httptest.NewRequest("POST", "/products/1/images", body)
+			req.Header.Set("Content-Type", contentType)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+			claims := jwt.MapClaims{"email": testSeller.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
 
 			rr := httptest.NewRecorder()
-			controller.CreateProduct(rr, req)
+			controller.UploadProductImage(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				assert.Contains(t, rr.Body.String(), `"url":"http://localhost/static/products/1/abc.png"`)
+			}
 			mockProductService.AssertExpectations(t)
 			mockUserService.AssertExpectations(t)
 		})
 	}
 }
 
-func TestUpdateProduct(t *testing.T) {
+func TestReorderProductImages(t *testing.T) {
 	mockProductService := service.NewMockProductService(t)
 	mockUserService := service.NewMockUserService(t)
 	controller := NewMarketplaceController(mockProductService, mockUserService)
 
-	testName := strings.Replace(faker.Name(), " ", "", -1)
-	testDomain := faker.DomainName()
-
-	testEmail := fmt.Sprintf("%s@%s", testName, testDomain)
 	testSeller := UserFactory{Role: "seller"}.Build()
 
-	validUpdate := `{
-        "title": "Updated Product",
-        "product_description": "Updated Description",
-        "product_image": "updated_pic.jpg",
-        "price": 200,
-        "amount": 20
-    }`
-
 	tests := []struct {
 		name           string
-		productID      string
-		requestBody    string
-		setupMocks     func()
-		setupRequest   func(req *http.Request)
+		body           string
+		mockSetup      func()
 		expectedStatus int
 	}{
 		{
-			name:        "Success - update product",
-			productID:   "1",
-			requestBody: validUpdate,
-			setupMocks: func() {
-				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+			name: "Success - gallery reordered",
+			body: `{"image_ids": [3, 1, 2]}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
 					Return(testSeller, nil).Once()
-				mockProductService.On("UpdateProduct", mock.Anything, mock.Anything, int64(1), testSeller.ID).
-					Return(int64(1), nil).Once()
-			},
-			setupRequest: func(req *http.Request) {
-				claims := jwt.MapClaims{"email": testEmail}
-				ctx := context.WithValue(req.Context(), "userClaims", claims)
-				*req = *req.WithContext(ctx)
+				mockProductService.On("ReorderProductImages", mock.Anything, int64(1), []int64{3, 1, 2}, *testSeller).
+					Return([]model.ProductImage{
+						{ID: 3, ProductID: 1, Position: 0},
+						{ID: 1, ProductID: 1, Position: 1, Primary: true},
+						{ID: 2, ProductID: 1, Position: 2},
+					}, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:        "Invalid product ID",
-			productID:   "invalid",
-			requestBody: validUpdate,
-			setupMocks: func() {
-			},
-			setupRequest: func(req *http.Request) {
-				claims := jwt.MapClaims{"email": testEmail}
-				ctx := context.WithValue(req.Context(), "userClaims", claims)
-				*req = *req.WithContext(ctx)
+			name: "Incomplete order",
+			body: `{"image_ids": [3]}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("ReorderProductImages", mock.Anything, int64(1), []int64{3}, *testSeller).
+					Return(nil, service.ErrInvalidImageOrder).Once()
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:        "Invalid update data",
-			productID:   "2",
-			requestBody: `{"title": ""}`,
-			setupMocks: func() {
-			},
-			setupRequest: func(req *http.Request) {
-				claims := jwt.MapClaims{"email": testEmail}
-				ctx := context.WithValue(req.Context(), "userClaims", claims)
-				*req = *req.WithContext(ctx)
+			name: "Not the owner",
+			body: `{"image_ids": [1]}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("ReorderProductImages", mock.Anything, int64(1), []int64{1}, *testSeller).
+					Return(nil, service.ErrForbidden).Once()
 			},
-			expectedStatus: http.StatusBadRequest,
+			expectedStatus: http.StatusForbidden,
 		},
 		{
-			name:           "Unauthorized - no auth context",
-			productID:      "3",
-			requestBody:    validUpdate,
-			setupMocks:     func() {},
-			setupRequest:   func(req *http.Request) {},
-			expectedStatus: http.StatusUnauthorized,
+			name:           "Invalid payload",
+			body:           `{"image_ids": "1,2"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Setup mocks
-			tt.setupMocks()
-
-			// Create request
-			req := httptest.NewRequest("PUT", "/products/"+tt.productID, bytes.NewBufferString(tt.requestBody))
-			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+			tt.mockSetup()
 
-			// Setup request context
-			tt.setupRequest(req)
+			req := httptest.NewRequest("PUT", "/products/1/images/order", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+			claims := jwt.MapClaims{"email": testSeller.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
 
 			rr := httptest.NewRecorder()
-			controller.UpdateProduct(rr, req)
+			controller.ReorderProductImages(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
-
-			if tt.expectedStatus != http.StatusUnauthorized && tt.expectedStatus != http.StatusBadRequest {
-				mockProductService.AssertExpectations(t)
-			}
+			mockProductService.AssertExpectations(t)
 			mockUserService.AssertExpectations(t)
 		})
 	}
 }
 
-func TestDeleteProduct(t *testing.T) {
+func TestChangeProductStatus(t *testing.T) {
 	mockProductService := service.NewMockProductService(t)
 	mockUserService := service.NewMockUserService(t)
 	controller := NewMarketplaceController(mockProductService, mockUserService)
 
+	testSeller := UserFactory{Role: "seller"}.Build()
+	publishAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name           string
-		productID      string
-		mockSetup      func(id int64)
+		requestBody    string
+		mockSetup      func()
 		expectedStatus int
 	}{
 		{
-			name:      "Success - delete product",
-			productID: "123",
-			mockSetup: func(id int64) {
-				mockProductService.On("DeleteProduct", mock.Anything, id).
-					Return(nil).Once()
+			name:        "Success - publishing scheduled",
+			requestBody: `{"status": "scheduled", "publish_at": "2030-01-01T09:00:00Z"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				req := model.ProductStatusRequest{Status: model.ProductStatusScheduled, PublishAt: &publishAt}
+				mockProductService.On("ChangeProductStatus", mock.Anything, int64(1), req, *testSeller).
+					Return(&req, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:           "Invalid ID",
-			productID:      "invalid",
-			mockSetup:      func(id int64) {},
-			expectedStatus: http.StatusBadRequest,
+			name:        "Transition not allowed",
+			requestBody: `{"status": "scheduled", "publish_at": "2030-01-01T09:00:00Z"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("ChangeProductStatus", mock.Anything, int64(1), mock.Anything, *testSeller).
+					Return(nil, service.ErrInvalidStatusTransition).Once()
+			},
+			expectedStatus: http.StatusConflict,
 		},
 		{
-			name:      "Service error",
-			productID: "1",
-			mockSetup: func(id int64) {
-				mockProductService.On("DeleteProduct", mock.Anything, id).
-					Return(errors.New("service error")).Once()
+			name:        "Publish time in the past",
+			requestBody: `{"status": "scheduled", "publish_at": "2020-01-01T09:00:00Z"}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("ChangeProductStatus", mock.Anything, int64(1), mock.Anything, *testSeller).
+					Return(nil, service.ErrInvalidPublishTime).Once()
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid payload",
+			requestBody:    `{"status": "draft", "publish_at": "tomorrow"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.mockSetup != nil {
-				id, _ := strconv.ParseInt(tt.productID, 10, 64)
-				tt.mockSetup(id)
-			}
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/products/1/status", strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+			claims := jwt.MapClaims{"email": testSeller.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
 
-			req := httptest.NewRequest("DELETE", "/products/"+tt.productID, nil)
-			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
 			rr := httptest.NewRecorder()
-			controller.DeleteProduct(rr, req)
+			controller.ChangeProductStatus(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"status":"scheduled"`)
+				assert.Contains(t, rr.Body.String(), `"publish_at":"2030-01-01T09:00:00Z"`)
+			}
 			mockProductService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
 		})
 	}
 }
 
-func TestAddToCart(t *testing.T) {
+func TestRestoreProduct(t *testing.T) {
 	mockProductService := service.NewMockProductService(t)
 	mockUserService := service.NewMockUserService(t)
 	controller := NewMarketplaceController(mockProductService, mockUserService)
 
-	testName := strings.Replace(faker.Name(), " ", "", -1)
-	testDomain := faker.DomainName()
-
-	testEmail := fmt.Sprintf("%s@%s", testName, testDomain)
-	testCustomer := &model.User{ID: 1, Email: testEmail, Role: "customer"}
+	testSeller := UserFactory{Role: "seller"}.Build()
 
 	tests := []struct {
 		name           string
 		productID      string
-		mockSetup      func(productID, userID int64)
+		mockSetup      func()
 		expectedStatus int
 	}{
 		{
-			name:      "Success - add to cart",
+			name:      "Success",
 			productID: "1",
-			mockSetup: func(productID, userID int64) {
-				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
-					Return(testCustomer, nil).Once()
-				mockProductService.On("AddToCart", mock.Anything, productID, userID).
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("RestoreProduct", mock.Anything, int64(1), *testSeller).
 					Return(nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:           "Invalid product ID",
-			productID:      "invalid",
-			mockSetup:      func(productID, userID int64) {},
-			expectedStatus: http.StatusBadRequest,
-		},
-		{
-			name:      "Service error",
+			name:      "Not deleted or not owned",
 			productID: "2",
-			mockSetup: func(productID, userID int64) {
-				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
-					Return(testCustomer, nil).Once()
-				mockProductService.On("AddToCart", mock.Anything, productID, userID).
-					Return(errors.New("service error")).Once()
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).
+					Return(testSeller, nil).Once()
+				mockProductService.On("RestoreProduct", mock.Anything, int64(2), *testSeller).
+					Return(service.ErrProductNotFound).Once()
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid id",
+			productID:      "abc",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.mockSetup != nil {
-				productID, _ := strconv.ParseInt(tt.productID, 10, 64)
-				tt.mockSetup(productID, testCustomer.ID)
-			}
+			tt.mockSetup()
 
-			req := httptest.NewRequest("POST", "/products/cart/"+tt.productID, nil)
+			req := httptest.NewRequest("POST", "/products/"+tt.productID+"/restore", nil)
 			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
-
-			// Set up auth context
-			claims := jwt.MapClaims{"email": testEmail}
-			ctx := context.WithValue(req.Context(), "userClaims", claims)
-			req = req.WithContext(ctx)
+			claims := jwt.MapClaims{"email": testSeller.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
 
 			rr := httptest.NewRecorder()
-			controller.AddToCart(rr, req)
+			controller.RestoreProduct(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
 			mockProductService.AssertExpectations(t)
@@ -522,77 +1887,95 @@ func TestAddToCart(t *testing.T) {
 	}
 }
 
-func TestBuyProduct(t *testing.T) {
+func TestUpdateCartItem(t *testing.T) {
 	mockProductService := service.NewMockProductService(t)
 	mockUserService := service.NewMockUserService(t)
 	controller := NewMarketplaceController(mockProductService, mockUserService)
 
-	testName := strings.Replace(faker.Name(), " ", "", -1)
-	testDomain := faker.DomainName()
-
-	testEmail := fmt.Sprintf("%s@%s", testName, testDomain)
-	testCustomer := &model.User{
-		ID:       123,
-		Email:    testEmail,
-		Role:     "customer",
-		UserName: "ivan_ivanov",
+	testCustomer := UserFactory{Role: "customer"}.Build()
+	cart := &model.Cart{
+		Items:         []model.CartItem{{ProductID: 1, Title: "Lamp", Price: 1500, Quantity: 2, Available: 5, LineTotal: 3000}},
+		TotalQuantity: 2,
+		Total:         3000,
 	}
 
 	tests := []struct {
 		name           string
-		productID      string
-		mockSetup      func(productID, userID int64)
+		query          string
+		requestBody    string
+		mockSetup      func()
 		expectedStatus int
 	}{
 		{
-			name:      "Success - buy product",
-			productID: "1",
-			mockSetup: func(productID, userID int64) {
-				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+			name:        "Success",
+			requestBody: `{"quantity": 2}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testCustomer.Email).
 					Return(testCustomer, nil).Once()
-				mockProductService.On("BuyProduct", mock.Anything, productID, userID).
-					Return(nil).Once()
+				mockProductService.On("UpdateCartItem", mock.Anything, int64(1), int64(0), testCustomer.ID, 2).
+					Return(cart, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:           "Invalid product ID",
-			productID:      "invalid",
-			mockSetup:      func(productID, userID int64) {},
-			expectedStatus: http.StatusBadRequest,
+			name:        "Variant not in cart",
+			query:       "?variant_id=3",
+			requestBody: `{"quantity": 1}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testCustomer.Email).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("UpdateCartItem", mock.Anything, int64(1), int64(3), testCustomer.ID, 1).
+					Return(nil, service.ErrCartItemNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
 		},
 		{
-			name:      "Service error",
-			productID: "2",
-			mockSetup: func(productID, userID int64) {
-				mockUserService.On("GetUserByEmail", mock.Anything, testEmail).
+			name:        "Not enough stock",
+			requestBody: `{"quantity": 9}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testCustomer.Email).
 					Return(testCustomer, nil).Once()
-				mockProductService.On("BuyProduct", mock.Anything, productID, userID).
-					Return(errors.New("service error")).Once()
+				mockProductService.On("UpdateCartItem", mock.Anything, int64(1), int64(0), testCustomer.ID, 9).
+					Return(nil, fmt.Errorf("%w: only 5 left", service.ErrOutOfStock)).Once()
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Invalid quantity",
+			requestBody: `{"quantity": 100}`,
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testCustomer.Email).
+					Return(testCustomer, nil).Once()
+				mockProductService.On("UpdateCartItem", mock.Anything, int64(1), int64(0), testCustomer.ID, 100).
+					Return(nil, service.ErrInvalidQuantity).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid payload",
+			requestBody:    `{"quantity": "two"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.mockSetup != nil {
-				productID, _ := strconv.ParseInt(tt.productID, 10, 64)
-				tt.mockSetup(productID, testCustomer.ID)
-			}
-
-			req := httptest.NewRequest("POST", "/products/buy/"+tt.productID, nil)
-			req = mux.SetURLVars(req, map[string]string{"id": tt.productID})
+			tt.mockSetup()
 
-			// Set up auth context
-			claims := jwt.MapClaims{"email": testEmail}
-			ctx := context.WithValue(req.Context(), "userClaims", claims)
-			req = req.WithContext(ctx)
+			req := httptest.NewRequest("PUT", "/cart/items/1"+tt.query, strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+			claims := jwt.MapClaims{"email": testCustomer.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
 
 			rr := httptest.NewRecorder()
-			controller.BuyProduct(rr, req)
+			controller.UpdateCartItem(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"line_total":3000`)
+				assert.Contains(t, rr.Body.String(), `"total":3000`)
+			}
 			mockProductService.AssertExpectations(t)
 			mockUserService.AssertExpectations(t)
 		})
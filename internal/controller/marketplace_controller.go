@@ -3,10 +3,14 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/middleware"
@@ -29,22 +33,85 @@ func NewMarketplaceController(servicePr service.ProductService, serviceUs servic
 	}
 }
 
-func (c *MarketplaceController) RegisterRoutes(router *mux.Router) {
+// route describes a protected endpoint together with the roles allowed to call it
+// and the scope an API key needs for it
+type route struct {
+	path    string
+	method  string
+	handler http.HandlerFunc
+	roles   []string
+	scope   string
+}
+
+// noAPIKey marks routes that can only be called with an access token
+const noAPIKey = ""
+
+// guarded wraps the handler with the role and scope checks of the route
+func (rt route) guarded() http.Handler {
+	return middleware.RequireRole(rt.roles...)(middleware.RequireScope(rt.scope)(rt.handler))
+}
+
+var (
+	anyRole     = []string{model.RoleCustomer, model.RoleSeller, model.RoleAdmin}
+	buyerRoles  = []string{model.RoleCustomer, model.RoleSeller}
+	sellerRoles = []string{model.RoleSeller, model.RoleAdmin}
+)
+
+// protectedRoutes is the access policy of the API: every authenticated route
+// must be listed here with the roles that may use it
+func (c *MarketplaceController) protectedRoutes() []route {
+	return []route{
+		{"/user/logout", "POST", c.LogoutUser, anyRole, noAPIKey},
+		{"/user/me", "GET", c.GetMe, anyRole, noAPIKey},
+		{"/user/me", "PUT", c.UpdateMe, anyRole, noAPIKey},
+		{"/user/me/password", "POST", c.ChangePassword, anyRole, noAPIKey},
+
+		{"/products", "GET", c.GetAllProducts, anyRole, model.ScopeProductsRead},
+		// Before /products/{id}, which would take "search" and "mine" for ids
+		{"/products/search", "GET", c.SearchProducts, anyRole, model.ScopeProductsRead},
+		{"/products/mine", "GET", c.ListMyProducts, sellerRoles, model.ScopeProductsRead},
+		{"/products/{id}", "GET", c.GetProductByID, anyRole, model.ScopeProductsRead},
+		{"/products", "POST", c.CreateProduct, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}", "PUT", c.UpdateProduct, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}", "DELETE", c.DeleteProduct, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/status", "POST", c.ChangeProductStatus, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/restore", "POST", c.RestoreProduct, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/images", "POST", c.UploadProductImage, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/images/order", "PUT", c.ReorderProductImages, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/images/{image_id}", "PUT", c.UpdateProductImage, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/images/{image_id}", "DELETE", c.DeleteProductImage, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/variants", "POST", c.CreateVariant, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/variants/{variant_id}", "PUT", c.UpdateVariant, sellerRoles, model.ScopeProductsWrite},
+		{"/products/{id}/variants/{variant_id}", "DELETE", c.DeleteVariant, sellerRoles, model.ScopeProductsWrite},
+
+		{"/products/cart/{id}", "POST", c.AddToCart, buyerRoles, noAPIKey},
+		{"/products/buy/{id}", "POST", c.BuyProduct, buyerRoles, noAPIKey},
+
+		{"/cart", "GET", c.GetCart, buyerRoles, noAPIKey},
+		{"/cart", "DELETE", c.ClearCart, buyerRoles, noAPIKey},
+		{"/cart/items", "POST", c.AddCartItem, buyerRoles, noAPIKey},
+		{"/cart/items/{id}", "PUT", c.UpdateCartItem, buyerRoles, noAPIKey},
+		{"/cart/items/{id}", "DELETE", c.RemoveCartItem, buyerRoles, noAPIKey},
+	}
+}
+
+func (c *MarketplaceController) RegisterRoutes(router *mux.Router, authMiddleware mux.MiddlewareFunc) {
 	router.HandleFunc("/user", c.CreateUser).Methods("POST")
 	router.HandleFunc("/user/login", c.LoginUser).Methods("POST")
+	router.HandleFunc("/user/token/refresh", c.RefreshToken).Methods("POST")
+	router.HandleFunc("/user/verify/request", c.RequestEmailVerification).Methods("POST")
+	router.HandleFunc("/user/verify/confirm", c.ConfirmEmail).Methods("GET", "POST")
+	router.HandleFunc("/user/password/forgot", c.RequestPasswordReset).Methods("POST")
+	router.HandleFunc("/user/password/reset", c.ResetPasswordPage).Methods("GET")
+	router.HandleFunc("/user/password/reset", c.ResetPassword).Methods("POST")
 
 	// Protected routes (auth required)
 	protectedRouter := router.PathPrefix("").Subrouter()
-	protectedRouter.Use(middleware.AuthMiddleware)
+	protectedRouter.Use(authMiddleware)
 
-	protectedRouter.HandleFunc("/products", c.GetAllProducts).Methods("GET")
-	protectedRouter.HandleFunc("/products/{id}", c.GetProductByID).Methods("GET")
-	protectedRouter.HandleFunc("/products", c.CreateProduct).Methods("POST")
-	protectedRouter.HandleFunc("/products/{id}", c.UpdateProduct).Methods("PUT")
-	protectedRouter.HandleFunc("/products/{id}", c.DeleteProduct).Methods("DELETE")
-
-	protectedRouter.HandleFunc("/products/cart/{id}", c.AddToCart).Methods("POST")
-	protectedRouter.HandleFunc("/products/buy/{id}", c.BuyProduct).Methods("POST")
+	for _, rt := range c.protectedRoutes() {
+		protectedRouter.Handle(rt.path, rt.guarded()).Methods(rt.method)
+	}
 }
 
 func (c *MarketplaceController) CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -132,20 +199,32 @@ func (c *MarketplaceController) LoginUser(w http.ResponseWriter, r *http.Request
 	}
 
 	// Call service
-	token, err := c.usrSrvc.LoginUser(ctx, loginReq)
+	tokens, err := c.usrSrvc.LoginUser(ctx, loginReq, clientIP(r))
+	var lockedErr *service.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		utils.RespondWithError(w, http.StatusTooManyRequests, "Too many failed login attempts, try again later")
+		return
+	}
+	if errors.Is(err, service.ErrUserBanned) {
+		utils.RespondWithError(w, http.StatusForbidden, "Account is banned")
+		return
+	}
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		utils.RespondWithError(w, http.StatusForbidden, "Email is not verified")
+		return
+	}
 	if err != nil {
 		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
-		"TokenBearer": token,
-	})
+	utils.RespondWithJSON(w, http.StatusOK, tokens)
 }
 
-func (c *MarketplaceController) GetAllProducts(w http.ResponseWriter, r *http.Request) {
+func (c *MarketplaceController) RefreshToken(w http.ResponseWriter, r *http.Request) {
 
-	const op = "controller.GetAllProducts"
+	const op = "controller.RefreshToken"
 
 	var err error
 
@@ -158,22 +237,37 @@ func (c *MarketplaceController) GetAllProducts(w http.ResponseWriter, r *http.Re
 	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
 	defer cancel()
 
-	products, err := c.prSrvc.GetAllProducts(ctx)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	var refreshReq model.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&refreshReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	if products == nil {
-		products = []model.Product{}
+	if refreshReq.RefreshToken == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Refresh token is required")
+		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, products)
+	tokens, err := c.usrSrvc.RefreshToken(ctx, refreshReq.RefreshToken)
+	if errors.Is(err, service.ErrInvalidToken) {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired refresh token")
+		return
+	}
+	if errors.Is(err, service.ErrUserBanned) {
+		utils.RespondWithError(w, http.StatusForbidden, "Account is banned")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, tokens)
 }
 
-func (c *MarketplaceController) GetProductByID(w http.ResponseWriter, r *http.Request) {
+func (c *MarketplaceController) LogoutUser(w http.ResponseWriter, r *http.Request) {
 
-	const op = "controller.GetProductByID"
+	const op = "controller.LogoutUser"
 
 	var err error
 
@@ -186,29 +280,55 @@ func (c *MarketplaceController) GetProductByID(w http.ResponseWriter, r *http.Re
 	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
 	defer cancel()
 
-	vars := mux.Vars(r)
-	id := vars["id"]
-	intId, err := strconv.ParseInt(id, 10, 64)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid id")
+	claims, ok := utils.GetUserClaimsFromContext(r)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
 		return
 	}
 
-	Product, err := c.prSrvc.GetProductByID(ctx, intId)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	jti, ok := claims["jti"].(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Token ID not found in token")
 		return
 	}
-	if Product == nil {
-		utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Token expiry not found in token")
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, Product)
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// The refresh token is optional: without it only the access token is revoked
+	var logoutReq model.RefreshTokenRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&logoutReq); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+
+	err = c.usrSrvc.LogoutUser(ctx, int64(userID), jti, time.Unix(int64(exp), 0), logoutReq.RefreshToken)
+	if errors.Is(err, service.ErrInvalidToken) {
+		utils.RespondWithError(w, http.StatusForbidden, "Refresh token belongs to another user")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to logout")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
 }
 
-func (c *MarketplaceController) CreateProduct(w http.ResponseWriter, r *http.Request) {
+func (c *MarketplaceController) RequestEmailVerification(w http.ResponseWriter, r *http.Request) {
 
-	const op = "controller.CreateProduct"
+	const op = "controller.RequestEmailVerification"
 
 	var err error
 
@@ -221,49 +341,74 @@ func (c *MarketplaceController) CreateProduct(w http.ResponseWriter, r *http.Req
 	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
 	defer cancel()
 
-	// Only decode ONCE at the start
-	var prReq model.CreateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&prReq); err != nil {
+	var emailReq model.EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&emailReq); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	// Validation checks
-	if prReq.Title == "" || prReq.ProductImage == "" || prReq.Price <= 0 || prReq.Amount <= 0 {
-		utils.RespondWithError(w, http.StatusBadRequest, "Wrong product data")
+	if emailReq.Email == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Email is required")
 		return
 	}
 
-	claims, ok := utils.GetUserClaimsFromContext(r)
-	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+	err = c.usrSrvc.RequestEmailVerification(ctx, emailReq.Email)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to send verification email")
 		return
 	}
 
-	userEmail, ok := claims["email"].(string)
-	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "User email not found in token")
+	// Same answer whether the email is registered or not
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]string{
+		"message": "If the account exists and is not verified, a verification email has been sent",
+	})
+}
+
+func (c *MarketplaceController) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ConfirmEmail"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	// The link in the verification email is opened with GET and carries the token in the query
+	var confirmReq model.ConfirmEmailRequest
+	if r.Method == http.MethodGet {
+		confirmReq.Token = r.URL.Query().Get("token")
+	} else if err := json.NewDecoder(r.Body).Decode(&confirmReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "User not found by email")
+	if confirmReq.Token == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Token is required")
 		return
 	}
 
-	product, err := c.prSrvc.CreateProduct(ctx, prReq, *curUser)
+	err = c.usrSrvc.ConfirmEmail(ctx, confirmReq.Token)
+	if errors.Is(err, service.ErrInvalidToken) {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to confirm email")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusCreated, product)
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Email confirmed"})
 }
 
-func (c *MarketplaceController) AddToCart(w http.ResponseWriter, r *http.Request) {
+func (c *MarketplaceController) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
 
-	const op = "controller.AddToCart"
+	const op = "controller.RequestPasswordReset"
 
 	var err error
 
@@ -273,48 +418,35 @@ func (c *MarketplaceController) AddToCart(w http.ResponseWriter, r *http.Request
 		}
 	}()
 
-	claims, ok := utils.GetUserClaimsFromContext(r)
-	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
-		return
-	}
-
-	vars := mux.Vars(r)
-	id := vars["id"]
-	intId, err := strconv.ParseInt(id, 10, 64)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
-		return
-	}
-
 	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
 	defer cancel()
 
-	userEmail, ok := claims["email"].(string)
-	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "User email not found in token")
+	var emailReq model.EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&emailReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "User not found by email")
+	if emailReq.Email == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Email is required")
 		return
 	}
 
-	err = c.prSrvc.AddToCart(ctx, intId, curUser.ID)
-
+	err = c.usrSrvc.RequestPasswordReset(ctx, emailReq.Email)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to send password reset email")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, "Added to cart")
+	// Same answer whether the email is registered or not
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]string{
+		"message": "If the account exists, a password reset email has been sent",
+	})
 }
 
-func (c *MarketplaceController) BuyProduct(w http.ResponseWriter, r *http.Request) {
+func (c *MarketplaceController) ResetPassword(w http.ResponseWriter, r *http.Request) {
 
-	const op = "controller.BuyProduct"
+	const op = "controller.ResetPassword"
 
 	var err error
 
@@ -324,48 +456,90 @@ func (c *MarketplaceController) BuyProduct(w http.ResponseWriter, r *http.Reques
 		}
 	}()
 
-	claims, ok := utils.GetUserClaimsFromContext(r)
-	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	// The form of ResetPasswordPage posts the fields url encoded, API clients send JSON
+	var resetReq model.ResetPasswordRequest
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		resetReq.Token = r.PostFormValue("token")
+		resetReq.Password = r.PostFormValue("password")
+	} else if err := json.NewDecoder(r.Body).Decode(&resetReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	vars := mux.Vars(r)
-	id := vars["id"]
-	intId, err := strconv.ParseInt(id, 10, 64)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+	if resetReq.Token == "" || resetReq.Password == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Token and password are required")
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
-	defer cancel()
-
-	userEmail, ok := claims["email"].(string)
-	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "User email not found in token")
+	hashedPassword, err := utils.HashPassword(resetReq.Password)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process password")
 		return
 	}
 
-	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
+	err = c.usrSrvc.ResetPassword(ctx, resetReq.Token, hashedPassword)
+	if errors.Is(err, service.ErrInvalidToken) {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "User not found by email")
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to reset password")
 		return
 	}
 
-	err = c.prSrvc.BuyProduct(ctx, intId, curUser.ID)
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Password has been reset"})
+}
 
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+// resetPasswordPage asks for the new password and posts it with the token back to the URL it was opened at
+var resetPasswordPage = template.Must(template.New("reset").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Reset password</title></head>
+<body>
+<form method="post" action="">
+<input type="hidden" name="token" value="{{.}}">
+<label>New password <input type="password" name="password" required></label>
+<button type="submit">Set password</button>
+</form>
+</body>
+</html>
+`))
+
+// ResetPasswordPage is opened from the link in the password reset email
+func (c *MarketplaceController) ResetPasswordPage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Token is required")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, "Product purchased")
+	// The token is in the URL, it must not leak to caches or through the referrer
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if err := resetPasswordPage.Execute(w, token); err != nil {
+		log.Println(fmt.Errorf("controller.ResetPasswordPage: %w", err))
+	}
 }
 
-func (c *MarketplaceController) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+func (c *MarketplaceController) GetMe(w http.ResponseWriter, r *http.Request) {
 
-	const op = "controller.UpdateProduct"
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, curUser)
+}
+
+func (c *MarketplaceController) UpdateMe(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UpdateMe"
 
 	var err error
 
@@ -375,64 +549,88 @@ func (c *MarketplaceController) UpdateProduct(w http.ResponseWriter, r *http.Req
 		}
 	}()
 
-	claims, ok := utils.GetUserClaimsFromContext(r)
-	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	var profileReq model.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&profileReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	userEmail, ok := claims["email"].(string)
-	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in token")
+	if profileReq.UserName == "" && profileReq.Email == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Name or email is required")
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
-	defer cancel()
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
 
-	vars := mux.Vars(r)
-	id := vars["id"]
-	intId, err := strconv.ParseInt(id, 10, 64)
+	updated, err := c.usrSrvc.UpdateProfile(ctx, *curUser, profileReq)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		respondWithServiceError(w, err)
 		return
 	}
 
-	var updatePrReq model.UpdateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&updatePrReq); err != nil {
+	utils.RespondWithJSON(w, http.StatusOK, updated)
+}
+
+func (c *MarketplaceController) ChangePassword(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ChangePassword"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	var passwordReq model.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&passwordReq); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	if updatePrReq.Price < 0 || updatePrReq.Amount < 0 {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	if passwordReq.OldPassword == "" || passwordReq.NewPassword == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Old and new passwords are required")
 		return
 	}
 
-	if updatePrReq.ProductImage == "" && updatePrReq.ProductDescription == "" &&
-		updatePrReq.Title == "" && updatePrReq.Amount <= 0 && updatePrReq.Price <= 0 {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
 		return
 	}
 
-	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
+	hashedPassword, err := utils.HashPassword(passwordReq.NewPassword)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "User not found")
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process password")
 		return
 	}
 
-	resID, err := c.prSrvc.UpdateProduct(ctx, updatePrReq, intId, curUser.ID)
+	err = c.usrSrvc.ChangePassword(ctx, *curUser, passwordReq.OldPassword, hashedPassword)
+	if errors.Is(err, service.ErrWrongPassword) {
+		utils.RespondWithError(w, http.StatusForbidden, "Old password is incorrect")
+		return
+	}
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to change password")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, resID)
+	// Every token of the user is revoked, the client has to log in again
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Password changed, please log in again"})
 }
 
-func (c *MarketplaceController) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+func (c *MarketplaceController) GetAllProducts(w http.ResponseWriter, r *http.Request) {
 
-	const op = "controller.DeleteProduct"
+	const op = "controller.GetAllProducts"
 
 	var err error
 
@@ -445,18 +643,1039 @@ func (c *MarketplaceController) DeleteProduct(w http.ResponseWriter, r *http.Req
 	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
 	defer cancel()
 
-	vars := mux.Vars(r)
-	id := vars["id"]
-	intId, err := strconv.ParseInt(id, 10, 64)
+	filter, err := parseProductFilter(r)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid id")
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err = c.prSrvc.DeleteProduct(ctx, intId)
+	page, err := c.prSrvc.ListProducts(ctx, filter)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithServiceError(w, err)
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product deleted successfully"})
+
+	utils.RespondWithJSON(w, http.StatusOK, page)
+}
+
+func (c *MarketplaceController) SearchProducts(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.SearchProducts"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	filter, err := parseProductFilter(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Query = r.URL.Query().Get("q")
+
+	page, err := c.prSrvc.SearchProducts(ctx, filter)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, page)
+}
+
+func (c *MarketplaceController) GetProductByID(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.GetProductByID"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	intId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	Product, err := c.prSrvc.GetProductByID(ctx, intId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if Product == nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, Product)
+}
+
+func (c *MarketplaceController) CreateProduct(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.CreateProduct"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	// Only decode ONCE at the start
+	var prReq model.CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&prReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Validation checks
+	if prReq.Title == "" || prReq.ProductImage == "" || prReq.Price <= 0 || prReq.Amount <= 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Wrong product data")
+		return
+	}
+
+	claims, ok := utils.GetUserClaimsFromContext(r)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+		return
+	}
+
+	userEmail, ok := claims["email"].(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User email not found in token")
+		return
+	}
+
+	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "User not found by email")
+		return
+	}
+
+	product, err := c.prSrvc.CreateProduct(ctx, prReq, *curUser)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, product)
+}
+
+// AddToCart adds the product to the cart, quantity items of it when the quantity parameter is set
+func (c *MarketplaceController) AddToCart(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.AddToCart"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	claims, ok := utils.GetUserClaimsFromContext(r)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	intId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	userEmail, ok := claims["email"].(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User email not found in token")
+		return
+	}
+
+	variantID, err := variantParam(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant id")
+		return
+	}
+
+	quantity := 1
+	if v := r.URL.Query().Get("quantity"); v != "" {
+		if quantity, err = strconv.Atoi(v); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid quantity")
+			return
+		}
+	}
+
+	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "User not found by email")
+		return
+	}
+
+	cart, err := c.prSrvc.AddToCart(ctx, intId, variantID, curUser.ID, quantity)
+
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+func (c *MarketplaceController) BuyProduct(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.BuyProduct"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	claims, ok := utils.GetUserClaimsFromContext(r)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	intId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	userEmail, ok := claims["email"].(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User email not found in token")
+		return
+	}
+
+	variantID, err := variantParam(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant id")
+		return
+	}
+
+	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "User not found by email")
+		return
+	}
+
+	err = c.prSrvc.BuyProduct(ctx, intId, variantID, curUser.ID)
+
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, "Product purchased")
+}
+
+func (c *MarketplaceController) GetCart(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.GetCart"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	cart, err := c.prSrvc.GetCart(ctx, curUser.ID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+func (c *MarketplaceController) AddCartItem(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.AddCartItem"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	var itemReq model.CartItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&itemReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if itemReq.ProductID <= 0 || itemReq.VariantID < 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product or variant id")
+		return
+	}
+	// One item when the quantity is left out
+	if itemReq.Quantity == 0 {
+		itemReq.Quantity = 1
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	cart, err := c.prSrvc.AddToCart(ctx, itemReq.ProductID, itemReq.VariantID, curUser.ID, itemReq.Quantity)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// UpdateCartItem sets the quantity of the product in the cart, or of its variant given by variant_id
+func (c *MarketplaceController) UpdateCartItem(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UpdateCartItem"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	variantID, err := variantParam(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant id")
+		return
+	}
+
+	var updateReq model.UpdateCartItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	cart, err := c.prSrvc.UpdateCartItem(ctx, productID, variantID, curUser.ID, updateReq.Quantity)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// RemoveCartItem takes the product, or its variant given by variant_id, out of the cart
+func (c *MarketplaceController) RemoveCartItem(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.RemoveCartItem"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	variantID, err := variantParam(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant id")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	cart, err := c.prSrvc.RemoveFromCart(ctx, productID, variantID, curUser.ID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+func (c *MarketplaceController) ClearCart(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ClearCart"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	err = c.prSrvc.ClearCart(ctx, curUser.ID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Cart cleared"})
+}
+
+func (c *MarketplaceController) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UpdateProduct"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	claims, ok := utils.GetUserClaimsFromContext(r)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+		return
+	}
+
+	userEmail, ok := claims["email"].(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in token")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	intId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	var updatePrReq model.UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&updatePrReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if updatePrReq.Price < 0 || updatePrReq.Amount < 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if updatePrReq.CategoryID != nil && *updatePrReq.CategoryID < 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if updatePrReq.ProductImage == "" && updatePrReq.ProductDescription == "" &&
+		updatePrReq.Title == "" && updatePrReq.Amount <= 0 && updatePrReq.Price <= 0 &&
+		updatePrReq.CategoryID == nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "User not found")
+		return
+	}
+
+	resID, err := c.prSrvc.UpdateProduct(ctx, updatePrReq, intId, *curUser)
+	if errors.Is(err, service.ErrForbidden) {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+	if errors.Is(err, service.ErrProductNotFound) {
+		utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+	if errors.Is(err, service.ErrUnknownCategory) || errors.Is(err, service.ErrImageNotInGallery) {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrProductHasVariants) || errors.Is(err, service.ErrAmountBelowReserved) {
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, resID)
+}
+
+func (c *MarketplaceController) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.DeleteProduct"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	claims, ok := utils.GetUserClaimsFromContext(r)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+		return
+	}
+
+	userEmail, ok := claims["email"].(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User email not found in token")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	intId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	curUser, err := c.usrSrvc.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "User not found")
+		return
+	}
+
+	err = c.prSrvc.DeleteProduct(ctx, intId, *curUser)
+	if errors.Is(err, service.ErrForbidden) {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+	if errors.Is(err, service.ErrProductNotFound) {
+		utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product deleted successfully"})
+}
+
+// RestoreProduct brings back a deleted product of the current seller, admins can restore any product
+func (c *MarketplaceController) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.RestoreProduct"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	err = c.prSrvc.RestoreProduct(ctx, productID, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product restored successfully"})
+}
+
+func (c *MarketplaceController) CreateVariant(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.CreateVariant"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	var variantReq model.VariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&variantReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	variant, err := c.prSrvc.CreateVariant(ctx, productID, variantReq, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, variant)
+}
+
+func (c *MarketplaceController) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UpdateVariant"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	productID, err := pathID(vars, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+	variantID, err := pathID(vars, "variant_id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant id")
+		return
+	}
+
+	var variantReq model.VariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&variantReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	variant, err := c.prSrvc.UpdateVariant(ctx, productID, variantID, variantReq, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, variant)
+}
+
+func (c *MarketplaceController) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.DeleteVariant"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	productID, err := pathID(vars, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+	variantID, err := pathID(vars, "variant_id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant id")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	err = c.prSrvc.DeleteVariant(ctx, productID, variantID, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Variant deleted successfully"})
+}
+
+// ListMyProducts returns the products of the current seller in any status, drafts and archived included.
+// With deleted=true it returns the deleted products instead, which can still be restored.
+func (c *MarketplaceController) ListMyProducts(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ListMyProducts"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var deleted bool
+	if v := r.URL.Query().Get("deleted"); v != "" {
+		if deleted, err = strconv.ParseBool(v); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid deleted")
+			return
+		}
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	products, err := c.prSrvc.ListSellerProducts(ctx, model.SellerProductFilter{
+		SellerID: curUser.ID,
+		Status:   r.URL.Query().Get("status"),
+		Deleted:  deleted,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, products)
+}
+
+func (c *MarketplaceController) ChangeProductStatus(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ChangeProductStatus"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	var statusReq model.ProductStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&statusReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	status, err := c.prSrvc.ChangeProductStatus(ctx, productID, statusReq, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":         productID,
+		"status":     status.Status,
+		"publish_at": status.PublishAt,
+	})
+}
+
+func (c *MarketplaceController) UploadProductImage(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UploadProductImage"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	data, err := readUploadedFile(w, r, "image")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, service.ErrImageTooLarge.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Expected a multipart form with an image file")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	upload := model.ImageUpload{
+		Data:    data,
+		AltText: r.FormValue("alt_text"),
+		Primary: r.FormValue("primary") == "true",
+	}
+	image, err := c.prSrvc.UploadProductImage(ctx, productID, upload, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, image)
+}
+
+func (c *MarketplaceController) UpdateProductImage(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.UpdateProductImage"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	productID, err := pathID(vars, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+	imageID, err := pathID(vars, "image_id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid image id")
+		return
+	}
+
+	var imageReq model.UpdateImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&imageReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	image, err := c.prSrvc.UpdateProductImage(ctx, productID, imageID, imageReq, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, image)
+}
+
+func (c *MarketplaceController) ReorderProductImages(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ReorderProductImages"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	productID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+
+	var orderReq model.ReorderImagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&orderReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	images, err := c.prSrvc.ReorderProductImages(ctx, productID, orderReq.ImageIDs, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, images)
+}
+
+func (c *MarketplaceController) DeleteProductImage(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.DeleteProductImage"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	productID, err := pathID(vars, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return
+	}
+	imageID, err := pathID(vars, "image_id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid image id")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	err = c.prSrvc.DeleteProductImage(ctx, productID, imageID, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Image deleted successfully"})
 }
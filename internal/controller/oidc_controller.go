@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// oidcStateCookie binds a login to the browser that started it, so nobody can
+// make a victim complete a login with the attacker's account at the provider
+const oidcStateCookie = "oidc_state"
+
+type OIDCController struct {
+	oidcSrvc      service.OIDCService
+	secureCookies bool
+}
+
+// NewOIDCController creates the controller of logins at external providers.
+// secureCookies must be set when the application is served over HTTPS.
+func NewOIDCController(serviceOIDC service.OIDCService, secureCookies bool) *OIDCController {
+	return &OIDCController{
+		oidcSrvc:      serviceOIDC,
+		secureCookies: secureCookies,
+	}
+}
+
+func (c *OIDCController) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/auth/oidc/providers", c.ListProviders).Methods("GET")
+	router.HandleFunc("/auth/oidc/{provider}/login", c.StartLogin).Methods("GET")
+	router.HandleFunc("/auth/oidc/{provider}/callback", c.Callback).Methods("GET")
+}
+
+func (c *OIDCController) ListProviders(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"providers": c.oidcSrvc.Providers(),
+	})
+}
+
+func (c *OIDCController) StartLogin(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.StartOIDCLogin"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	authURL, state, err := c.oidcSrvc.StartLogin(ctx, mux.Vars(r)["provider"])
+	if errors.Is(err, service.ErrUnknownOIDCProvider) {
+		utils.RespondWithError(w, http.StatusNotFound, "Unknown identity provider")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+
+	c.setStateCookie(w, state, 0)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (c *OIDCController) Callback(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.OIDCCallback"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	query := r.URL.Query()
+	state, code := query.Get("state"), query.Get("code")
+
+	// The state is single use either way
+	c.setStateCookie(w, "", -1)
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Login was refused by the identity provider: "+providerErr)
+		return
+	}
+
+	cookie, cookieErr := r.Cookie(oidcStateCookie)
+	if state == "" || code == "" || cookieErr != nil ||
+		subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid or expired login state")
+		return
+	}
+
+	tokens, err := c.oidcSrvc.CompleteLogin(ctx, mux.Vars(r)["provider"], code, state)
+	switch {
+	case err == nil:
+		utils.RespondWithJSON(w, http.StatusOK, tokens)
+	case errors.Is(err, service.ErrUnknownOIDCProvider):
+		utils.RespondWithError(w, http.StatusNotFound, "Unknown identity provider")
+	case errors.Is(err, service.ErrInvalidToken):
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid or expired login state")
+	case errors.Is(err, service.ErrOIDCLoginFailed):
+		utils.RespondWithError(w, http.StatusUnauthorized, "Login with the identity provider failed")
+	case errors.Is(err, service.ErrOIDCEmailNotVerified):
+		utils.RespondWithError(w, http.StatusForbidden, "The identity provider did not verify your email")
+	case errors.Is(err, service.ErrUserBanned):
+		utils.RespondWithError(w, http.StatusForbidden, "Account is banned")
+	default:
+		respondWithServiceError(w, err)
+	}
+}
+
+// setStateCookie stores the state for the callback, a negative maxAge deletes the cookie
+func (c *OIDCController) setStateCookie(w http.ResponseWriter, state string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/auth/oidc/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.secureCookies,
+		// Lax, the callback is a top-level redirect from the provider
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+)
+
+func TestStartOIDCLogin(t *testing.T) {
+	mockOIDCService := service.NewMockOIDCService(t)
+	controller := NewOIDCController(mockOIDCService, true)
+
+	tests := []struct {
+		name           string
+		provider       string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:     "Success - redirected to provider",
+			provider: "corp",
+			mockSetup: func() {
+				mockOIDCService.On("StartLogin", mock.Anything, "corp").
+					Return("https://idp.example.com/authorize?state=state-1", "state-1", nil).Once()
+			},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			name:     "Unknown provider",
+			provider: "other",
+			mockSetup: func() {
+				mockOIDCService.On("StartLogin", mock.Anything, "other").
+					Return("", "", service.ErrUnknownOIDCProvider).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("GET", "/auth/oidc/"+tt.provider+"/login", nil)
+			req = mux.SetURLVars(req, map[string]string{"provider": tt.provider})
+
+			rr := httptest.NewRecorder()
+			controller.StartLogin(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusFound {
+				assert.Equal(t, "https://idp.example.com/authorize?state=state-1", rr.Header().Get("Location"))
+
+				cookies := rr.Result().Cookies()
+				if assert.Len(t, cookies, 1) {
+					assert.Equal(t, oidcStateCookie, cookies[0].Name)
+					assert.Equal(t, "state-1", cookies[0].Value)
+					assert.True(t, cookies[0].HttpOnly)
+					assert.True(t, cookies[0].Secure)
+				}
+			}
+			mockOIDCService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOIDCCallback(t *testing.T) {
+	mockOIDCService := service.NewMockOIDCService(t)
+	controller := NewOIDCController(mockOIDCService, false)
+
+	tokens := &model.TokenPair{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}
+
+	tests := []struct {
+		name           string
+		query          string
+		cookie         string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "Success - tokens issued",
+			query:  "?code=code-1&state=state-1",
+			cookie: "state-1",
+			mockSetup: func() {
+				mockOIDCService.On("CompleteLogin", mock.Anything, "corp", "code-1", "state-1").
+					Return(tokens, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "State of another browser",
+			query:          "?code=code-1&state=state-1",
+			cookie:         "state-2",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing cookie",
+			query:          "?code=code-1&state=state-1",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Refused at the provider",
+			query:          "?error=access_denied&state=state-1",
+			cookie:         "state-1",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Expired state",
+			query:  "?code=code-1&state=state-1",
+			cookie: "state-1",
+			mockSetup: func() {
+				mockOIDCService.On("CompleteLogin", mock.Anything, "corp", "code-1", "state-1").
+					Return(nil, service.ErrInvalidToken).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Invalid id token",
+			query:  "?code=code-1&state=state-1",
+			cookie: "state-1",
+			mockSetup: func() {
+				mockOIDCService.On("CompleteLogin", mock.Anything, "corp", "code-1", "state-1").
+					Return(nil, errors.Join(service.ErrOIDCLoginFailed, errors.New("nonce mismatch"))).Once()
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Email not verified by the provider",
+			query:  "?code=code-1&state=state-1",
+			cookie: "state-1",
+			mockSetup: func() {
+				mockOIDCService.On("CompleteLogin", mock.Anything, "corp", "code-1", "state-1").
+					Return(nil, service.ErrOIDCEmailNotVerified).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("GET", "/auth/oidc/corp/callback"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"provider": "corp"})
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: tt.cookie})
+			}
+
+			rr := httptest.NewRecorder()
+			controller.Callback(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"refresh_token":"refresh"`)
+			}
+			mockOIDCService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type OrderController struct {
+	ordSrvc service.OrderService
+	usrSrvc service.UserService
+}
+
+func NewOrderController(serviceOrd service.OrderService, serviceUs service.UserService) *OrderController {
+	return &OrderController{
+		ordSrvc: serviceOrd,
+		usrSrvc: serviceUs,
+	}
+}
+
+func (c *OrderController) protectedRoutes() []route {
+	return []route{
+		{"/checkout", "POST", c.Checkout, buyerRoles, noAPIKey},
+		{"/orders", "GET", c.ListOrders, buyerRoles, model.ScopeOrdersRead},
+		{"/orders/{id}", "GET", c.GetOrder, anyRole, model.ScopeOrdersRead},
+		{"/orders/{id}/cancel", "POST", c.CancelOrder, anyRole, noAPIKey},
+		{"/orders/{id}/returns", "POST", c.RequestReturn, buyerRoles, noAPIKey},
+		{"/returns", "GET", c.ListReturns, anyRole, model.ScopeOrdersRead},
+		{"/returns/{id}/accept", "POST", c.AcceptReturn, sellerRoles, noAPIKey},
+		{"/returns/{id}/reject", "POST", c.RejectReturn, sellerRoles, noAPIKey},
+		{"/admin/orders/{id}/status", "POST", c.ChangeOrderStatus, adminRoles, noAPIKey},
+	}
+}
+
+func (c *OrderController) RegisterRoutes(router *mux.Router, authMiddleware mux.MiddlewareFunc) {
+	orderRouter := router.PathPrefix("").Subrouter()
+	orderRouter.Use(authMiddleware)
+
+	for _, rt := range c.protectedRoutes() {
+		orderRouter.Handle(rt.path, rt.guarded()).Methods(rt.method)
+	}
+}
+
+// Checkout orders the whole cart of the current user
+func (c *OrderController) Checkout(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.Checkout"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	order, err := c.ordSrvc.Checkout(ctx, curUser.ID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, order)
+}
+
+// ListOrders returns the order history of the current user, newest first
+func (c *OrderController) ListOrders(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ListOrders"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	orders, err := c.ordSrvc.ListOrders(ctx, model.OrderFilter{
+		UserID: curUser.ID,
+		Status: r.URL.Query().Get("status"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, orders)
+}
+
+func (c *OrderController) GetOrder(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.GetOrder"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	orderID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid order id")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	order, err := c.ordSrvc.GetOrder(ctx, orderID, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, order)
+}
+
+func (c *OrderController) ChangeOrderStatus(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ChangeOrderStatus"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	orderID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid order id")
+		return
+	}
+
+	var statusReq model.OrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&statusReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	order, err := c.ordSrvc.ChangeOrderStatus(ctx, orderID, statusReq, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// CancelOrder cancels an order that is not shipped yet and gives its stock back
+func (c *OrderController) CancelOrder(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.CancelOrder"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	orderID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid order id")
+		return
+	}
+
+	var cancelReq model.CancelOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&cancelReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	order, err := c.ordSrvc.CancelOrder(ctx, orderID, cancelReq, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// RequestReturn asks to return items of a delivered order
+func (c *OrderController) RequestReturn(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.RequestReturn"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	orderID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid order id")
+		return
+	}
+
+	var returnReq model.ReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&returnReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	ret, err := c.ordSrvc.RequestReturn(ctx, orderID, returnReq, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, ret)
+}
+
+// ListReturns returns the returns requested by the current user or waiting for them as a seller
+func (c *OrderController) ListReturns(w http.ResponseWriter, r *http.Request) {
+
+	const op = "controller.ListReturns"
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	returns, err := c.ordSrvc.ListReturns(ctx, model.ReturnFilter{
+		Status: r.URL.Query().Get("status"),
+		Limit:  limit,
+		Offset: offset,
+	}, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, returns)
+}
+
+func (c *OrderController) AcceptReturn(w http.ResponseWriter, r *http.Request) {
+	c.resolveReturn(w, r, "controller.AcceptReturn", c.ordSrvc.AcceptReturn)
+}
+
+func (c *OrderController) RejectReturn(w http.ResponseWriter, r *http.Request) {
+	c.resolveReturn(w, r, "controller.RejectReturn", c.ordSrvc.RejectReturn)
+}
+
+func (c *OrderController) resolveReturn(w http.ResponseWriter, r *http.Request, op string,
+	resolve func(context.Context, int64, model.ResolveReturnRequest, model.User) (*model.OrderReturn, error)) {
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			log.Println(fmt.Errorf("%s: %w", op, err))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Second)
+	defer cancel()
+
+	returnID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid return id")
+		return
+	}
+
+	// The note is optional, an empty body is fine
+	var resolveReq model.ResolveReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&resolveReq); err != nil && !errors.Is(err, io.EOF) {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	curUser, ok := currentUser(ctx, w, r, c.usrSrvc)
+	if !ok {
+		return
+	}
+
+	ret, err := resolve(ctx, returnID, resolveReq, *curUser)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, ret)
+}
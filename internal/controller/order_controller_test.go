@@ -0,0 +1,353 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+)
+
+func TestCheckout(t *testing.T) {
+	mockOrderService := service.NewMockOrderService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewOrderController(mockOrderService, mockUserService)
+
+	testCustomer := UserFactory{Role: "customer"}.Build()
+	productID := int64(3)
+	order := &model.Order{
+		ID:     10,
+		UserID: testCustomer.ID,
+		Status: model.OrderStatusPending,
+		Total:  4500,
+		Items: []model.OrderItem{
+			{ID: 1, ProductID: &productID, SellerID: 2, Title: "Lamp", Quantity: 3, UnitPrice: 1500, LineTotal: 4500},
+		},
+		CreatedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+	}
+	reservedUntil := order.CreatedAt.Add(15 * time.Minute)
+	order.ReservedUntil = &reservedUntil
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Success - order created",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testCustomer.Email).
+					Return(testCustomer, nil).Once()
+				mockOrderService.On("Checkout", mock.Anything, testCustomer.ID).Return(order, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Empty cart",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testCustomer.Email).
+					Return(testCustomer, nil).Once()
+				mockOrderService.On("Checkout", mock.Anything, testCustomer.ID).
+					Return(nil, service.ErrCartEmpty).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Item out of stock",
+			mockSetup: func() {
+				mockUserService.On("GetUserByEmail", mock.Anything, testCustomer.Email).
+					Return(testCustomer, nil).Once()
+				mockOrderService.On("Checkout", mock.Anything, testCustomer.ID).
+					Return(nil, fmt.Errorf("%w: Lamp, 2 left", service.ErrOutOfStock)).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/checkout", nil)
+			claims := jwt.MapClaims{"email": testCustomer.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.Checkout(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				assert.Contains(t, rr.Body.String(), `"status":"pending"`)
+				assert.Contains(t, rr.Body.String(), `"unit_price":1500`)
+				assert.Contains(t, rr.Body.String(), `"reserved_until":"2026-10-18T12:15:00Z"`)
+			}
+			mockOrderService.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChangeOrderStatus(t *testing.T) {
+	mockOrderService := service.NewMockOrderService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewOrderController(mockOrderService, mockUserService)
+
+	testAdmin := UserFactory{Role: "admin"}.Build()
+	mockUserService.On("GetUserByEmail", mock.Anything, testAdmin.Email).Return(testAdmin, nil)
+
+	shippedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - order shipped",
+			requestBody: `{"status": "shipped"}`,
+			mockSetup: func() {
+				mockOrderService.On("ChangeOrderStatus", mock.Anything, int64(10),
+					model.OrderStatusRequest{Status: model.OrderStatusShipped}, *testAdmin).
+					Return(&model.Order{ID: 10, Status: model.OrderStatusShipped, ShippedAt: &shippedAt}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Transition not allowed",
+			requestBody: `{"status": "paid"}`,
+			mockSetup: func() {
+				mockOrderService.On("ChangeOrderStatus", mock.Anything, int64(10), mock.Anything, *testAdmin).
+					Return(nil, fmt.Errorf("%w: shipped to paid", service.ErrInvalidOrderTransition)).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Reservation expired before the payment",
+			requestBody: `{"status": "paid"}`,
+			mockSetup: func() {
+				mockOrderService.On("ChangeOrderStatus", mock.Anything, int64(10),
+					model.OrderStatusRequest{Status: model.OrderStatusPaid}, *testAdmin).
+					Return(nil, service.ErrReservationExpired).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Unknown status",
+			requestBody: `{"status": "lost"}`,
+			mockSetup: func() {
+				mockOrderService.On("ChangeOrderStatus", mock.Anything, int64(10), mock.Anything, *testAdmin).
+					Return(nil, service.ErrInvalidOrderStatus).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Order not found",
+			requestBody: `{"status": "paid"}`,
+			mockSetup: func() {
+				mockOrderService.On("ChangeOrderStatus", mock.Anything, int64(10), mock.Anything, *testAdmin).
+					Return(nil, service.ErrOrderNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/admin/orders/10/status", strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": "10"})
+			claims := jwt.MapClaims{"email": testAdmin.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.ChangeOrderStatus(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"shipped_at":"2026-10-19T08:00:00Z"`)
+			}
+			if tt.name == "Transition not allowed" {
+				assert.Contains(t, rr.Body.String(), "shipped to paid")
+			}
+			mockOrderService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	mockOrderService := service.NewMockOrderService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewOrderController(mockOrderService, mockUserService)
+
+	testCustomer := UserFactory{Role: "customer"}.Build()
+	mockUserService.On("GetUserByEmail", mock.Anything, testCustomer.Email).Return(testCustomer, nil)
+
+	cancelledAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - order cancelled",
+			requestBody: `{"reason": "ordered by mistake"}`,
+			mockSetup: func() {
+				mockOrderService.On("CancelOrder", mock.Anything, int64(10),
+					model.CancelOrderRequest{Reason: "ordered by mistake"}, *testCustomer).
+					Return(&model.Order{
+						ID:           10,
+						UserID:       testCustomer.ID,
+						Status:       model.OrderStatusCancelled,
+						CancelReason: "ordered by mistake",
+						CancelledAt:  &cancelledAt,
+					}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Missing reason",
+			requestBody: `{}`,
+			mockSetup: func() {
+				mockOrderService.On("CancelOrder", mock.Anything, int64(10), mock.Anything, *testCustomer).
+					Return(nil, service.ErrInvalidReason).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Already shipped",
+			requestBody: `{"reason": "too late"}`,
+			mockSetup: func() {
+				mockOrderService.On("CancelOrder", mock.Anything, int64(10), mock.Anything, *testCustomer).
+					Return(nil, fmt.Errorf("%w: shipped orders cannot be cancelled",
+						service.ErrInvalidOrderTransition)).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Items already cancelled",
+			requestBody: `{"reason": "cannot ship"}`,
+			mockSetup: func() {
+				mockOrderService.On("CancelOrder", mock.Anything, int64(10), mock.Anything, *testCustomer).
+					Return(nil, service.ErrOrderItemsCancelled).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Invalid payload",
+			requestBody:    `{"reason":`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/orders/10/cancel", strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": "10"})
+			claims := jwt.MapClaims{"email": testCustomer.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			controller.CancelOrder(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"status":"cancelled"`)
+			}
+			mockOrderService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResolveReturn(t *testing.T) {
+	mockOrderService := service.NewMockOrderService(t)
+	mockUserService := service.NewMockUserService(t)
+	controller := NewOrderController(mockOrderService, mockUserService)
+
+	testSeller := UserFactory{Role: "seller"}.Build()
+	mockUserService.On("GetUserByEmail", mock.Anything, testSeller.Email).Return(testSeller, nil)
+
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:        "Success - return accepted without a note",
+			handler:     controller.AcceptReturn,
+			requestBody: ``,
+			mockSetup: func() {
+				mockOrderService.On("AcceptReturn", mock.Anything, int64(7), model.ResolveReturnRequest{}, *testSeller).
+					Return(&model.OrderReturn{ID: 7, SellerID: testSeller.ID, Status: model.ReturnStatusAccepted}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Success - return rejected",
+			handler:     controller.RejectReturn,
+			requestBody: `{"note": "item was used"}`,
+			mockSetup: func() {
+				mockOrderService.On("RejectReturn", mock.Anything, int64(7),
+					model.ResolveReturnRequest{Note: "item was used"}, *testSeller).
+					Return(&model.OrderReturn{ID: 7, SellerID: testSeller.ID, Status: model.ReturnStatusRejected}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Already resolved",
+			handler:     controller.AcceptReturn,
+			requestBody: `{}`,
+			mockSetup: func() {
+				mockOrderService.On("AcceptReturn", mock.Anything, int64(7), mock.Anything, *testSeller).
+					Return(nil, service.ErrReturnResolved).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "Return of another seller",
+			handler:     controller.RejectReturn,
+			requestBody: `{}`,
+			mockSetup: func() {
+				mockOrderService.On("RejectReturn", mock.Anything, int64(7), mock.Anything, *testSeller).
+					Return(nil, service.ErrReturnNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/returns/7/accept", strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": "7"})
+			claims := jwt.MapClaims{"email": testSeller.Email}
+			req = req.WithContext(context.WithValue(req.Context(), "userClaims", claims))
+
+			rr := httptest.NewRecorder()
+			tt.handler(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockOrderService.AssertExpectations(t)
+		})
+	}
+}
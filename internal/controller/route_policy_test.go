@@ -0,0 +1,321 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/auth"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/middleware"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+)
+
+// expectedPolicy is the reference access matrix, written independently from
+// protectedRoutes so that a change of the policy has to be made in both places
+var expectedPolicy = map[string][]string{
+	"POST /user/logout":      {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"GET /user/me":           {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"PUT /user/me":           {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"POST /user/me/password": {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"GET /user/me/export":    {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"DELETE /user/me":        {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+
+	"POST /user/api-keys":        {model.RoleSeller, model.RoleAdmin},
+	"GET /user/api-keys":         {model.RoleSeller, model.RoleAdmin},
+	"DELETE /user/api-keys/{id}": {model.RoleSeller, model.RoleAdmin},
+
+	"GET /products":         {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"GET /products/search":  {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"GET /products/{id}":    {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"POST /products":        {model.RoleSeller, model.RoleAdmin},
+	"PUT /products/{id}":    {model.RoleSeller, model.RoleAdmin},
+	"DELETE /products/{id}": {model.RoleSeller, model.RoleAdmin},
+
+	"GET /products/mine":                          {model.RoleSeller, model.RoleAdmin},
+	"POST /products/{id}/status":                  {model.RoleSeller, model.RoleAdmin},
+	"POST /products/{id}/restore":                 {model.RoleSeller, model.RoleAdmin},
+	"POST /products/{id}/images":                  {model.RoleSeller, model.RoleAdmin},
+	"PUT /products/{id}/images/order":             {model.RoleSeller, model.RoleAdmin},
+	"PUT /products/{id}/images/{image_id}":        {model.RoleSeller, model.RoleAdmin},
+	"DELETE /products/{id}/images/{image_id}":     {model.RoleSeller, model.RoleAdmin},
+	"POST /products/{id}/variants":                {model.RoleSeller, model.RoleAdmin},
+	"PUT /products/{id}/variants/{variant_id}":    {model.RoleSeller, model.RoleAdmin},
+	"DELETE /products/{id}/variants/{variant_id}": {model.RoleSeller, model.RoleAdmin},
+
+	"POST /products/cart/{id}": {model.RoleCustomer, model.RoleSeller},
+	"POST /products/buy/{id}":  {model.RoleCustomer, model.RoleSeller},
+
+	"GET /cart":               {model.RoleCustomer, model.RoleSeller},
+	"DELETE /cart":            {model.RoleCustomer, model.RoleSeller},
+	"POST /cart/items":        {model.RoleCustomer, model.RoleSeller},
+	"PUT /cart/items/{id}":    {model.RoleCustomer, model.RoleSeller},
+	"DELETE /cart/items/{id}": {model.RoleCustomer, model.RoleSeller},
+
+	"POST /checkout":   {model.RoleCustomer, model.RoleSeller},
+	"GET /orders":      {model.RoleCustomer, model.RoleSeller},
+	"GET /orders/{id}": {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+
+	"POST /orders/{id}/cancel":  {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"POST /orders/{id}/returns": {model.RoleCustomer, model.RoleSeller},
+	"GET /returns":              {model.RoleCustomer, model.RoleSeller, model.RoleAdmin},
+	"POST /returns/{id}/accept": {model.RoleSeller, model.RoleAdmin},
+	"POST /returns/{id}/reject": {model.RoleSeller, model.RoleAdmin},
+
+	"GET /admin/users":              {model.RoleAdmin},
+	"POST /admin/users/{id}/ban":    {model.RoleAdmin},
+	"POST /admin/users/{id}/unban":  {model.RoleAdmin},
+	"PUT /admin/users/{id}/role":    {model.RoleAdmin},
+	"POST /admin/users/{id}/unlock": {model.RoleAdmin},
+	"GET /admin/users/{id}/cart":    {model.RoleAdmin},
+
+	"GET /admin/products":              {model.RoleAdmin},
+	"DELETE /admin/products/{id}":      {model.RoleAdmin},
+	"POST /admin/products/{id}/hide":   {model.RoleAdmin},
+	"POST /admin/products/{id}/unhide": {model.RoleAdmin},
+
+	"POST /admin/categories":        {model.RoleAdmin},
+	"PUT /admin/categories/{id}":    {model.RoleAdmin},
+	"DELETE /admin/categories/{id}": {model.RoleAdmin},
+
+	"POST /admin/categories/{id}/attributes":                  {model.RoleAdmin},
+	"PUT /admin/categories/{id}/attributes/{attribute_id}":    {model.RoleAdmin},
+	"DELETE /admin/categories/{id}/attributes/{attribute_id}": {model.RoleAdmin},
+
+	"POST /admin/orders/{id}/status": {model.RoleAdmin},
+}
+
+// expectedScopes lists the routes an API key may call and the scope it needs,
+// every other protected route must refuse API keys
+var expectedScopes = map[string]string{
+	"GET /products":         model.ScopeProductsRead,
+	"GET /products/search":  model.ScopeProductsRead,
+	"GET /products/{id}":    model.ScopeProductsRead,
+	"POST /products":        model.ScopeProductsWrite,
+	"PUT /products/{id}":    model.ScopeProductsWrite,
+	"DELETE /products/{id}": model.ScopeProductsWrite,
+
+	"GET /products/mine":                          model.ScopeProductsRead,
+	"POST /products/{id}/status":                  model.ScopeProductsWrite,
+	"POST /products/{id}/restore":                 model.ScopeProductsWrite,
+	"POST /products/{id}/images":                  model.ScopeProductsWrite,
+	"PUT /products/{id}/images/order":             model.ScopeProductsWrite,
+	"PUT /products/{id}/images/{image_id}":        model.ScopeProductsWrite,
+	"DELETE /products/{id}/images/{image_id}":     model.ScopeProductsWrite,
+	"POST /products/{id}/variants":                model.ScopeProductsWrite,
+	"PUT /products/{id}/variants/{variant_id}":    model.ScopeProductsWrite,
+	"DELETE /products/{id}/variants/{variant_id}": model.ScopeProductsWrite,
+
+	"GET /orders":      model.ScopeOrdersRead,
+	"GET /orders/{id}": model.ScopeOrdersRead,
+	"GET /returns":     model.ScopeOrdersRead,
+}
+
+func newTestKeyStore(t *testing.T) *auth.KeyStore {
+	keys, err := auth.NewEphemeralKeyStore()
+	assert.NoError(t, err)
+	return keys
+}
+
+func signTestToken(t *testing.T, keys *auth.KeyStore, role string) string {
+	claims := jwt.MapClaims{
+		"email":   role + "@example.com",
+		"role":    role,
+		"user_id": 1,
+		"jti":     "jti-" + role,
+		"iss":     auth.Issuer,
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}
+	token, err := keys.Sign(claims)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestRoutePolicies(t *testing.T) {
+	keys := newTestKeyStore(t)
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	mockAdminService := service.NewMockAdminService(t)
+	mockAccountService := service.NewMockAccountService(t)
+	mockAPIKeyService := service.NewMockAPIKeyService(t)
+	mockCategoryService := service.NewMockCategoryService(t)
+	mockOrderService := service.NewMockOrderService(t)
+	router, routes := newTestRouter(keys, mockProductService, mockUserService, mockAdminService,
+		mockAccountService, mockAPIKeyService, mockCategoryService, mockOrderService)
+
+	// Handlers that pass the policy stop at the first service call
+	stop := errors.New("stop")
+	mockUserService.On("IsTokenRevoked", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(false, nil).Maybe()
+	mockUserService.On("GetUserByEmail", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockUserService.On("LogoutUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	mockProductService.On("ListProducts", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockProductService.On("SearchProducts", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockProductService.On("GetProductByID", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockAdminService.On("ListUsers", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockAdminService.On("GetUserCart", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockAdminService.On("UnlockUser", mock.Anything, mock.Anything).Return(stop).Maybe()
+	mockAdminService.On("ListProducts", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockAdminService.On("DeleteProduct", mock.Anything, mock.Anything).Return(stop).Maybe()
+	mockAdminService.On("SetProductHidden", mock.Anything, mock.Anything, mock.Anything).Return(stop).Maybe()
+	mockCategoryService.On("CreateCategory", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockCategoryService.On("UpdateCategory", mock.Anything, mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockCategoryService.On("DeleteCategory", mock.Anything, mock.Anything).Return(stop).Maybe()
+	mockCategoryService.On("CreateAttribute", mock.Anything, mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockOrderService.On("ChangeOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, stop).Maybe()
+
+	assert.Len(t, routes, len(expectedPolicy), "every protected route must have an expected policy")
+
+	for _, rt := range routes {
+		key := rt.method + " " + rt.path
+		allowed, ok := expectedPolicy[key]
+		if !assert.True(t, ok, "no expected policy for %s", key) {
+			continue
+		}
+
+		for _, role := range []string{model.RoleCustomer, model.RoleSeller, model.RoleAdmin, "unknown"} {
+			t.Run(key+" as "+role, func(t *testing.T) {
+				path := strings.ReplaceAll(rt.path, "{id}", "1")
+				req := httptest.NewRequest(rt.method, path, bytes.NewBufferString(`{}`))
+				req.Header.Set("Authorization", "Bearer "+signTestToken(t, keys, role))
+
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+
+				if contains(allowed, role) {
+					assert.NotEqual(t, http.StatusForbidden, rr.Code)
+					assert.NotEqual(t, http.StatusUnauthorized, rr.Code)
+				} else {
+					assert.Equal(t, http.StatusForbidden, rr.Code)
+				}
+			})
+		}
+	}
+}
+
+func TestRoutePoliciesRequireToken(t *testing.T) {
+	router, routes := newTestRouter(newTestKeyStore(t), service.NewMockProductService(t),
+		service.NewMockUserService(t), service.NewMockAdminService(t), service.NewMockAccountService(t),
+		service.NewMockAPIKeyService(t), service.NewMockCategoryService(t), service.NewMockOrderService(t))
+
+	// Tokens signed with a key that is not in the store must be refused as well
+	foreignToken := signTestToken(t, newTestKeyStore(t), model.RoleAdmin)
+
+	for _, rt := range routes {
+		path := strings.ReplaceAll(rt.path, "{id}", "1")
+
+		for _, authHeader := range []string{"", "Bearer " + foreignToken} {
+			req := httptest.NewRequest(rt.method, path, nil)
+			if authHeader != "" {
+				req.Header.Set("Authorization", authHeader)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rr.Code, rt.method+" "+rt.path)
+		}
+	}
+}
+
+func TestRouteScopes(t *testing.T) {
+	mockProductService := service.NewMockProductService(t)
+	mockUserService := service.NewMockUserService(t)
+	mockAPIKeyService := service.NewMockAPIKeyService(t)
+	router, routes := newTestRouter(newTestKeyStore(t), mockProductService, mockUserService,
+		service.NewMockAdminService(t), service.NewMockAccountService(t), mockAPIKeyService,
+		service.NewMockCategoryService(t), service.NewMockOrderService(t))
+
+	seller := &model.User{ID: 7, UserName: "seller", Email: "seller@example.com", Role: model.RoleSeller}
+	mockAPIKeyService.On("AuthenticateAPIKey", mock.Anything, "hb_read").
+		Return(seller, []string{model.ScopeProductsRead}, nil).Maybe()
+	mockAPIKeyService.On("AuthenticateAPIKey", mock.Anything, "hb_all").
+		Return(seller, model.APIKeyScopes, nil).Maybe()
+	mockAPIKeyService.On("AuthenticateAPIKey", mock.Anything, "hb_revoked").
+		Return(nil, nil, service.ErrInvalidAPIKey).Maybe()
+
+	// Handlers that pass the policy stop at the first service call
+	stop := errors.New("stop")
+	mockUserService.On("GetUserByEmail", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockProductService.On("ListProducts", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockProductService.On("SearchProducts", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+	mockProductService.On("GetProductByID", mock.Anything, mock.Anything).Return(nil, stop).Maybe()
+
+	for _, rt := range routes {
+		key := rt.method + " " + rt.path
+		needed, allowed := expectedScopes[key]
+		assert.Equal(t, needed, rt.scope, key)
+
+		for _, apiKey := range []string{"hb_read", "hb_all", "hb_revoked"} {
+			t.Run(key+" with "+apiKey, func(t *testing.T) {
+				path := strings.ReplaceAll(rt.path, "{id}", "1")
+				req := httptest.NewRequest(rt.method, path, bytes.NewBufferString(`{}`))
+				req.Header.Set(middleware.APIKeyHeader, apiKey)
+
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+
+				switch {
+				case apiKey == "hb_revoked":
+					assert.Equal(t, http.StatusUnauthorized, rr.Code)
+				case allowed && (apiKey == "hb_all" || needed == model.ScopeProductsRead):
+					assert.NotEqual(t, http.StatusForbidden, rr.Code)
+					assert.NotEqual(t, http.StatusUnauthorized, rr.Code)
+				default:
+					assert.Equal(t, http.StatusForbidden, rr.Code)
+				}
+			})
+		}
+	}
+}
+
+// newTestRouter wires every controller the way main does and returns all protected routes
+func newTestRouter(keys *auth.KeyStore, prSrvc service.ProductService, usrSrvc service.UserService,
+	admSrvc service.AdminService, accSrvc service.AccountService, keySrvc service.APIKeyService,
+	catSrvc service.CategoryService, ordSrvc service.OrderService) (*mux.Router, []route) {
+
+	marketplaceController := NewMarketplaceController(prSrvc, usrSrvc)
+	adminController := NewAdminController(admSrvc, usrSrvc)
+	accountController := NewAccountController(accSrvc, usrSrvc)
+	apiKeyController := NewAPIKeyController(keySrvc, usrSrvc)
+	categoryController := NewCategoryController(catSrvc)
+	orderController := NewOrderController(ordSrvc, usrSrvc)
+
+	router := mux.NewRouter()
+	authMiddleware := middleware.AuthMiddleware(keys, usrSrvc, keySrvc)
+	marketplaceController.RegisterRoutes(router, authMiddleware)
+	adminController.RegisterRoutes(router, authMiddleware)
+	accountController.RegisterRoutes(router, authMiddleware)
+	apiKeyController.RegisterRoutes(router, authMiddleware)
+	categoryController.RegisterRoutes(router, authMiddleware)
+	orderController.RegisterRoutes(router, authMiddleware)
+
+	var routes []route
+	routes = append(routes, marketplaceController.protectedRoutes()...)
+	routes = append(routes, adminController.protectedRoutes()...)
+	routes = append(routes, accountController.protectedRoutes()...)
+	routes = append(routes, apiKeyController.protectedRoutes()...)
+	routes = append(routes, categoryController.protectedRoutes()...)
+	routes = append(routes, orderController.protectedRoutes()...)
+
+	return router, routes
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
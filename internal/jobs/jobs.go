@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a task run every Interval in the background. A failed run is logged and
+// the job is tried again at the next tick.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start runs every job in its own goroutine until ctx is cancelled. Jobs run once right away,
+// so work that came due while the service was down does not wait a full interval.
+// Jobs must be safe to run from several instances of the service at once.
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		go loop(ctx, job)
+	}
+}
+
+func loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce keeps a panic in a job from taking the whole service down
+func runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("job %s failed: %v", job.Name, err)
+	}
+}
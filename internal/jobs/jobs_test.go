@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartRunsJobsUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var runs atomic.Int32
+	Start(ctx, Job{
+		Name:     "count",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return errors.New("failed runs are retried")
+		},
+	})
+
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	stopped := runs.Load()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+}
+
+func TestRunOnceRecoversPanics(t *testing.T) {
+	assert.NotPanics(t, func() {
+		runOnce(context.Background(), Job{Name: "panic", Run: func(ctx context.Context) error {
+			panic("boom")
+		}})
+	})
+}
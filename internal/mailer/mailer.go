@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as verification and password reset links
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+func New(driver, dir, from string) (Mailer, error) {
+	switch driver {
+	case "", "log":
+		return NewLogMailer(from), nil
+	case "file":
+		return NewFileMailer(dir, from)
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", driver)
+	}
+}
+
+type logMailer struct {
+	from string
+}
+
+// NewLogMailer returns a Mailer that only writes messages to the application log
+func NewLogMailer(from string) Mailer {
+	return &logMailer{from: from}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("Mail from %s to %s: %s\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer returns a Mailer that stores every message as an .eml file in dir
+func NewFileMailer(dir, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating mail directory: %w", err)
+	}
+
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	name := fmt.Sprintf("%d_%s.eml", now.UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		m.from, msg.To, msg.Subject, now.Format(time.RFC1123Z), msg.Body)
+
+	if err := os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("error writing mail file: %w", err)
+	}
+
+	return nil
+}
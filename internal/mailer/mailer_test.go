@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := New("file", dir, "noreply@example.com")
+	assert.NoError(t, err)
+
+	err = m.Send(context.Background(), Message{
+		To:      "ivan@example.com",
+		Subject: "Confirm your email",
+		Body:    "token: abc",
+	})
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.True(t, strings.HasSuffix(files[0], "_ivan_at_example.com.eml"))
+
+		content, err := os.ReadFile(files[0])
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "To: ivan@example.com")
+		assert.Contains(t, string(content), "Subject: Confirm your email")
+		assert.Contains(t, string(content), "token: abc")
+	}
+}
+
+func TestUnknownDriver(t *testing.T) {
+	_, err := New("smtp", "", "")
+	assert.Error(t, err)
+}
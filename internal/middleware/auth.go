@@ -3,56 +3,112 @@ package middleware
 import (
 	"context"
 	"net/http"
-	"os"
 	"strings"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/auth"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip middleware for these paths
-		if r.URL.Path == "/user" && r.Method == "POST" ||
-			r.URL.Path == "/user/login" && r.Method == "POST" {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		// Get token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return
-		}
-
-		// Check if it's a Bearer token
-		splitToken := strings.Split(authHeader, "Bearer ")
-		if len(splitToken) != 2 {
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
-			return
-		}
-
-		tokenString := splitToken[1]
-
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+// TokenChecker reports whether an access token was revoked before its expiry
+type TokenChecker interface {
+	IsTokenRevoked(ctx context.Context, jti string, userID, version int64) (bool, error)
+}
+
+// APIKeyHeader carries the API key of requests made by seller scripts instead of a bearer token
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyScopesClaim is only present in the claims of requests authenticated with an API key
+const apiKeyScopesClaim = "api_key_scopes"
+
+// APIKeyAuthenticator resolves an API key to its owner and the scopes granted to it
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*model.User, []string, error)
+}
+
+// AuthMiddleware accepts requests with a valid, unrevoked bearer token or an active API key
+// and puts the claims of the caller in the context
+func AuthMiddleware(verifier auth.Verifier, checker TokenChecker,
+	apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Skip middleware for these paths
+			if r.URL.Path == "/user" && r.Method == "POST" ||
+				r.URL.Path == "/user/login" && r.Method == "POST" ||
+				r.URL.Path == "/user/token/refresh" && r.Method == "POST" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if key := r.Header.Get(APIKeyHeader); key != "" {
+				user, scopes, err := apiKeys.AuthenticateAPIKey(r.Context(), key)
+				if err != nil {
+					http.Error(w, "Invalid or revoked API key", http.StatusUnauthorized)
+					return
+				}
 
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
+				// Same claims as in an access token, so handlers do not care how the caller authenticated
+				claims := jwt.MapClaims{
+					"user_id":         float64(user.ID),
+					"user_name":       user.UserName,
+					"email":           user.Email,
+					"role":            user.Role,
+					apiKeyScopesClaim: scopes,
+				}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "userClaims", claims)))
+				return
+			}
+
+			// Get token from Authorization header
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				return
+			}
 
-		// Add claims to context
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			// Check if it's a Bearer token
+			splitToken := strings.Split(authHeader, "Bearer ")
+			if len(splitToken) != 2 {
+				http.Error(w, "Invalid token format", http.StatusUnauthorized)
+				return
+			}
+
+			tokenString := splitToken[1]
+
+			// Check the signature against the published keys and validate the standard claims
+			claims, err := verifier.Verify(tokenString)
+			if err != nil {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			// Tokens without an ID cannot be revoked, so they are not accepted
+			jti, ok := claims["jti"].(string)
+			if !ok || jti == "" {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			// Tokens issued before token versions carry none, they count as version 0
+			userID, _ := claims["user_id"].(float64)
+			version, _ := claims["token_version"].(float64)
+
+			revoked, err := checker.IsTokenRevoked(r.Context(), jti, int64(userID), int64(version))
+			if err != nil {
+				http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+				return
+			}
+			if revoked {
+				http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+				return
+			}
+
+			// Add claims to context
 			ctx := context.WithValue(r.Context(), "userClaims", claims)
 			r = r.WithContext(ctx)
-		}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+)
+
+// RequireRole only lets through requests whose token carries one of the given roles.
+// It must run after AuthMiddleware, which puts the token claims into the context.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := utils.GetUserClaimsFromContext(r)
+			if !ok {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+				return
+			}
+
+			role, _ := claims["role"].(string)
+			if _, ok := allowed[role]; !ok {
+				utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope limits what API keys can do: a request authenticated with an API key is only
+// let through if the key was granted scope. An empty scope closes the route to API keys.
+// Requests with an access token are not affected.
+// It must run after AuthMiddleware, which puts the caller claims into the context.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := utils.GetUserClaimsFromContext(r)
+			if !ok {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user claims")
+				return
+			}
+
+			scopes, isAPIKey := claims[apiKeyScopesClaim].([]string)
+			if isAPIKey && (scope == "" || !slices.Contains(scopes, scope)) {
+				utils.RespondWithError(w, http.StatusForbidden, "API key is not allowed to access this resource")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
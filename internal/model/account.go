@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+type CredentialsInfo struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type Purchase struct {
+	ID        int64     `json:"id"`
+	ProductID *int64    `json:"product_id,omitempty"`
+	VariantID *int64    `json:"variant_id,omitempty"`
+	Title     string    `json:"title"`
+	SKU       string    `json:"sku,omitempty"`
+	Price     int64     `json:"price"`
+	CreatedAt time.Time `json:"purchased_at"`
+}
+
+// UserExport is everything the marketplace stores about a user
+type UserExport struct {
+	ExportedAt  time.Time       `json:"exported_at"`
+	Profile     User            `json:"profile"`
+	Credentials CredentialsInfo `json:"credentials"`
+	Products    []Product       `json:"products"`
+	Cart        []CartItem      `json:"cart"`
+	Purchases   []Purchase      `json:"purchases"`
+	// Orders hold their items and refunds, Returns are the returns the user asked for
+	Orders  []Order       `json:"orders"`
+	Returns []OrderReturn `json:"returns"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,20 @@
+package model
+
+type UserFilter struct {
+	Query  string
+	Role   string
+	Limit  int
+	Offset int
+}
+
+type AdminProductFilter struct {
+	Query      string
+	SellerID   int64
+	HiddenOnly bool
+	Limit      int
+	Offset     int
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,54 @@
+package model
+
+// Types of product attributes
+const (
+	AttributeString  = "string"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+	AttributeEnum    = "enum"
+)
+
+var AttributeTypes = []string{AttributeString, AttributeNumber, AttributeBoolean, AttributeEnum}
+
+// Operators of attribute filters
+const (
+	AttributeOpEq  = "="
+	AttributeOpNe  = "!="
+	AttributeOpGt  = ">"
+	AttributeOpGte = ">="
+	AttributeOpLt  = "<"
+	AttributeOpLte = "<="
+)
+
+// AttributeDefinition is an attribute that the products of a category and of its subcategories can have
+type AttributeDefinition struct {
+	ID         int64  `json:"id"`
+	CategoryID int64  `json:"category_id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Unit       string `json:"unit,omitempty"`
+	// Values are the allowed values of an enum
+	Values   []string `json:"values,omitempty"`
+	Required bool     `json:"required"`
+}
+
+type AttributeDefinitionRequest struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Unit     string   `json:"unit"`
+	Values   []string `json:"values"`
+	Required bool     `json:"required"`
+}
+
+// ProductAttributes maps attribute codes to their values: strings, float64 numbers or booleans
+type ProductAttributes map[string]interface{}
+
+// AttributeFilter compares an attribute of the products with a value, e.g. ram_gb >= 16.
+// Only = and != apply to strings and booleans.
+type AttributeFilter struct {
+	Code  string
+	Op    string
+	Value string
+}
@@ -0,0 +1,40 @@
+package model
+
+type TokenPair struct {
+	AccessToken  string `json:"TokenBearer"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type UpdateProfileRequest struct {
+	UserName string `json:"name"`
+	Email    string `json:"email"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+const (
+	RoleCustomer = "customer"
+	RoleSeller   = "seller"
+	RoleAdmin    = "admin"
+)
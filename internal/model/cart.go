@@ -0,0 +1,32 @@
+package model
+
+// CartItem is a line of the cart with the current price of the product or variant.
+// Available is its current stock, quantities are checked against it when they change
+// and again when the item is bought.
+type CartItem struct {
+	ProductID int64  `json:"product_id"`
+	VariantID *int64 `json:"variant_id,omitempty"`
+	Title     string `json:"title"`
+	SKU       string `json:"sku,omitempty"`
+	Price     int64  `json:"price"`
+	Quantity  int    `json:"quantity"`
+	Available int    `json:"available"`
+	LineTotal int64  `json:"line_total"`
+}
+
+type Cart struct {
+	Items         []CartItem `json:"items"`
+	TotalQuantity int        `json:"total_quantity"`
+	Total         int64      `json:"total"`
+}
+
+// CartItemRequest adds items to the cart. VariantID is required for products with variants.
+type CartItemRequest struct {
+	ProductID int64 `json:"product_id"`
+	VariantID int64 `json:"variant_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity"`
+}
@@ -0,0 +1,100 @@
+package model
+
+import "time"
+
+// Sort orders of the product catalog
+const (
+	ProductSortNewest    = "newest"
+	ProductSortPriceAsc  = "price_asc"
+	ProductSortPriceDesc = "price_desc"
+	ProductSortTitle     = "title"
+	// ProductSortRelevance orders search results by rank and is the default of a search
+	ProductSortRelevance = "relevance"
+)
+
+var (
+	ProductSorts       = []string{ProductSortNewest, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortTitle}
+	ProductSearchSorts = append([]string{ProductSortRelevance}, ProductSorts...)
+)
+
+// ProductFilter selects a page of the public catalog. Zero values do not filter.
+type ProductFilter struct {
+	// Query is the text of a search
+	Query         string
+	MinPrice      int64
+	MaxPrice      int64
+	SellerID      int64
+	CategoryID    int64
+	InStock       bool
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Attributes    []AttributeFilter
+	Sort          string
+	Limit         int
+	Cursor        string
+	// Facets asks for the facet counts of the filter along with the page
+	Facets bool
+}
+
+// ProductCursor is the position after the last product of a page, in the sort order it was read with
+type ProductCursor struct {
+	Sort      string    `json:"s"`
+	ID        int64     `json:"id"`
+	Price     int64     `json:"p,omitempty"`
+	Title     string    `json:"t,omitempty"`
+	Rank      float32   `json:"r,omitempty"`
+	CreatedAt time.Time `json:"c,omitzero"`
+}
+
+type ProductPage struct {
+	Items      []Product      `json:"items"`
+	NextCursor *string        `json:"next_cursor"`
+	Total      int64          `json:"total"`
+	Limit      int            `json:"limit"`
+	Facets     *ProductFacets `json:"facets,omitempty"`
+}
+
+// ProductSearchHit is a product found by a search. Highlights wrap the matched words
+// in <mark> tags, the rest of the text is HTML escaped.
+type ProductSearchHit struct {
+	Product
+	Rank               float32 `json:"rank"`
+	TitleHighlight     string  `json:"title_highlight"`
+	DescriptionSnippet string  `json:"description_snippet"`
+}
+
+type ProductSearchPage struct {
+	Items      []ProductSearchHit `json:"items"`
+	NextCursor *string            `json:"next_cursor"`
+	Total      int64              `json:"total"`
+	Limit      int                `json:"limit"`
+	Facets     *ProductFacets     `json:"facets,omitempty"`
+}
+
+// ProductFacets counts the products matching a filter by seller, category, price and attribute value.
+// Each facet ignores the filter on itself, so the other values of a facet stay selectable.
+type ProductFacets struct {
+	Sellers    []FacetCount                     `json:"sellers"`
+	Categories []FacetCount                     `json:"categories"`
+	Prices     []PriceBucket                    `json:"prices"`
+	Attributes map[string][]AttributeValueCount `json:"attributes"`
+}
+
+// FacetCount is the number of products of a seller or in a category
+type FacetCount struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucket is the number of products with Min <= price < Max. The last bucket has no Max.
+type PriceBucket struct {
+	Min   int64  `json:"min"`
+	Max   *int64 `json:"max"`
+	Count int64  `json:"count"`
+}
+
+type AttributeValueCount struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
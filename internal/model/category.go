@@ -0,0 +1,17 @@
+package model
+
+type Category struct {
+	ID       int64      `json:"id"`
+	ParentID *int64     `json:"parent_id"`
+	Name     string     `json:"name"`
+	Slug     string     `json:"slug"`
+	Children []Category `json:"children,omitempty"`
+}
+
+// CategoryRequest creates or updates a category. The slug is derived from the name when empty,
+// a nil parent makes a top level category.
+type CategoryRequest struct {
+	ParentID *int64 `json:"parent_id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+}
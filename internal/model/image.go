@@ -0,0 +1,30 @@
+package model
+
+// ProductImage is an image of a product gallery. The primary image is also
+// the product_image of the product.
+type ProductImage struct {
+	ID        int64  `json:"id"`
+	ProductID int64  `json:"product_id"`
+	URL       string `json:"url"`
+	AltText   string `json:"alt_text"`
+	Position  int    `json:"position"`
+	Primary   bool   `json:"primary"`
+}
+
+// UpdateImageRequest changes the fields that are set. An image cannot be made
+// not primary, another one has to be made primary instead.
+type UpdateImageRequest struct {
+	AltText *string `json:"alt_text"`
+	Primary bool    `json:"primary"`
+}
+
+type ReorderImagesRequest struct {
+	ImageIDs []int64 `json:"image_ids"`
+}
+
+// ImageUpload is an uploaded image file with its gallery settings
+type ImageUpload struct {
+	Data    []byte
+	AltText string
+	Primary bool
+}
@@ -0,0 +1,135 @@
+package model
+
+import "time"
+
+// A new order is pending until it is paid, then it is shipped and delivered.
+// It can be cancelled until it is shipped.
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+var OrderStatuses = []string{
+	OrderStatusPending, OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled,
+}
+
+// Order is an order of a customer. The timestamps tell when it entered each status.
+type Order struct {
+	ID        int64       `json:"id"`
+	UserID    int64       `json:"user_id"`
+	Status    string      `json:"status"`
+	Total     int64       `json:"total"`
+	Items     []OrderItem `json:"items"`
+	CreatedAt time.Time   `json:"created_at"`
+	// ReservedUntil is when a pending order releases the stock it holds and is cancelled unless paid
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	ShippedAt     *time.Time `json:"shipped_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty"`
+	CancelReason  string     `json:"cancel_reason,omitempty"`
+	// Refunds are the refunds owed for the order, when it was cancelled after its payment or
+	// some of its items were returned
+	Refunds []Refund `json:"refunds,omitempty"`
+}
+
+// OrderFilter selects the orders of a customer, newest first. An empty status selects all.
+type OrderFilter struct {
+	UserID int64
+	Status string
+	Limit  int
+	Offset int
+}
+
+// OrderStatusRequest moves an order to another status. Reason is required for cancelled only.
+type OrderStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+// OrderItem is a line of an order with the title, sku and price the product had when it was ordered.
+// The product and variant ids are unset once they are deleted for good.
+type OrderItem struct {
+	ID        int64  `json:"id"`
+	ProductID *int64 `json:"product_id"`
+	VariantID *int64 `json:"variant_id,omitempty"`
+	SellerID  int64  `json:"seller_id"`
+	Title     string `json:"title"`
+	SKU       string `json:"sku,omitempty"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"`
+	LineTotal int64  `json:"line_total"`
+	// ReturnedQuantity is how many of the items were returned
+	ReturnedQuantity int `json:"returned_quantity"`
+	// CancelledAt is set when the seller cancelled the line of an order shared with other sellers
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CancelReason string     `json:"cancel_reason,omitempty"`
+}
+
+// A return is requested by the customer and accepted or rejected by the seller of the item
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusAccepted  = "accepted"
+	ReturnStatusRejected  = "rejected"
+)
+
+var ReturnStatuses = []string{ReturnStatusRequested, ReturnStatusAccepted, ReturnStatusRejected}
+
+// OrderReturn is a request to return some of the items of one line of a delivered order
+type OrderReturn struct {
+	ID          int64      `json:"id"`
+	OrderID     int64      `json:"order_id"`
+	OrderItemID int64      `json:"order_item_id"`
+	SellerID    int64      `json:"seller_id"`
+	RequestedBy int64      `json:"requested_by"`
+	Title       string     `json:"title"`
+	Quantity    int        `json:"quantity"`
+	Reason      string     `json:"reason"`
+	Status      string     `json:"status"`
+	Note        string     `json:"note,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ReturnRequest struct {
+	OrderItemID int64  `json:"order_item_id"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason"`
+}
+
+// ResolveReturnRequest accepts or rejects a return, the note is shown to the customer
+type ResolveReturnRequest struct {
+	Note string `json:"note"`
+}
+
+// ReturnFilter selects the returns the user asked for or has to resolve as a seller, newest first.
+// A zero user selects the returns of everybody. An empty status selects all.
+type ReturnFilter struct {
+	UserID int64
+	Status string
+	Limit  int
+	Offset int
+}
+
+// RefundStatusPending is the status of a refund the payment side has not settled yet
+const RefundStatusPending = "pending"
+
+// Refund is an amount owed back to the customer for an order
+type Refund struct {
+	ID       int64  `json:"id"`
+	OrderID  int64  `json:"order_id"`
+	ReturnID *int64 `json:"return_id,omitempty"`
+	// OrderItemID is the line the returned items came from, a cancelled order is refunded as a whole
+	OrderItemID *int64    `json:"order_item_id,omitempty"`
+	Amount      int64     `json:"amount"`
+	Reason      string    `json:"reason"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
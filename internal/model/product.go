@@ -1,10 +1,15 @@
 package model
 
+import "time"
+
 type User struct {
-	ID       int64  `json:"id"`
-	UserName string `json:"name"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	ID              int64      `json:"id"`
+	UserName        string     `json:"name"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	BannedAt        *time.Time `json:"banned_at,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 type UserRegister struct {
@@ -27,21 +32,74 @@ type Product struct {
 	ProductDescription string `json:"product_description"`
 	ProductImage       string `json:"product_image"`
 	Price              int64  `json:"price"`
-	Amount             int    `json:"amount"`
+	// Amount is the stock on hand. Reserved is the part held by pending orders, the rest is Available.
+	Amount    int  `json:"amount"`
+	Reserved  int  `json:"reserved"`
+	Available int  `json:"available"`
+	Hidden    bool `json:"hidden,omitempty"`
+	// Status is only set in the views of the seller, the catalog shows published products only
+	Status     string     `json:"status,omitempty"`
+	PublishAt  *time.Time `json:"publish_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	CategoryID *int64     `json:"category_id"`
+	// Attributes hold the specifications of the product, following the schema of its category
+	Attributes ProductAttributes `json:"attributes,omitempty"`
+	CreatedAt  time.Time         `json:"created_at,omitzero"`
+	// Variants are only loaded for a single product. The price of a product with variants is
+	// the lowest one of its variants and the amount is their total stock.
+	Variants []ProductVariant `json:"variants,omitempty"`
+	// Images is the gallery of the product, in display order
+	Images []ProductImage `json:"images,omitempty"`
 }
 
 type CreateProductRequest struct {
-	Title              string `json:"title"`
-	ProductDescription string `json:"product_description"`
-	ProductImage       string `json:"product_image"`
-	Price              int64  `json:"price"`
-	Amount             int    `json:"amount"`
+	Title              string            `json:"title"`
+	ProductDescription string            `json:"product_description"`
+	ProductImage       string            `json:"product_image"`
+	Price              int64             `json:"price"`
+	Amount             int               `json:"amount"`
+	CategoryID         *int64            `json:"category_id"`
+	Attributes         ProductAttributes `json:"attributes"`
+	// Status is published when empty, a scheduled product needs a publish_at in the future
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
 }
 
+// UpdateProductRequest changes only the fields that are set. A category_id of 0 removes the category,
+// attributes replace all the attributes of the product. product_image makes the gallery image with
+// this url primary.
 type UpdateProductRequest struct {
-	Title              string `json:"title"`
-	ProductDescription string `json:"product_description"`
-	ProductImage       string `json:"product_image"`
-	Price              int64  `json:"price"`
-	Amount             int    `json:"amount"`
+	Title              string            `json:"title"`
+	ProductDescription string            `json:"product_description"`
+	ProductImage       string            `json:"product_image"`
+	Price              int64             `json:"price"`
+	Amount             int               `json:"amount"`
+	CategoryID         *int64            `json:"category_id"`
+	Attributes         ProductAttributes `json:"attributes"`
+}
+
+// Statuses of a product listing
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusScheduled = "scheduled"
+	ProductStatusPublished = "published"
+	ProductStatusArchived  = "archived"
+)
+
+var ProductStatuses = []string{ProductStatusDraft, ProductStatusScheduled, ProductStatusPublished, ProductStatusArchived}
+
+// ProductStatusRequest moves a product to another status. PublishAt is required for scheduled only.
+type ProductStatusRequest struct {
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// SellerProductFilter selects the products of a seller in any status. An empty status selects all.
+// Deleted selects the deleted products, which can still be restored, instead of the others.
+type SellerProductFilter struct {
+	SellerID int64
+	Status   string
+	Deleted  bool
+	Limit    int
+	Offset   int
 }
@@ -0,0 +1,22 @@
+package model
+
+// ProductVariant is a purchasable version of a product, such as a size or a colour,
+// with its own price and stock
+type ProductVariant struct {
+	ID        int64             `json:"id"`
+	ProductID int64             `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Options   map[string]string `json:"options"`
+	Price     int64             `json:"price"`
+	Amount    int               `json:"amount"`
+	Reserved  int               `json:"reserved"`
+	Available int               `json:"available"`
+}
+
+// VariantRequest creates a variant, or changes the fields that are set on an update
+type VariantRequest struct {
+	SKU     string            `json:"sku"`
+	Options map[string]string `json:"options"`
+	Price   *int64            `json:"price"`
+	Amount  *int              `json:"amount"`
+}
@@ -0,0 +1,292 @@
+// Package oidctest runs an in-process OpenID Connect provider, so the login flow
+// can be tested end to end without network access
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ClientID     = "test-client"
+	ClientSecret = "test-secret"
+)
+
+// User is the account signed in at the provider
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type grant struct {
+	challenge   string
+	nonce       string
+	redirectURI string
+	user        User
+}
+
+// IdP is a minimal provider supporting discovery, the authorization code flow with PKCE and key rotation.
+// The authorize endpoint signs in User right away instead of showing a login page.
+type IdP struct {
+	Server *httptest.Server
+
+	mu     sync.Mutex
+	user   User
+	keyID  string
+	key    *rsa.PrivateKey
+	keys   map[string]*rsa.PrivateKey
+	grants map[string]grant
+	// tamper may change the claims of the next id tokens
+	tamper func(claims jwt.MapClaims)
+}
+
+// NewIdP starts the provider, Close must be called once done
+func NewIdP(user User) (*IdP, error) {
+	idp := &IdP{user: user, keys: make(map[string]*rsa.PrivateKey), grants: make(map[string]grant)}
+	if err := idp.RotateKey(); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("GET /authorize", idp.authorize)
+	mux.HandleFunc("POST /token", idp.token)
+	mux.HandleFunc("GET /jwks", idp.jwks)
+	idp.Server = httptest.NewServer(mux)
+
+	return idp, nil
+}
+
+func (idp *IdP) Close() {
+	idp.Server.Close()
+}
+
+func (idp *IdP) Issuer() string {
+	return idp.Server.URL
+}
+
+// ProviderConfig returns the configuration of a client registered at the provider
+func (idp *IdP) ProviderConfig(name, redirectURL string) config.OIDCProviderConfig {
+	return config.OIDCProviderConfig{
+		Name:         name,
+		IssuerURL:    idp.Issuer(),
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// SetUser changes the account signed in by the next authorizations
+func (idp *IdP) SetUser(user User) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.user = user
+}
+
+// Tamper changes the claims of the id tokens issued from now on, nil restores valid tokens
+func (idp *IdP) Tamper(tamper func(claims jwt.MapClaims)) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.tamper = tamper
+}
+
+// RotateKey signs the next tokens with a new key. Old keys stay published.
+func (idp *IdP) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return fmt.Errorf("error generating key: %w", err)
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.keyID = fmt.Sprintf("key-%d", len(idp.keys)+1)
+	idp.key = key
+	idp.keys[idp.keyID] = key
+
+	return nil
+}
+
+// Authorize follows authURL like a browser whose user is signed in at the provider
+// and returns the code and the state passed back to the client
+func (idp *IdP) Authorize(authURL string) (code, state string, err error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("authorization failed with status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	if e := location.Query().Get("error"); e != "" {
+		return "", "", errors.New(e)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (idp *IdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                idp.Issuer(),
+		"authorization_endpoint":                idp.Issuer() + "/authorize",
+		"token_endpoint":                        idp.Issuer() + "/token",
+		"jwks_uri":                              idp.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (idp *IdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != ClientID || q.Get("response_type") != "code" || q.Get("redirect_uri") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+
+	idp.mu.Lock()
+	idp.grants[code] = grant{
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		redirectURI: q.Get("redirect_uri"),
+		user:        idp.user,
+	}
+	idp.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (idp *IdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != ClientID || secret != ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	idp.mu.Lock()
+	code := r.PostForm.Get("code")
+	g, found := idp.grants[code]
+	// Codes are single use
+	delete(idp.grants, code)
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !found || g.redirectURI != r.PostForm.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken, err := idp.idToken(g)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (idp *IdP) idToken(g grant) (string, error) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            idp.Issuer(),
+		"sub":            g.user.Subject,
+		"aud":            ClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          g.nonce,
+		"email":          g.user.Email,
+		"email_verified": g.user.EmailVerified,
+		"name":           g.user.Name,
+	}
+	if idp.tamper != nil {
+		idp.tamper(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.keyID
+
+	return token.SignedString(idp.key)
+}
+
+func (idp *IdP) jwks(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	keys := make([]map[string]string, 0, len(idp.keys))
+	for kid, key := range idp.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
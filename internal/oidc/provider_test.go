@@ -0,0 +1,122 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost/auth/oidc/test/callback"
+
+var testUser = oidctest.User{Subject: "sub-1", Email: "ivan@example.com", EmailVerified: true, Name: "Ivan"}
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.IdP) {
+	idp, err := oidctest.NewIdP(testUser)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(idp.Close)
+
+	p, err := NewProvider(context.Background(), idp.ProviderConfig("test", redirectURL), nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return p, idp
+}
+
+// login runs the flow up to the code exchange, as the service and the browser would
+func login(t *testing.T, p *Provider, idp *oidctest.IdP, nonce string) (*Identity, error) {
+	verifier, err := NewCodeVerifier()
+	assert.NoError(t, err)
+
+	code, state, err := idp.Authorize(p.AuthCodeURL("state-1", "nonce-1", verifier))
+	assert.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	return p.Exchange(context.Background(), code, verifier, nonce)
+}
+
+func TestProviderLogin(t *testing.T) {
+	p, idp := newTestProvider(t)
+
+	authURL, err := url.Parse(p.AuthCodeURL("state-1", "nonce-1", "verifier"))
+	assert.NoError(t, err)
+	assert.Equal(t, CodeChallenge("verifier"), authURL.Query().Get("code_challenge"))
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+	assert.Equal(t, redirectURL, authURL.Query().Get("redirect_uri"))
+
+	identity, err := login(t, p, idp, "nonce-1")
+	assert.NoError(t, err)
+	assert.Equal(t, &Identity{Subject: "sub-1", Email: "ivan@example.com", EmailVerified: true, Name: "Ivan"}, identity)
+
+	// Keys rotated at the provider are fetched again
+	assert.NoError(t, idp.RotateKey())
+	p.keysFetched = time.Time{}
+	_, err = login(t, p, idp, "nonce-1")
+	assert.NoError(t, err)
+}
+
+func TestProviderRejectsInvalidLogins(t *testing.T) {
+	p, idp := newTestProvider(t)
+
+	_, err := login(t, p, idp, "other-nonce")
+	assert.ErrorIs(t, err, ErrNonceMismatch)
+
+	// The code is bound to the challenge of the verifier it was requested with
+	code, _, err := idp.Authorize(p.AuthCodeURL("state-1", "nonce-1", "verifier"))
+	assert.NoError(t, err)
+	_, err = p.Exchange(context.Background(), code, "another-verifier", "nonce-1")
+	assert.Error(t, err)
+
+	tests := []struct {
+		name   string
+		tamper func(claims jwt.MapClaims)
+	}{
+		{"Other audience", func(claims jwt.MapClaims) { claims["aud"] = "other-client" }},
+		{"Other issuer", func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{"Expired", func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"Issued to another party", func(claims jwt.MapClaims) {
+			claims["aud"] = []string{oidctest.ClientID, "other-client"}
+			claims["azp"] = "other-client"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.Tamper(tt.tamper)
+			defer idp.Tamper(nil)
+
+			_, err := login(t, p, idp, "nonce-1")
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestProviderDiscoveryIssuerMismatch(t *testing.T) {
+	idp, err := oidctest.NewIdP(testUser)
+	assert.NoError(t, err)
+	defer idp.Close()
+
+	// The same server under another name must not be trusted as the configured issuer
+	cfg := idp.ProviderConfig("test", redirectURL)
+	cfg.IssuerURL = strings.Replace(idp.Issuer(), "127.0.0.1", "localhost", 1)
+	_, err = NewProvider(context.Background(), cfg, nil)
+	assert.ErrorIs(t, err, ErrIssuerMismatch)
+}
+
+func TestEmailVerifiedAsString(t *testing.T) {
+	p, idp := newTestProvider(t)
+	idp.Tamper(func(claims jwt.MapClaims) { claims["email_verified"] = "true" })
+
+	identity, err := login(t, p, idp, "nonce-1")
+	assert.NoError(t, err)
+	assert.True(t, identity.EmailVerified)
+}
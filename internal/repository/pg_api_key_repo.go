@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key model.APIKey, keyHash string) (*model.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID int64) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID int64) error
+	UseAPIKey(ctx context.Context, keyHash string) (*model.APIKey, error)
+}
+
+type postgresAPIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresAPIKeyRepository(pool *pgxpool.Pool) APIKeyRepository {
+	return &postgresAPIKeyRepository{pool: pool}
+}
+
+func (r *postgresAPIKeyRepository) CreateAPIKey(ctx context.Context, key model.APIKey,
+	keyHash string) (*model.APIKey, error) {
+
+	query := `INSERT INTO api_keys (user_id, name, key_prefix, key_hash, scopes, created_at)
+	VALUES ($1, $2, $3, $4, $5, NOW())
+	RETURNING id, created_at;`
+	err := r.pool.QueryRow(ctx, query, key.UserID, key.Name, key.Prefix, keyHash, key.Scopes).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert api key: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *postgresAPIKeyRepository) ListAPIKeys(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	query := `SELECT id, user_id, name, key_prefix, scopes, created_at, last_used_at, revoked_at
+	FROM api_keys
+	WHERE user_id = $1
+	ORDER BY id;`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []model.APIKey
+	for rows.Next() {
+		var k model.APIKey
+		err := rows.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key of the user. Keys of other users are reported as not found.
+func (r *postgresAPIKeyRepository) RevokeAPIKey(ctx context.Context, userID, keyID int64) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+	WHERE id = $1 AND user_id = $2;`
+	tag, err := r.pool.Exec(ctx, query, keyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// UseAPIKey looks up an active key by its hash and records that it was used
+func (r *postgresAPIKeyRepository) UseAPIKey(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	query := `UPDATE api_keys SET last_used_at = NOW()
+	WHERE key_hash = $1 AND revoked_at IS NULL
+	RETURNING id, user_id, name, key_prefix, scopes, created_at, last_used_at;`
+	var k model.APIKey
+	err := r.pool.QueryRow(ctx, query, keyHash).
+		Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedAt, &k.LastUsedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to use api key: %w", err)
+	}
+
+	return &k, nil
+}
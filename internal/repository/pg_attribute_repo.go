@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrAttributeNotFound      = errors.New("attribute not found")
+	ErrAttributeAlreadyExists = errors.New("category already has an attribute with this code")
+)
+
+type AttributeRepository interface {
+	ListAttributes(ctx context.Context, categoryID int64) ([]model.AttributeDefinition, error)
+	CreateAttribute(ctx context.Context, attr model.AttributeDefinition) (int64, error)
+	UpdateAttribute(ctx context.Context, attr model.AttributeDefinition) error
+	DeleteAttribute(ctx context.Context, categoryID, attributeID int64) error
+}
+
+type postgresAttributeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresAttributeRepository(pool *pgxpool.Pool) AttributeRepository {
+	return &postgresAttributeRepository{pool: pool}
+}
+
+// ListAttributes returns the schema of the category: its own attributes and those inherited from
+// its parents, ordered by code. An attribute redefined by a subcategory overrides the inherited one.
+func (r *postgresAttributeRepository) ListAttributes(ctx context.Context,
+	categoryID int64) ([]model.AttributeDefinition, error) {
+
+	query := `WITH RECURSIVE ancestors AS (
+		SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1
+		UNION ALL
+		SELECT c.id, c.parent_id, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+	)
+	SELECT DISTINCT ON (ca.code) ca.id, ca.category_id, ca.code, ca.name, ca.type, ca.unit, ca.enum_values, ca.required
+	FROM category_attributes ca
+	JOIN ancestors a ON a.id = ca.category_id
+	ORDER BY ca.code, a.depth;`
+	rows, err := r.pool.Query(ctx, query, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attributes: %w", err)
+	}
+	defer rows.Close()
+
+	var attrs []model.AttributeDefinition
+	for rows.Next() {
+		var a model.AttributeDefinition
+		err := rows.Scan(&a.ID, &a.CategoryID, &a.Code, &a.Name, &a.Type, &a.Unit, &a.Values, &a.Required)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attribute: %w", err)
+		}
+		attrs = append(attrs, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return attrs, nil
+}
+
+func (r *postgresAttributeRepository) CreateAttribute(ctx context.Context, attr model.AttributeDefinition) (int64, error) {
+	query := `INSERT INTO category_attributes
+	(category_id, code, name, type, unit, enum_values, required, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, COALESCE($6, '{}'), $7, NOW(), NOW())
+	RETURNING id;`
+	var id int64
+	err := r.pool.QueryRow(ctx, query,
+		attr.CategoryID, attr.Code, attr.Name, attr.Type, attr.Unit, attr.Values, attr.Required).Scan(&id)
+	if isUniqueViolation(err) {
+		return -1, ErrAttributeAlreadyExists
+	}
+	if isForeignKeyViolation(err) {
+		return -1, ErrCategoryNotFound
+	}
+	if err != nil {
+		return -1, fmt.Errorf("failed to create attribute: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateAttribute changes the definition only. Values already set on products are kept,
+// they are checked against the new definition when the product is next updated.
+func (r *postgresAttributeRepository) UpdateAttribute(ctx context.Context, attr model.AttributeDefinition) error {
+	query := `UPDATE category_attributes
+	SET code = $3, name = $4, type = $5, unit = $6, enum_values = COALESCE($7, '{}'), required = $8, updated_at = NOW()
+	WHERE id = $1 AND category_id = $2;`
+	tag, err := r.pool.Exec(ctx, query,
+		attr.ID, attr.CategoryID, attr.Code, attr.Name, attr.Type, attr.Unit, attr.Values, attr.Required)
+	if isUniqueViolation(err) {
+		return ErrAttributeAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update attribute: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAttributeNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresAttributeRepository) DeleteAttribute(ctx context.Context, categoryID, attributeID int64) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM category_attributes WHERE id = $1 AND category_id = $2;`,
+		attributeID, categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to delete attribute: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAttributeNotFound
+	}
+
+	return nil
+}
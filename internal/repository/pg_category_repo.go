@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// foreignKeyViolation is the postgres error code for a row still referenced by another table
+const foreignKeyViolation = "23503"
+
+var (
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrCategoryParentNotFound = errors.New("parent category not found")
+	ErrCategoryAlreadyExists  = errors.New("category with this slug already exists")
+	ErrCategoryNotEmpty       = errors.New("category has subcategories")
+)
+
+type CategoryRepository interface {
+	ListCategories(ctx context.Context) ([]model.Category, error)
+	GetCategory(ctx context.Context, id int64) (*model.Category, error)
+	CreateCategory(ctx context.Context, category model.Category) (int64, error)
+	UpdateCategory(ctx context.Context, category model.Category) error
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+type postgresCategoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresCategoryRepository(pool *pgxpool.Pool) CategoryRepository {
+	return &postgresCategoryRepository{pool: pool}
+}
+
+// ListCategories returns every category as a flat list ordered by name
+func (r *postgresCategoryRepository) ListCategories(ctx context.Context) ([]model.Category, error) {
+	query := `SELECT id, parent_id, name, slug FROM categories ORDER BY name, id;`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []model.Category
+	for rows.Next() {
+		var c model.Category
+		if err := rows.Scan(&c.ID, &c.ParentID, &c.Name, &c.Slug); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (r *postgresCategoryRepository) GetCategory(ctx context.Context, id int64) (*model.Category, error) {
+	query := `SELECT id, parent_id, name, slug FROM categories WHERE id = $1;`
+	var c model.Category
+	err := r.pool.QueryRow(ctx, query, id).Scan(&c.ID, &c.ParentID, &c.Name, &c.Slug)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	return &c, nil
+}
+
+func (r *postgresCategoryRepository) CreateCategory(ctx context.Context, category model.Category) (int64, error) {
+	query := `INSERT INTO categories (parent_id, name, slug, created_at, updated_at)
+	VALUES ($1, $2, $3, NOW(), NOW())
+	RETURNING id;`
+	var id int64
+	err := r.pool.QueryRow(ctx, query, category.ParentID, category.Name, category.Slug).Scan(&id)
+	if err != nil {
+		return -1, categoryError("failed to create category", err)
+	}
+
+	return id, nil
+}
+
+func (r *postgresCategoryRepository) UpdateCategory(ctx context.Context, category model.Category) error {
+	query := `UPDATE categories SET parent_id = $2, name = $3, slug = $4, updated_at = NOW() WHERE id = $1;`
+	tag, err := r.pool.Exec(ctx, query, category.ID, category.ParentID, category.Name, category.Slug)
+	if err != nil {
+		return categoryError("failed to update category", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+// DeleteCategory refuses to delete a category with subcategories.
+// Its products are kept without a category.
+func (r *postgresCategoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM categories WHERE id = $1;`, id)
+	if isForeignKeyViolation(err) {
+		return ErrCategoryNotEmpty
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+// categoryError maps constraint violations of an insert or an update to the repository errors
+func categoryError(msg string, err error) error {
+	if isUniqueViolation(err) {
+		return ErrCategoryAlreadyExists
+	}
+	if isForeignKeyViolation(err) {
+		return ErrCategoryParentNotFound
+	}
+
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
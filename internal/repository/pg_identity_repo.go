@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrIdentityNotFound      = errors.New("identity not found")
+	ErrIdentityAlreadyLinked = errors.New("identity is already linked to a user")
+)
+
+// IdentityRepository stores the accounts at external identity providers users sign in with
+type IdentityRepository interface {
+	GetUserIDByIdentity(ctx context.Context, provider, subject string) (int64, error)
+	LinkIdentity(ctx context.Context, identity model.UserIdentity) error
+}
+
+type postgresIdentityRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresIdentityRepository(pool *pgxpool.Pool) IdentityRepository {
+	return &postgresIdentityRepository{pool: pool}
+}
+
+func (r *postgresIdentityRepository) GetUserIDByIdentity(ctx context.Context, provider, subject string) (int64, error) {
+	query := `SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2;`
+	var userID int64
+	err := r.pool.QueryRow(ctx, query, provider, subject).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return -1, ErrIdentityNotFound
+	}
+	if err != nil {
+		return -1, fmt.Errorf("error performing get identity query: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *postgresIdentityRepository) LinkIdentity(ctx context.Context, identity model.UserIdentity) error {
+	query := `INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+	VALUES ($1, $2, $3, $4, NOW());`
+	_, err := r.pool.Exec(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email)
+	if isUniqueViolation(err) {
+		return ErrIdentityAlreadyLinked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return nil
+}
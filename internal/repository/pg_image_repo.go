@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrImageNotFound     = errors.New("image not found")
+	ErrInvalidImageOrder = errors.New("image order must list every image of the product once")
+	ErrGalleryFull       = errors.New("product gallery is full")
+)
+
+type ImageRepository interface {
+	ListImages(ctx context.Context, productIDs []int64) (map[int64][]model.ProductImage, error)
+	GetImage(ctx context.Context, productID, imageID int64) (*model.ProductImage, error)
+	AddImage(ctx context.Context, image model.ProductImage, limit int) (*model.ProductImage, error)
+	UpdateImage(ctx context.Context, image model.ProductImage) error
+	ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error
+	DeleteImage(ctx context.Context, productID, imageID int64) (*model.ProductImage, error)
+}
+
+type postgresImageRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresImageRepository(pool *pgxpool.Pool) ImageRepository {
+	return &postgresImageRepository{pool: pool}
+}
+
+// ListImages returns the galleries of the products in display order, keyed by product id
+func (r *postgresImageRepository) ListImages(ctx context.Context,
+	productIDs []int64) (map[int64][]model.ProductImage, error) {
+
+	query := `SELECT id, product_id, url, alt_text, position, is_primary
+	FROM product_images
+	WHERE product_id = ANY($1)
+	ORDER BY product_id, position, id;`
+	rows, err := r.pool.Query(ctx, query, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query images: %w", err)
+	}
+	defer rows.Close()
+
+	images := make(map[int64][]model.ProductImage)
+	for rows.Next() {
+		var img model.ProductImage
+		if err := rows.Scan(&img.ID, &img.ProductID, &img.URL, &img.AltText, &img.Position, &img.Primary); err != nil {
+			return nil, fmt.Errorf("failed to scan image: %w", err)
+		}
+		images[img.ProductID] = append(images[img.ProductID], img)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return images, nil
+}
+
+func (r *postgresImageRepository) GetImage(ctx context.Context, productID, imageID int64) (*model.ProductImage, error) {
+	query := `SELECT id, product_id, url, alt_text, position, is_primary
+	FROM product_images
+	WHERE id = $1 AND product_id = $2;`
+	var img model.ProductImage
+	err := r.pool.QueryRow(ctx, query, imageID, productID).
+		Scan(&img.ID, &img.ProductID, &img.URL, &img.AltText, &img.Position, &img.Primary)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrImageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	return &img, nil
+}
+
+// AddImage appends the image to the gallery unless it already holds limit images.
+// The first image of a product is always primary.
+func (r *postgresImageRepository) AddImage(ctx context.Context, image model.ProductImage,
+	limit int) (*model.ProductImage, error) {
+
+	err := r.inTx(ctx, image.ProductID, func(tx pgx.Tx) error {
+		var count int
+		err := tx.QueryRow(ctx,
+			`SELECT COUNT(*), COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1`,
+			image.ProductID).Scan(&count, &image.Position)
+		if err != nil {
+			return fmt.Errorf("failed to query gallery: %w", err)
+		}
+		if count >= limit {
+			return ErrGalleryFull
+		}
+
+		image.Primary = image.Primary || count == 0
+		if image.Primary {
+			if err := unsetPrimaryImage(ctx, tx, image.ProductID); err != nil {
+				return err
+			}
+		}
+
+		query := `INSERT INTO product_images (product_id, url, alt_text, position, is_primary, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id;`
+		err = tx.QueryRow(ctx, query,
+			image.ProductID, image.URL, image.AltText, image.Position, image.Primary).Scan(&image.ID)
+		if err != nil {
+			return fmt.Errorf("failed to add image: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// UpdateImage changes the alt text of the image and makes it primary if asked to
+func (r *postgresImageRepository) UpdateImage(ctx context.Context, image model.ProductImage) error {
+	return r.inTx(ctx, image.ProductID, func(tx pgx.Tx) error {
+		if image.Primary {
+			if err := unsetPrimaryImage(ctx, tx, image.ProductID); err != nil {
+				return err
+			}
+		}
+
+		query := `UPDATE product_images
+		SET alt_text = $3, is_primary = is_primary OR $4
+		WHERE id = $1 AND product_id = $2;`
+		tag, err := tx.Exec(ctx, query, image.ID, image.ProductID, image.AltText, image.Primary)
+		if err != nil {
+			return fmt.Errorf("failed to update image: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrImageNotFound
+		}
+		return nil
+	})
+}
+
+// ReorderImages sets the display order of the gallery, imageIDs must hold every image of the product
+func (r *postgresImageRepository) ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error {
+	return r.inTx(ctx, productID, func(tx pgx.Tx) error {
+		query := `UPDATE product_images i
+		SET position = o.position - 1
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, position)
+		WHERE i.id = o.id AND i.product_id = $1;`
+		tag, err := tx.Exec(ctx, query, productID, imageIDs)
+		if err != nil {
+			return fmt.Errorf("failed to reorder images: %w", err)
+		}
+
+		var count int64
+		err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM product_images WHERE product_id = $1`, productID).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to query gallery: %w", err)
+		}
+		// Unknown or repeated ids leave some images out, the transaction is rolled back
+		if tag.RowsAffected() != count || int64(len(imageIDs)) != count {
+			return ErrInvalidImageOrder
+		}
+		return nil
+	})
+}
+
+// DeleteImage removes the image from the gallery and returns it. When it was primary,
+// the first of the remaining images becomes primary.
+func (r *postgresImageRepository) DeleteImage(ctx context.Context, productID, imageID int64) (*model.ProductImage, error) {
+	var img model.ProductImage
+	err := r.inTx(ctx, productID, func(tx pgx.Tx) error {
+		query := `DELETE FROM product_images
+		WHERE id = $1 AND product_id = $2
+		RETURNING id, product_id, url, alt_text, position, is_primary;`
+		err := tx.QueryRow(ctx, query, imageID, productID).
+			Scan(&img.ID, &img.ProductID, &img.URL, &img.AltText, &img.Position, &img.Primary)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrImageNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete image: %w", err)
+		}
+
+		if img.Primary {
+			_, err = tx.Exec(ctx, `UPDATE product_images SET is_primary = TRUE
+			WHERE id = (SELECT id FROM product_images WHERE product_id = $1 ORDER BY position, id LIMIT 1)`,
+				productID)
+			if err != nil {
+				return fmt.Errorf("failed to choose primary image: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &img, nil
+}
+
+// inTx runs a change of the gallery with the product locked, so concurrent changes cannot
+// both pick the same position or primary image, and updates product_image in the same transaction
+func (r *postgresImageRepository) inTx(ctx context.Context, productID int64, fn func(tx pgx.Tx) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, productID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE products
+	SET product_image = COALESCE((SELECT url FROM product_images WHERE product_id = $1 AND is_primary), ''),
+	updated_at = NOW()
+	WHERE id = $1`, productID)
+	if err != nil {
+		return fmt.Errorf("failed to update product image: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func unsetPrimaryImage(ctx context.Context, tx pgx.Tx, productID int64) error {
+	_, err := tx.Exec(ctx, `UPDATE product_images SET is_primary = FALSE WHERE product_id = $1 AND is_primary`, productID)
+	if err != nil {
+		return fmt.Errorf("failed to unset primary image: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,970 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrInvalidOrderTransition = errors.New("order cannot move to this status")
+	ErrReturnNotFound         = errors.New("return not found")
+	ErrReturnResolved         = errors.New("return is already resolved")
+	ErrReturnQuantityExceeded = errors.New("more items than are left to return")
+	ErrReservationExpired     = errors.New("stock reservation of the order has expired")
+	ErrOrderItemsCancelled    = errors.New("order items are already cancelled")
+)
+
+// checkViolation is the postgres error code for a broken check constraint
+const checkViolation = "23514"
+
+// reservationExpiredReason is the cancel reason of the orders that were not paid in time
+const reservationExpiredReason = "stock reservation expired before the payment"
+
+// orderStatusTimestamps are the columns recording when an order entered a status. Orders are paid
+// with PayOrder, which takes their reserved stock, and cancelled with CancelOrder, which gives it back.
+var orderStatusTimestamps = map[string]string{
+	model.OrderStatusShipped:   "shipped_at",
+	model.OrderStatusDelivered: "delivered_at",
+}
+
+const orderColumns = `id, user_id, status, total, created_at, reserved_until, paid_at, shipped_at, delivered_at,
+	cancelled_at, COALESCE(cancel_reason, '')`
+
+const returnColumns = `r.id, r.order_id, r.order_item_id, i.seller_id, r.requested_by, i.title, r.quantity, r.reason,
+	r.status, COALESCE(r.note, ''), r.created_at, r.resolved_at`
+
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, userID int64, items []model.CartItem, reservation time.Duration) (*model.Order, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error)
+	GetOrdersByUser(ctx context.Context, userID int64) ([]model.Order, error)
+	GetOrder(ctx context.Context, id int64) (*model.Order, error)
+	SetOrderStatus(ctx context.Context, id int64, status string, from []string) error
+	PayOrder(ctx context.Context, id int64) error
+	CancelOrder(ctx context.Context, id int64, reason string, cancelledBy int64, from []string) error
+	CancelSellerItems(ctx context.Context, id, sellerID int64, reason string, from []string) error
+	ReleaseExpiredReservations(ctx context.Context) (int64, error)
+	CreateReturn(ctx context.Context, ret model.OrderReturn) (*model.OrderReturn, error)
+	ListReturns(ctx context.Context, filter model.ReturnFilter) ([]model.OrderReturn, error)
+	GetReturnsByUser(ctx context.Context, userID int64) ([]model.OrderReturn, error)
+	GetReturn(ctx context.Context, id int64) (*model.OrderReturn, error)
+	ResolveReturn(ctx context.Context, id int64, accept bool, note string) error
+}
+
+type postgresOrderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresOrderRepository(pool *pgxpool.Pool) OrderRepository {
+	return &postgresOrderRepository{pool: pool}
+}
+
+// CreateOrder reserves the stock of every item for the reservation period and records them as
+// a pending order, at the prices the products have now. It fails as a whole when any item cannot
+// be ordered.
+func (r *postgresOrderRepository) CreateOrder(ctx context.Context, userID int64, items []model.CartItem,
+	reservation time.Duration) (*model.Order, error) {
+
+	// Rows are locked in the order of their ids, so concurrent checkouts cannot deadlock
+	items = slices.Clone(items)
+	slices.SortFunc(items, func(a, b model.CartItem) int {
+		return cmp.Or(cmp.Compare(a.ProductID, b.ProductID), cmp.Compare(variantIDOf(a), variantIDOf(b)))
+	})
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	order := &model.Order{UserID: userID, Status: model.OrderStatusPending}
+	for _, item := range items {
+		line, err := reserveStock(ctx, tx, item)
+		if err != nil {
+			return nil, err
+		}
+		order.Items = append(order.Items, *line)
+		order.Total += line.LineTotal
+	}
+
+	err = tx.QueryRow(ctx, `INSERT INTO orders (user_id, status, total, reserved_until, created_at, updated_at)
+	VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second', NOW(), NOW())
+	RETURNING id, reserved_until, created_at;`, userID, order.Status, order.Total, reservation.Seconds()).
+		Scan(&order.ID, &order.ReservedUntil, &order.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	for i := range order.Items {
+		line := &order.Items[i]
+		err := tx.QueryRow(ctx, `INSERT INTO order_items
+		(order_id, product_id, variant_id, seller_id, title, sku, quantity, unit_price)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		RETURNING id;`,
+			order.ID, line.ProductID, line.VariantID, line.SellerID, line.Title, line.SKU, line.Quantity,
+			line.UnitPrice).Scan(&line.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create order item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return order, nil
+}
+
+// ListOrders returns the orders of the customer with their items, newest first
+func (r *postgresOrderRepository) ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	query := `SELECT ` + orderColumns + `
+	FROM orders
+	WHERE user_id = $1 AND ($2 = '' OR status = $2)
+	ORDER BY created_at DESC, id DESC
+	LIMIT $3 OFFSET $4;`
+
+	return r.queryOrders(ctx, query, filter.UserID, filter.Status, filter.Limit, filter.Offset)
+}
+
+// GetOrdersByUser returns every order of the customer with their items and refunds, newest first
+func (r *postgresOrderRepository) GetOrdersByUser(ctx context.Context, userID int64) ([]model.Order, error) {
+	query := `SELECT ` + orderColumns + `
+	FROM orders
+	WHERE user_id = $1
+	ORDER BY created_at DESC, id DESC;`
+
+	return r.queryOrders(ctx, query, userID)
+}
+
+// queryOrders reads the orders selected by the query and loads their items and refunds
+func (r *postgresOrderRepository) queryOrders(ctx context.Context, query string, args ...any) ([]model.Order, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []model.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := r.loadOrderItems(ctx, orders); err != nil {
+		return nil, err
+	}
+	if err := r.loadOrderRefunds(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *postgresOrderRepository) GetOrder(ctx context.Context, id int64) (*model.Order, error) {
+	order, err := scanOrder(r.pool.QueryRow(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = $1;`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []model.Order{*order}
+	if err := r.loadOrderItems(ctx, orders); err != nil {
+		return nil, err
+	}
+	if err := r.loadOrderRefunds(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return &orders[0], nil
+}
+
+// SetOrderStatus moves the order to the status if its current status is one of from, so concurrent
+// changes cannot make a transition that is not allowed, and records when it happened
+func (r *postgresOrderRepository) SetOrderStatus(ctx context.Context, id int64, status string, from []string) error {
+	column, ok := orderStatusTimestamps[status]
+	if !ok {
+		return ErrInvalidOrderTransition
+	}
+
+	query := fmt.Sprintf(`UPDATE orders SET status = $2, %s = NOW(), updated_at = NOW()
+	WHERE id = $1 AND status = ANY($3);`, column)
+	tag, err := r.pool.Exec(ctx, query, id, status, from)
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1);`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check order: %w", err)
+	}
+	if !exists {
+		return ErrOrderNotFound
+	}
+
+	return ErrInvalidOrderTransition
+}
+
+func scanOrder(row pgx.Row) (*model.Order, error) {
+	var o model.Order
+	err := row.Scan(&o.ID, &o.UserID, &o.Status, &o.Total, &o.CreatedAt, &o.ReservedUntil,
+		&o.PaidAt, &o.ShippedAt, &o.DeliveredAt, &o.CancelledAt, &o.CancelReason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan order: %w", err)
+	}
+
+	return &o, nil
+}
+
+// loadOrderItems reads the items of all the orders in one query
+func (r *postgresOrderRepository) loadOrderItems(ctx context.Context, orders []model.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Order, len(orders))
+	ids := make([]int64, 0, len(orders))
+	for i := range orders {
+		orders[i].Items = []model.OrderItem{}
+		byID[orders[i].ID] = &orders[i]
+		ids = append(ids, orders[i].ID)
+	}
+
+	query := `SELECT id, order_id, product_id, variant_id, seller_id, title, COALESCE(sku, ''), quantity, unit_price,
+	returned_quantity, cancelled_at, COALESCE(cancel_reason, '')
+	FROM order_items
+	WHERE order_id = ANY($1)
+	ORDER BY order_id, id;`
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.OrderItem
+		var orderID int64
+		err := rows.Scan(&item.ID, &orderID, &item.ProductID, &item.VariantID, &item.SellerID, &item.Title,
+			&item.SKU, &item.Quantity, &item.UnitPrice, &item.ReturnedQuantity, &item.CancelledAt, &item.CancelReason)
+		if err != nil {
+			return fmt.Errorf("failed to scan order item: %w", err)
+		}
+		item.LineTotal = item.UnitPrice * int64(item.Quantity)
+		order := byID[orderID]
+		order.Items = append(order.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	return nil
+}
+
+// loadOrderRefunds reads the refunds of all the orders in one query
+func (r *postgresOrderRepository) loadOrderRefunds(ctx context.Context, orders []model.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Order, len(orders))
+	ids := make([]int64, 0, len(orders))
+	for i := range orders {
+		byID[orders[i].ID] = &orders[i]
+		ids = append(ids, orders[i].ID)
+	}
+
+	query := `SELECT f.id, f.order_id, f.return_id, COALESCE(f.order_item_id, t.order_item_id), f.amount, f.reason, f.status, f.created_at
+	FROM refunds f
+	LEFT JOIN order_returns t ON t.id = f.return_id
+	WHERE f.order_id = ANY($1)
+	ORDER BY f.order_id, f.id;`
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query refunds: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var refund model.Refund
+		err := rows.Scan(&refund.ID, &refund.OrderID, &refund.ReturnID, &refund.OrderItemID, &refund.Amount,
+			&refund.Reason, &refund.Status, &refund.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to scan refund: %w", err)
+		}
+		order := byID[refund.OrderID]
+		order.Refunds = append(order.Refunds, refund)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	return nil
+}
+
+// PayOrder marks a pending order as paid and takes the stock it reserved out of the stock on hand.
+// An order whose reservation has expired cannot be paid anymore.
+func (r *postgresOrderRepository) PayOrder(ctx context.Context, id int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var reserved, expired bool
+	err = tx.QueryRow(ctx, `SELECT status, reserved_until IS NOT NULL, COALESCE(reserved_until <= NOW(), FALSE)
+	FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&status, &reserved, &expired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrOrderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+	if status != model.OrderStatusPending {
+		return ErrInvalidOrderTransition
+	}
+	if expired {
+		return ErrReservationExpired
+	}
+
+	// Orders placed before reservations took their stock at checkout already
+	if reserved {
+		lines, err := orderStockLines(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err := moveStock(ctx, tx, line, stockTake); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE orders
+	SET status = $2, paid_at = NOW(), reserved_until = NULL, updated_at = NOW()
+	WHERE id = $1`, id, model.OrderStatusPaid)
+	if err != nil {
+		return fmt.Errorf("failed to pay order: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CancelOrder cancels the order if its status is one of from and releases or gives back the stock of its items.
+// A paid order is refunded in full.
+func (r *postgresOrderRepository) CancelOrder(ctx context.Context, id int64, reason string, cancelledBy int64,
+	from []string) error {
+
+	return r.cancelOrder(ctx, id, reason, &cancelledBy, from)
+}
+
+// ReleaseExpiredReservations cancels the pending orders whose reservation has expired, so their stock
+// can be sold again. It returns how many orders were cancelled.
+func (r *postgresOrderRepository) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id FROM orders
+	WHERE status = $1 AND reserved_until <= NOW()
+	ORDER BY reserved_until`, model.OrderStatusPending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query expired orders: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan expired order: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	// Every order is cancelled on its own, an order paid in the meantime is skipped
+	var released int64
+	for _, id := range ids {
+		err := r.cancelOrder(ctx, id, reservationExpiredReason, nil, []string{model.OrderStatusPending})
+		if errors.Is(err, ErrInvalidOrderTransition) || errors.Is(err, ErrOrderNotFound) {
+			continue
+		}
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+// cancelOrder cancels the order if its status is one of from. A pending order releases the stock
+// it reserved, any other order gives the stock it took back. A paid order is refunded in full.
+// cancelledBy is nil when the order is cancelled by the system.
+func (r *postgresOrderRepository) cancelOrder(ctx context.Context, id int64, reason string, cancelledBy *int64,
+	from []string) error {
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var total int64
+	var paid, reserved bool
+	err = tx.QueryRow(ctx, `SELECT status, total, paid_at IS NOT NULL, reserved_until IS NOT NULL
+	FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&status, &total, &paid, &reserved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrOrderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+	if !slices.Contains(from, status) {
+		return ErrInvalidOrderTransition
+	}
+
+	lines, err := orderStockLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		move := stockGiveBack
+		if reserved {
+			move = stockRelease
+		}
+		if err := moveStock(ctx, tx, line, move); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE orders
+	SET status = $2, cancelled_at = NOW(), cancel_reason = $3, cancelled_by = $4, reserved_until = NULL,
+		updated_at = NOW()
+	WHERE id = $1`, id, model.OrderStatusCancelled, reason, cancelledBy)
+	if err != nil {
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	if paid {
+		_, err = tx.Exec(ctx, `INSERT INTO refunds (order_id, amount, reason, status, created_at)
+		VALUES ($1, $2, $3, $4, NOW())`, id, total, reason, model.RefundStatusPending)
+		if err != nil {
+			return fmt.Errorf("failed to record refund: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CancelSellerItems cancels the lines of the seller in an order shared with other sellers, if the order
+// status is one of from. Their stock is released or given back as cancelOrder does, the order total
+// drops by their price and each line of a paid order is refunded. Once no line is left the order is
+// cancelled as a whole.
+func (r *postgresOrderRepository) CancelSellerItems(ctx context.Context, id, sellerID int64, reason string,
+	from []string) error {
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var paid, reserved bool
+	err = tx.QueryRow(ctx, `SELECT status, paid_at IS NOT NULL, reserved_until IS NOT NULL
+	FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&status, &paid, &reserved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrOrderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+	if !slices.Contains(from, status) {
+		return ErrInvalidOrderTransition
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, product_id, variant_id, title, quantity - returned_quantity,
+		unit_price * (quantity - returned_quantity)
+	FROM order_items WHERE order_id = $1 AND seller_id = $2 AND cancelled_at IS NULL
+	ORDER BY product_id, variant_id NULLS FIRST`, id, sellerID)
+	if err != nil {
+		return fmt.Errorf("failed to query order items: %w", err)
+	}
+	var lines []stockLine
+	var itemIDs []int64
+	var amounts []int64
+	for rows.Next() {
+		var line stockLine
+		var itemID, amount int64
+		err := rows.Scan(&itemID, &line.productID, &line.variantID, &line.title, &line.quantity, &amount)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan order item: %w", err)
+		}
+		lines = append(lines, line)
+		itemIDs = append(itemIDs, itemID)
+		amounts = append(amounts, amount)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+	if len(lines) == 0 {
+		return ErrOrderItemsCancelled
+	}
+
+	var total int64
+	for i, line := range lines {
+		move := stockGiveBack
+		if reserved {
+			move = stockRelease
+		}
+		if line.productID != nil {
+			if err := moveStock(ctx, tx, line, move); err != nil {
+				return err
+			}
+		}
+		total += amounts[i]
+
+		if paid {
+			_, err = tx.Exec(ctx, `INSERT INTO refunds (order_id, order_item_id, amount, reason, status, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())`, id, itemIDs[i], amounts[i], reason, model.RefundStatusPending)
+			if err != nil {
+				return fmt.Errorf("failed to record refund: %w", err)
+			}
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE order_items SET cancelled_at = NOW(), cancel_reason = $2
+	WHERE id = ANY($1)`, itemIDs, reason)
+	if err != nil {
+		return fmt.Errorf("failed to cancel order items: %w", err)
+	}
+
+	// The total is what a later cancel of the whole order refunds, so the cancelled lines leave it
+	var left bool
+	err = tx.QueryRow(ctx, `UPDATE orders SET total = total - $2, updated_at = NOW()
+	WHERE id = $1
+	RETURNING EXISTS (SELECT 1 FROM order_items WHERE order_id = $1 AND cancelled_at IS NULL)`,
+		id, total).Scan(&left)
+	if err != nil {
+		return fmt.Errorf("failed to update order total: %w", err)
+	}
+	if !left {
+		_, err = tx.Exec(ctx, `UPDATE orders
+		SET status = $2, cancelled_at = NOW(), cancel_reason = $3, cancelled_by = $4, reserved_until = NULL,
+			updated_at = NOW()
+		WHERE id = $1`, id, model.OrderStatusCancelled, reason, sellerID)
+		if err != nil {
+			return fmt.Errorf("failed to cancel order: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// orderStockLines returns the items of the order that were neither returned, cancelled nor deleted since,
+// sorted by product and variant ids. moveStock locks the product before its variant, so the rows are locked
+// in the same order as CreateOrder locks them.
+func orderStockLines(ctx context.Context, tx pgx.Tx, orderID int64) ([]stockLine, error) {
+	rows, err := tx.Query(ctx, `SELECT product_id, variant_id, title, quantity - returned_quantity
+	FROM order_items WHERE order_id = $1 AND product_id IS NOT NULL AND cancelled_at IS NULL
+	ORDER BY product_id, variant_id NULLS FIRST`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []stockLine
+	for rows.Next() {
+		var line stockLine
+		if err := rows.Scan(&line.productID, &line.variantID, &line.title, &line.quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return lines, nil
+}
+
+// CreateReturn records a return request for a line of an order. Its quantity cannot exceed the items
+// of the line that were neither returned nor are waiting for another return, a cancelled line has none.
+func (r *postgresOrderRepository) CreateReturn(ctx context.Context, ret model.OrderReturn) (*model.OrderReturn, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The line is locked, so concurrent requests cannot ask for the same items
+	var left int
+	err = tx.QueryRow(ctx, `SELECT CASE WHEN i.cancelled_at IS NULL THEN i.quantity - i.returned_quantity - COALESCE((
+		SELECT SUM(quantity) FROM order_returns WHERE order_item_id = i.id AND status = 'requested'), 0) ELSE 0 END
+	FROM order_items i WHERE i.id = $1 AND i.order_id = $2 FOR UPDATE`, ret.OrderItemID, ret.OrderID).Scan(&left)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock order item: %w", err)
+	}
+	if ret.Quantity > left {
+		return nil, fmt.Errorf("%w: %d left", ErrReturnQuantityExceeded, left)
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `INSERT INTO order_returns
+	(order_id, order_item_id, requested_by, quantity, reason, status, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	RETURNING id`, ret.OrderID, ret.OrderItemID, ret.RequestedBy, ret.Quantity, ret.Reason,
+		model.ReturnStatusRequested).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create return: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return r.GetReturn(ctx, id)
+}
+
+// ListReturns returns the returns the user asked for or sold the items of, newest first
+func (r *postgresOrderRepository) ListReturns(ctx context.Context,
+	filter model.ReturnFilter) ([]model.OrderReturn, error) {
+
+	query := `SELECT ` + returnColumns + `
+	FROM order_returns r JOIN order_items i ON i.id = r.order_item_id
+	WHERE ($1 = 0 OR r.requested_by = $1 OR i.seller_id = $1) AND ($2 = '' OR r.status = $2)
+	ORDER BY r.created_at DESC, r.id DESC
+	LIMIT $3 OFFSET $4;`
+
+	return r.queryReturns(ctx, query, filter.UserID, filter.Status, filter.Limit, filter.Offset)
+}
+
+// GetReturnsByUser returns every return the customer asked for, newest first
+func (r *postgresOrderRepository) GetReturnsByUser(ctx context.Context, userID int64) ([]model.OrderReturn, error) {
+	query := `SELECT ` + returnColumns + `
+	FROM order_returns r JOIN order_items i ON i.id = r.order_item_id
+	WHERE r.requested_by = $1
+	ORDER BY r.created_at DESC, r.id DESC;`
+
+	return r.queryReturns(ctx, query, userID)
+}
+
+func (r *postgresOrderRepository) queryReturns(ctx context.Context, query string,
+	args ...any) ([]model.OrderReturn, error) {
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query returns: %w", err)
+	}
+	defer rows.Close()
+
+	var returns []model.OrderReturn
+	for rows.Next() {
+		ret, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, *ret)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return returns, nil
+}
+
+func (r *postgresOrderRepository) GetReturn(ctx context.Context, id int64) (*model.OrderReturn, error) {
+	ret, err := scanReturn(r.pool.QueryRow(ctx, `SELECT `+returnColumns+`
+	FROM order_returns r JOIN order_items i ON i.id = r.order_item_id
+	WHERE r.id = $1;`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrReturnNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ResolveReturn accepts or rejects a requested return. An accepted return gives the stock back
+// and is refunded at the price the items were ordered at.
+func (r *postgresOrderRepository) ResolveReturn(ctx context.Context, id int64, accept bool, note string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status, reason string
+	var orderID, itemID int64
+	var quantity int
+	err = tx.QueryRow(ctx, `SELECT status, order_id, order_item_id, quantity, reason
+	FROM order_returns WHERE id = $1 FOR UPDATE`, id).Scan(&status, &orderID, &itemID, &quantity, &reason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrReturnNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock return: %w", err)
+	}
+	if status != model.ReturnStatusRequested {
+		return ErrReturnResolved
+	}
+
+	status = model.ReturnStatusRejected
+	if accept {
+		status = model.ReturnStatusAccepted
+
+		line := stockLine{quantity: quantity}
+		var unitPrice int64
+		err = tx.QueryRow(ctx, `UPDATE order_items SET returned_quantity = returned_quantity + $2
+		WHERE id = $1
+		RETURNING product_id, variant_id, unit_price`, itemID, quantity).
+			Scan(&line.productID, &line.variantID, &unitPrice)
+		if err != nil {
+			return fmt.Errorf("failed to update returned quantity: %w", err)
+		}
+		if err := moveStock(ctx, tx, line, stockGiveBack); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO refunds (order_id, return_id, amount, reason, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())`,
+			orderID, id, unitPrice*int64(quantity), reason, model.RefundStatusPending)
+		if err != nil {
+			return fmt.Errorf("failed to record refund: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE order_returns SET status = $2, note = NULLIF($3, ''), resolved_at = NOW()
+	WHERE id = $1`, id, status, note)
+	if err != nil {
+		return fmt.Errorf("failed to resolve return: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func scanReturn(row pgx.Row) (*model.OrderReturn, error) {
+	var ret model.OrderReturn
+	err := row.Scan(&ret.ID, &ret.OrderID, &ret.OrderItemID, &ret.SellerID, &ret.RequestedBy, &ret.Title,
+		&ret.Quantity, &ret.Reason, &ret.Status, &ret.Note, &ret.CreatedAt, &ret.ResolvedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan return: %w", err)
+	}
+
+	return &ret, nil
+}
+
+// stockLine is a quantity of a product, or of its variant, of an order
+type stockLine struct {
+	productID *int64
+	variantID *int64
+	title     string
+	quantity  int
+}
+
+// stockMove is what happens to the stock of an order line after checkout reserved it
+type stockMove int
+
+const (
+	// stockTake takes the reserved items of a paid order out of the stock on hand
+	stockTake stockMove = iota
+	// stockRelease makes the reserved items of a cancelled pending order available again
+	stockRelease
+	// stockGiveBack puts the items of a cancelled or returned order that took its stock back on hand
+	stockGiveBack
+)
+
+// deltas returns what the move adds to the stock on hand and to the reserved stock
+func (m stockMove) deltas(quantity int) (amount, reserved int) {
+	switch m {
+	case stockTake:
+		return -quantity, -quantity
+	case stockRelease:
+		return 0, -quantity
+	default:
+		return quantity, 0
+	}
+}
+
+// moveStock applies the move to the stock on hand and the reserved stock of the variant, or of
+// the product when the line has no variant. A line whose variant was deleted since has no variant
+// either: its move is applied to the product if it has no variants left and skipped otherwise, as the
+// totals of a product with variants are kept by its variants. DeleteVariant refuses variants with
+// reservations, so only stock that was already taken can end up there. Taking more stock than is on
+// hand fails with ErrOutOfStock. The product is locked first, as reserveStock does, so checkouts cannot
+// deadlock with it.
+func moveStock(ctx context.Context, tx pgx.Tx, line stockLine, move stockMove) error {
+	if line.productID == nil || line.quantity <= 0 {
+		return nil
+	}
+	amount, reserved := move.deltas(line.quantity)
+
+	var locked int
+	err := tx.QueryRow(ctx, "SELECT 1 FROM products WHERE id = $1 FOR UPDATE", *line.productID).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	if line.variantID == nil {
+		_, err := tx.Exec(ctx, `UPDATE products
+		SET amount = amount + $2, reserved = GREATEST(reserved + $3, 0), updated_at = NOW()
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM product_variants WHERE product_id = $1)`,
+			*line.productID, amount, reserved)
+		if isCheckViolation(err) {
+			return &OutOfStockError{Item: line.title}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update product stock: %w", err)
+		}
+		return nil
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE product_variants
+	SET amount = amount + $3, reserved = GREATEST(reserved + $4, 0), updated_at = NOW()
+	WHERE id = $1 AND product_id = $2`,
+		*line.variantID, *line.productID, amount, reserved)
+	if isCheckViolation(err) {
+		return &OutOfStockError{Item: line.title}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update variant stock: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	return syncVariantTotals(ctx, tx, *line.productID)
+}
+
+func isCheckViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == checkViolation
+}
+
+// reserveStock locks the product, or its variant, and reserves the quantity of the item out of its
+// stock that is not reserved yet. It returns the order line for the item.
+func reserveStock(ctx context.Context, tx pgx.Tx, item model.CartItem) (*model.OrderItem, error) {
+	productID := item.ProductID
+	line := model.OrderItem{ProductID: &productID, VariantID: item.VariantID, Quantity: item.Quantity}
+
+	var amount int
+	var hasVariants bool
+	err := tx.QueryRow(ctx,
+		`SELECT seller_id, title, price, amount - reserved, EXISTS (SELECT 1 FROM product_variants WHERE product_id = $1)
+		FROM products WHERE id = $1 AND `+visibleProduct+` FOR UPDATE`,
+		productID).Scan(&line.SellerID, &line.Title, &line.UnitPrice, &amount, &hasVariants)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product amount: %w", err)
+	}
+
+	if item.VariantID == nil {
+		if hasVariants {
+			return nil, ErrVariantRequired
+		}
+		if amount < item.Quantity {
+			return nil, &OutOfStockError{Item: line.Title}
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE products SET reserved = reserved + $2, updated_at = NOW() WHERE id = $1",
+			productID, item.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve product stock: %w", err)
+		}
+	} else {
+		err = tx.QueryRow(ctx,
+			"SELECT sku, price, amount - reserved FROM product_variants WHERE id = $1 AND product_id = $2 FOR UPDATE",
+			*item.VariantID, productID).Scan(&line.SKU, &line.UnitPrice, &amount)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVariantNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query variant amount: %w", err)
+		}
+		if amount < item.Quantity {
+			return nil, &OutOfStockError{Item: line.Title + " " + line.SKU}
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE product_variants SET reserved = reserved + $2, updated_at = NOW() WHERE id = $1",
+			*item.VariantID, item.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve variant stock: %w", err)
+		}
+		if err := syncVariantTotals(ctx, tx, productID); err != nil {
+			return nil, err
+		}
+	}
+
+	line.LineTotal = line.UnitPrice * int64(line.Quantity)
+
+	return &line, nil
+}
+
+func variantIDOf(item model.CartItem) int64 {
+	if item.VariantID == nil {
+		return 0
+	}
+	return *item.VariantID
+}
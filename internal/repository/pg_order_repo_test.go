@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockMoveDeltas(t *testing.T) {
+	tests := []struct {
+		name     string
+		move     stockMove
+		amount   int
+		reserved int
+	}{
+		{name: "Paid order takes its reservation", move: stockTake, amount: -3, reserved: -3},
+		{name: "Cancelled pending order releases its reservation", move: stockRelease, amount: 0, reserved: -3},
+		{name: "Cancelled or returned order gives its stock back", move: stockGiveBack, amount: 3, reserved: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, reserved := tt.move.deltas(3)
+			assert.Equal(t, tt.amount, amount)
+			assert.Equal(t, tt.reserved, reserved)
+		})
+	}
+}
+
+func TestStockMoveLifecycle(t *testing.T) {
+	// amount and reserved of a product, moved the way the order queries move them
+	amount, reserved := 10, 0
+	apply := func(move stockMove, quantity int) {
+		da, dr := move.deltas(quantity)
+		amount, reserved = amount+da, max(reserved+dr, 0)
+	}
+
+	// Checkout reserves 3, paying takes them out of the stock on hand
+	reserved += 3
+	assert.Equal(t, 7, amount-reserved)
+	apply(stockTake, 3)
+	assert.Equal(t, []int{7, 0}, []int{amount, reserved})
+
+	// An expired reservation of 2 is released, nothing leaves the stock on hand
+	reserved += 2
+	apply(stockRelease, 2)
+	assert.Equal(t, []int{7, 0}, []int{amount, reserved})
+
+	// The paid order is cancelled, its 3 items are back on hand
+	apply(stockGiveBack, 3)
+	assert.Equal(t, []int{10, 0}, []int{amount, reserved})
+}
@@ -2,11 +2,19 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 )
@@ -15,16 +23,45 @@ const (
 	cartKey = "cart"
 )
 
+var (
+	ErrProductNotFound         = errors.New("product not found")
+	ErrInvalidStatusTransition = errors.New("product cannot move to this status")
+	ErrCartItemNotFound        = errors.New("item is not in the cart")
+	ErrAmountBelowReserved     = errors.New("amount cannot be less than the stock reserved by pending orders")
+)
+
+// visibleProduct is the condition for a product customers can see and buy
+const visibleProduct = "NOT is_hidden AND status = 'published' AND deleted_at IS NULL"
+
 type ProductRepository interface {
-	GetAllProducts(ctx context.Context) ([]model.Product, error)
+	ListProducts(ctx context.Context, filter model.ProductFilter, cursor *model.ProductCursor) ([]model.Product, error)
+	SearchProducts(ctx context.Context, filter model.ProductFilter,
+		cursor *model.ProductCursor) ([]model.ProductSearchHit, error)
+	CountProducts(ctx context.Context, filter model.ProductFilter) (int64, error)
+	CountFacets(ctx context.Context, filter model.ProductFilter, priceBounds []int64) (*model.ProductFacets, error)
 	GetProductByID(ctx context.Context, id int64) (*model.Product, error)
+	GetProductAttributes(ctx context.Context, id int64) (*int64, model.ProductAttributes, error)
 	CreateProduct(ctx context.Context, product model.Product) (int64, error)
 	UpdateProduct(ctx context.Context, query string, params []interface{}) (int64, error)
 	DeleteProduct(ctx context.Context, id int64) error
+	RestoreProduct(ctx context.Context, productID, sellerID int64) error
+	PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int64, []string, error)
 	CheckAccess(ctx context.Context, productID int64) (int64, error)
-	AddToCart(ctx context.Context, productID int64, userID int64) error
-	CheckCart(ctx context.Context, productID int64, userID int64) error
-	BuyProduct(ctx context.Context, productID int64, userID int64) error
+	GetCartQuantity(ctx context.Context, userID, productID, variantID int64) (int, error)
+	SetCartQuantity(ctx context.Context, userID, productID, variantID int64, quantity int, ttl time.Duration) error
+	AddCartQuantity(ctx context.Context, userID, productID, variantID int64, quantity int, ttl time.Duration) (int, error)
+	RemoveFromCart(ctx context.Context, userID, productID, variantID int64) error
+	BuyProduct(ctx context.Context, productID, variantID, userID int64) error
+	ListAllProducts(ctx context.Context, filter model.AdminProductFilter) ([]model.Product, error)
+	ListSellerProducts(ctx context.Context, filter model.SellerProductFilter) ([]model.Product, error)
+	SetProductStatus(ctx context.Context, productID int64, status string, publishAt *time.Time, from []string) error
+	PublishScheduled(ctx context.Context) (int64, error)
+	SetProductHidden(ctx context.Context, productID int64, hidden bool) error
+	GetCart(ctx context.Context, userID int64) ([]model.CartItem, error)
+	ClearCart(ctx context.Context, userID int64) error
+	TakeFromCart(ctx context.Context, userID int64, items []model.CartItem) error
+	GetProductsBySeller(ctx context.Context, sellerID int64) ([]model.Product, error)
+	GetPurchases(ctx context.Context, userID int64) ([]model.Purchase, error)
 }
 
 type postgresProductRepository struct {
@@ -33,20 +70,135 @@ type postgresProductRepository struct {
 }
 
 func NewPostgresProductRepository(pool *pgxpool.Pool, rc *redis.Client) ProductRepository {
-	return &postgresProductRepository{pool: pool}
+	return &postgresProductRepository{pool: pool, rc: rc}
 }
 
-func (r *postgresProductRepository) GetAllProducts(ctx context.Context) ([]model.Product, error) {
-	query := `SELECT id,
-	title, 
-	seller_name, 
-	seller_id, 
-	product_description, 
-	product_image, 
-	price, 
-	amount
-	FROM products;`
-	rows, err := r.pool.Query(ctx, query)
+// productSortOrders maps a catalog sort to its ORDER BY clause and the keyset condition
+// selecting the products after a cursor. The id makes every order total.
+var productSortOrders = map[string]struct {
+	orderBy string
+	after   string
+}{
+	model.ProductSortNewest:    {"created_at DESC, id DESC", "(created_at, id) < (?, ?)"},
+	model.ProductSortPriceAsc:  {"price ASC, id ASC", "(price, id) > (?, ?)"},
+	model.ProductSortPriceDesc: {"price DESC, id DESC", "(price, id) < (?, ?)"},
+	model.ProductSortTitle:     {"title ASC, id ASC", "(title, id) > (?, ?)"},
+}
+
+// searchQuery is the tsquery of a search text in both languages of the catalog
+const searchQuery = "(websearch_to_tsquery('russian', %[1]s) || websearch_to_tsquery('english', %[1]s))"
+
+// ts_headline wraps matched words in control characters that cannot clash with the
+// HTML escaping of the text, they are turned into <mark> tags by markHighlights
+const (
+	highlightStart  = "\x02"
+	highlightStop   = "\x03"
+	titleHeadline   = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", HighlightAll=true`
+	snippetHeadline = `StartSel="` + highlightStart + `", StopSel="` + highlightStop +
+		`", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+)
+
+// catalogFilter builds the conditions shared by a catalog page and its total count
+func catalogFilter(filter model.ProductFilter) *whereBuilder {
+	b := &whereBuilder{}
+	b.add(visibleProduct)
+	if filter.Query != "" {
+		// A title similar to the query matches as well, so a typo still finds the product
+		q := b.arg(filter.Query)
+		b.add(fmt.Sprintf("(search_vector @@ "+searchQuery+" OR %[1]s <%% title)", q))
+	}
+	b.addIf(filter.MinPrice > 0, "price >= ?", filter.MinPrice)
+	b.addIf(filter.MaxPrice > 0, "price <= ?", filter.MaxPrice)
+	b.addIf(filter.SellerID > 0, "seller_id = ?", filter.SellerID)
+	// A category includes the products of all its subcategories
+	b.addIf(filter.CategoryID > 0, `category_id IN (
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT id FROM tree)`, filter.CategoryID)
+	b.addIf(filter.InStock, "amount > reserved")
+	b.addIf(!filter.CreatedAfter.IsZero(), "created_at >= ?", filter.CreatedAfter)
+	b.addIf(!filter.CreatedBefore.IsZero(), "created_at < ?", filter.CreatedBefore)
+	for _, f := range filter.Attributes {
+		addAttributeFilter(b, f)
+	}
+
+	return b
+}
+
+// addAttributeFilter compares an attribute of the products with the filter value. Equality uses the
+// GIN index on attributes and matches the value as a string, and as a number or a boolean when it
+// parses as one. Comparisons apply to numbers only, a product without the attribute never matches.
+func addAttributeFilter(b *whereBuilder, f model.AttributeFilter) {
+	if f.Op != model.AttributeOpEq && f.Op != model.AttributeOpNe {
+		n, _ := strconv.ParseFloat(f.Value, 64)
+		path := fmt.Sprintf(`$.%s ? (@ %s $v)`, strconv.Quote(f.Code), f.Op)
+		b.add("jsonb_path_exists(attributes, ?::jsonpath, ?::jsonb)", path, attributeJSON("v", n))
+		return
+	}
+
+	values := []interface{}{f.Value}
+	if n, err := strconv.ParseFloat(f.Value, 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+		values = append(values, n)
+	}
+	if f.Value == "true" || f.Value == "false" {
+		values = append(values, f.Value == "true")
+	}
+
+	conds := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		conds[i] = "attributes @> ?::jsonb"
+		args[i] = attributeJSON(f.Code, v)
+	}
+	eq := "(" + strings.Join(conds, " OR ") + ")"
+	if f.Op == model.AttributeOpEq {
+		b.add(eq, args...)
+		return
+	}
+	b.add("(attributes -> ? IS NOT NULL AND NOT "+eq+")", append([]interface{}{f.Code}, args...)...)
+}
+
+// attributeJSON encodes a one key object, such as {"ram_gb": 16}
+func attributeJSON(key string, value interface{}) string {
+	data, _ := json.Marshal(map[string]interface{}{key: value})
+	return string(data)
+}
+
+// ListProducts returns up to filter.Limit visible products following the cursor, if any
+func (r *postgresProductRepository) ListProducts(ctx context.Context, filter model.ProductFilter,
+	cursor *model.ProductCursor) ([]model.Product, error) {
+
+	order, ok := productSortOrders[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown product sort %q", filter.Sort)
+	}
+
+	b := catalogFilter(filter)
+	if cursor != nil {
+		addCursor(b, filter.Sort, cursor)
+	}
+
+	query := fmt.Sprintf(`SELECT id,
+	title,
+	seller_name,
+	seller_id,
+	product_description,
+	product_image,
+	price,
+	amount,
+	reserved,
+	GREATEST(amount - reserved, 0),
+	category_id,
+	attributes,
+	created_at
+	FROM products
+	%s
+	ORDER BY %s
+	LIMIT %s;`, b.where(), order.orderBy, b.arg(filter.Limit))
+	rows, err := r.pool.Query(ctx, query, b.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query products: %w", err)
 	}
@@ -64,6 +216,11 @@ func (r *postgresProductRepository) GetAllProducts(ctx context.Context) ([]model
 			&p.ProductImage,
 			&p.Price,
 			&p.Amount,
+			&p.Reserved,
+			&p.Available,
+			&p.CategoryID,
+			&p.Attributes,
+			&p.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan product: %w", err)
@@ -78,6 +235,311 @@ func (r *postgresProductRepository) GetAllProducts(ctx context.Context) ([]model
 	return products, nil
 }
 
+// SearchProducts returns up to filter.Limit visible products matching filter.Query, following the
+// cursor if any, with their rank and highlighted text
+func (r *postgresProductRepository) SearchProducts(ctx context.Context, filter model.ProductFilter,
+	cursor *model.ProductCursor) ([]model.ProductSearchHit, error) {
+
+	b := catalogFilter(filter)
+	q := b.arg(filter.Query)
+	tsQuery := fmt.Sprintf(searchQuery, q)
+	rank := fmt.Sprintf("(ts_rank_cd(search_vector, %s) + word_similarity(%s, title))::real", tsQuery, q)
+
+	orderBy := "rank DESC, id DESC"
+	if filter.Sort != model.ProductSortRelevance {
+		order, ok := productSortOrders[filter.Sort]
+		if !ok {
+			return nil, fmt.Errorf("unknown product sort %q", filter.Sort)
+		}
+		orderBy = order.orderBy
+	}
+
+	if cursor != nil {
+		if filter.Sort == model.ProductSortRelevance {
+			b.add(fmt.Sprintf("(%s, id) < (?::real, ?)", rank), cursor.Rank, cursor.ID)
+		} else {
+			addCursor(b, filter.Sort, cursor)
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT id,
+	title,
+	seller_name,
+	seller_id,
+	product_description,
+	product_image,
+	price,
+	amount,
+	reserved,
+	GREATEST(amount - reserved, 0),
+	category_id,
+	attributes,
+	created_at,
+	%s AS rank,
+	ts_headline('russian', COALESCE(title, ''), %s, %s),
+	ts_headline('russian', COALESCE(product_description, ''), %s, %s)
+	FROM products
+	%s
+	ORDER BY %s
+	LIMIT %s;`, rank, tsQuery, b.arg(titleHeadline), tsQuery, b.arg(snippetHeadline),
+		b.where(), orderBy, b.arg(filter.Limit))
+	rows, err := r.pool.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []model.ProductSearchHit
+	for rows.Next() {
+		var h model.ProductSearchHit
+		err := rows.Scan(
+			&h.ID,
+			&h.Title,
+			&h.SellerName,
+			&h.SellerID,
+			&h.ProductDescription,
+			&h.ProductImage,
+			&h.Price,
+			&h.Amount,
+			&h.Reserved,
+			&h.Available,
+			&h.CategoryID,
+			&h.Attributes,
+			&h.CreatedAt,
+			&h.Rank,
+			&h.TitleHighlight,
+			&h.DescriptionSnippet,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		h.TitleHighlight = markHighlights(h.TitleHighlight)
+		h.DescriptionSnippet = markHighlights(h.DescriptionSnippet)
+		hits = append(hits, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return hits, nil
+}
+
+// addCursor selects the products after the cursor in the sort order
+func addCursor(b *whereBuilder, sort string, cursor *model.ProductCursor) {
+	after := productSortOrders[sort].after
+	switch sort {
+	case model.ProductSortNewest:
+		b.add(after, cursor.CreatedAt, cursor.ID)
+	case model.ProductSortPriceAsc, model.ProductSortPriceDesc:
+		b.add(after, cursor.Price, cursor.ID)
+	case model.ProductSortTitle:
+		b.add(after, cursor.Title, cursor.ID)
+	}
+}
+
+// markHighlights escapes a ts_headline result for HTML and turns its markers into <mark> tags
+func markHighlights(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, highlightStart, "<mark>")
+	return strings.ReplaceAll(s, highlightStop, "</mark>")
+}
+
+// CountProducts returns how many visible products match the filter, on all pages
+func (r *postgresProductRepository) CountProducts(ctx context.Context, filter model.ProductFilter) (int64, error) {
+	b := catalogFilter(filter)
+
+	var total int64
+	err := r.pool.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM products %s;`, b.where()), b.args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	return total, nil
+}
+
+// maxFacetValues bounds the sellers and the values of each attribute in the facets, the most common are kept
+const maxFacetValues = 20
+
+// CountFacets counts the visible products matching the filter by seller, category, price bucket and
+// attribute value. priceBounds are the ascending lower bounds of the price buckets after the first.
+func (r *postgresProductRepository) CountFacets(ctx context.Context, filter model.ProductFilter,
+	priceBounds []int64) (*model.ProductFacets, error) {
+
+	facets := &model.ProductFacets{Attributes: make(map[string][]model.AttributeValueCount)}
+
+	var err error
+	if facets.Sellers, err = r.countSellers(ctx, filter); err != nil {
+		return nil, err
+	}
+	if facets.Categories, err = r.countCategories(ctx, filter); err != nil {
+		return nil, err
+	}
+	if facets.Prices, err = r.countPrices(ctx, filter, priceBounds); err != nil {
+		return nil, err
+	}
+
+	// Attributes without a filter are counted together, each filtered one without its own filters
+	var codes []string
+	for _, f := range filter.Attributes {
+		if !slices.Contains(codes, f.Code) {
+			codes = append(codes, f.Code)
+		}
+	}
+	b := catalogFilter(filter)
+	b.addIf(len(codes) > 0, "a.key <> ALL(?)", codes)
+	if err := r.countAttributes(ctx, b, facets.Attributes); err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		f := filter
+		f.Attributes = slices.DeleteFunc(slices.Clone(filter.Attributes), func(af model.AttributeFilter) bool {
+			return af.Code == code
+		})
+		b := catalogFilter(f)
+		b.add("a.key = ?", code)
+		if err := r.countAttributes(ctx, b, facets.Attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	return facets, nil
+}
+
+func (r *postgresProductRepository) countSellers(ctx context.Context,
+	filter model.ProductFilter) ([]model.FacetCount, error) {
+
+	filter.SellerID = 0
+	b := catalogFilter(filter)
+	query := fmt.Sprintf(`SELECT seller_id, seller_name, COUNT(*)
+	FROM products
+	%s
+	GROUP BY seller_id, seller_name
+	ORDER BY COUNT(*) DESC, seller_id
+	LIMIT %s;`, b.where(), b.arg(maxFacetValues))
+
+	return r.queryFacetCounts(ctx, query, b.args)
+}
+
+// countCategories counts the products directly in each category, products without one are left out
+func (r *postgresProductRepository) countCategories(ctx context.Context,
+	filter model.ProductFilter) ([]model.FacetCount, error) {
+
+	filter.CategoryID = 0
+	b := catalogFilter(filter)
+	b.add("category_id IS NOT NULL")
+	query := fmt.Sprintf(`SELECT c.id, c.name, m.count
+	FROM (SELECT category_id, COUNT(*) AS count FROM products %s GROUP BY category_id) m
+	JOIN categories c ON c.id = m.category_id
+	ORDER BY c.name, c.id;`, b.where())
+
+	return r.queryFacetCounts(ctx, query, b.args)
+}
+
+func (r *postgresProductRepository) queryFacetCounts(ctx context.Context, query string,
+	args []interface{}) ([]model.FacetCount, error) {
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count facets: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []model.FacetCount{}
+	for rows.Next() {
+		var c model.FacetCount
+		if err := rows.Scan(&c.ID, &c.Name, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan facet: %w", err)
+		}
+		counts = append(counts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return counts, nil
+}
+
+// countPrices returns every bucket, empty ones included
+func (r *postgresProductRepository) countPrices(ctx context.Context, filter model.ProductFilter,
+	priceBounds []int64) ([]model.PriceBucket, error) {
+
+	filter.MinPrice, filter.MaxPrice = 0, 0
+	b := catalogFilter(filter)
+	// width_bucket puts prices below the first bound in bucket 0
+	query := fmt.Sprintf(`SELECT width_bucket(price, %s::bigint[]) AS bucket, COUNT(*)
+	FROM products
+	%s
+	GROUP BY bucket;`, b.arg(priceBounds), b.where())
+	rows, err := r.pool.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count prices: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := make([]model.PriceBucket, len(priceBounds)+1)
+	for i := range buckets {
+		if i > 0 {
+			buckets[i].Min = priceBounds[i-1]
+		}
+		if i < len(priceBounds) {
+			buckets[i].Max = &priceBounds[i]
+		}
+	}
+	for rows.Next() {
+		var bucket int
+		var count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan price bucket: %w", err)
+		}
+		if bucket >= 0 && bucket < len(buckets) {
+			buckets[bucket].Count = count
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// countAttributes adds the most common values of each attribute selected by b to counts
+func (r *postgresProductRepository) countAttributes(ctx context.Context, b *whereBuilder,
+	counts map[string][]model.AttributeValueCount) error {
+
+	query := fmt.Sprintf(`SELECT key, value, count FROM (
+		SELECT a.key, a.value, COUNT(*) AS count,
+		row_number() OVER (PARTITION BY a.key ORDER BY COUNT(*) DESC, a.value) AS n
+		FROM products, jsonb_each(attributes) a
+		%s
+		GROUP BY a.key, a.value
+	) v
+	WHERE n <= %s
+	ORDER BY key, n;`, b.where(), b.arg(maxFacetValues))
+	rows, err := r.pool.Query(ctx, query, b.args...)
+	if err != nil {
+		return fmt.Errorf("failed to count attributes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var c model.AttributeValueCount
+		if err := rows.Scan(&key, &c.Value, &c.Count); err != nil {
+			return fmt.Errorf("failed to scan attribute value: %w", err)
+		}
+		counts[key] = append(counts[key], c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	return nil
+}
+
 func (r *postgresProductRepository) GetProductByID(ctx context.Context, id int64) (*model.Product, error) {
 	query := `SELECT id,
 	title, 
@@ -86,9 +548,13 @@ func (r *postgresProductRepository) GetProductByID(ctx context.Context, id int64
 	product_description, 
 	product_image, 
 	price, 
-	amount
+	amount,
+	reserved,
+	GREATEST(amount - reserved, 0),
+	category_id,
+	attributes
 	FROM products
-	WHERE id = $1;`
+	WHERE id = $1 AND ` + visibleProduct + `;`
 	row := r.pool.QueryRow(ctx, query, id)
 
 	var p model.Product
@@ -101,8 +567,15 @@ func (r *postgresProductRepository) GetProductByID(ctx context.Context, id int64
 		&p.ProductImage,
 		&p.Price,
 		&p.Amount,
+		&p.Reserved,
+		&p.Available,
+		&p.CategoryID,
+		&p.Attributes,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
@@ -110,13 +583,39 @@ func (r *postgresProductRepository) GetProductByID(ctx context.Context, id int64
 	return &p, nil
 }
 
+// GetProductAttributes returns the category and the attributes of the product, hidden or not
+func (r *postgresProductRepository) GetProductAttributes(ctx context.Context,
+	id int64) (*int64, model.ProductAttributes, error) {
+
+	var categoryID *int64
+	var attrs model.ProductAttributes
+	err := r.pool.QueryRow(ctx, `SELECT category_id, attributes FROM products WHERE id = $1 AND deleted_at IS NULL;`,
+		id).
+		Scan(&categoryID, &attrs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get product attributes: %w", err)
+	}
+
+	return categoryID, attrs, nil
+}
+
 func (r *postgresProductRepository) CreateProduct(ctx context.Context, product model.Product) (int64, error) {
+	// An image given with the product starts its gallery
 	query := `
-		INSERT INTO products 
-		(title, seller_name, seller_id, product_image, 
-		product_description, price, amount, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW()) 
-		RETURNING id;
+		WITH created AS (
+			INSERT INTO products 
+			(title, seller_name, seller_id, product_image, 
+			product_description, price, amount, category_id, attributes, status, publish_at, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW()) 
+			RETURNING id, product_image
+		), gallery AS (
+			INSERT INTO product_images (product_id, url, position, is_primary)
+			SELECT id, product_image, 0, TRUE FROM created WHERE product_image <> ''
+		)
+		SELECT id FROM created;
 	`
 	row := r.pool.QueryRow(
 		ctx,
@@ -128,6 +627,10 @@ func (r *postgresProductRepository) CreateProduct(ctx context.Context, product m
 		product.ProductDescription,
 		product.Price,
 		product.Amount,
+		product.CategoryID,
+		product.Attributes,
+		product.Status,
+		product.PublishAt,
 	)
 
 	var createdID int64
@@ -156,6 +659,13 @@ func (r *postgresProductRepository) UpdateProduct(ctx context.Context,
 		&updatedID,
 	)
 
+	// The product was deleted since its access was checked
+	if errors.Is(err, pgx.ErrNoRows) {
+		return -1, ErrProductNotFound
+	}
+	if isCheckViolation(err) {
+		return -1, ErrAmountBelowReserved
+	}
 	if err != nil {
 		return -1, fmt.Errorf("failed to update product: %w", err)
 	}
@@ -163,39 +673,148 @@ func (r *postgresProductRepository) UpdateProduct(ctx context.Context,
 	return updatedID, nil
 }
 
+// DeleteProduct only marks the product deleted, it can be restored until PurgeDeletedProducts removes it
 func (r *postgresProductRepository) DeleteProduct(ctx context.Context, id int64) error {
-	query := `DELETE FROM products WHERE id = $1;`
-	_, err := r.pool.Exec(ctx, query, id)
+	query := `UPDATE products SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL;`
+	tag, err := r.pool.Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return ErrProductNotFound
+	}
 	return nil
 }
 
-func (r *postgresProductRepository) AddToCart(ctx context.Context, productID int64, userID int64) error {
-	err := r.rc.Set(ctx, fmt.Sprintf("%s_%d_%d", cartKey, userID, productID), "1", time.Hour)
-	if err.Err() != nil {
-		return fmt.Errorf("error setting redis key: %w", err.Err())
+// RestoreProduct brings back a deleted product of the seller, or of any seller when sellerID is 0.
+// The products of a deleted account stay deleted.
+func (r *postgresProductRepository) RestoreProduct(ctx context.Context, productID, sellerID int64) error {
+	query := `UPDATE products p SET deleted_at = NULL, updated_at = NOW()
+	WHERE p.id = $1 AND p.deleted_at IS NOT NULL AND ($2 = 0 OR p.seller_id = $2)
+	AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = p.seller_id AND u.deleted_at IS NOT NULL);`
+	tag, err := r.pool.Exec(ctx, query, productID, sellerID)
+	if err != nil {
+		return fmt.Errorf("failed to restore product: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedProducts removes the products deleted longer than retention ago for good, with their
+// variants and images. It returns how many were removed and the URLs of their images, to remove
+// from the storage. Purchases keep their title and price.
+func (r *postgresProductRepository) PurgeDeletedProducts(ctx context.Context,
+	retention time.Duration) (int64, []string, error) {
+
+	query := `WITH purged AS (
+		DELETE FROM products WHERE deleted_at < NOW() - make_interval(secs => $1) RETURNING id
+	)
+	SELECT (SELECT COUNT(*) FROM purged),
+	COALESCE((SELECT array_agg(i.url) FROM product_images i JOIN purged p ON p.id = i.product_id), '{}');`
+	var purged int64
+	var urls []string
+	err := r.pool.QueryRow(ctx, query, retention.Seconds()).Scan(&purged, &urls)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to purge deleted products: %w", err)
+	}
+
+	return purged, urls, nil
+}
+
+// userCartKey is the redis hash holding the cart of the user, its fields are given by cartField
+func userCartKey(userID int64) string {
+	return fmt.Sprintf("%s_%d", cartKey, userID)
+}
+
+// cartField is the field of a product in the cart hash, or of one of its variants when variantID is set.
+// It holds the quantity.
+func cartField(productID, variantID int64) string {
+	if variantID == 0 {
+		return strconv.FormatInt(productID, 10)
+	}
+	return fmt.Sprintf("%d_%d", productID, variantID)
+}
+
+// GetCartQuantity returns how many items of the product or variant are in the cart, 0 when none
+func (r *postgresProductRepository) GetCartQuantity(ctx context.Context, userID, productID, variantID int64) (int, error) {
+	quantity, err := r.rc.HGet(ctx, userCartKey(userID), cartField(productID, variantID)).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting cart item: %w", err)
+	}
+
+	return quantity, nil
+}
+
+// SetCartQuantity sets the quantity of the product or variant in the cart. Every change keeps the
+// whole cart for ttl more.
+func (r *postgresProductRepository) SetCartQuantity(ctx context.Context, userID, productID, variantID int64,
+	quantity int, ttl time.Duration) error {
+
+	key := userCartKey(userID)
+	_, err := r.rc.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, cartField(productID, variantID), quantity)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error setting cart item: %w", err)
 	}
 
 	return nil
 }
 
-func (r *postgresProductRepository) CheckCart(ctx context.Context, productID int64, userID int64) error {
-	err := r.rc.Get(ctx, fmt.Sprintf("%s_%d_%d", cartKey, userID, productID))
-	if err.Err() != nil {
-		return fmt.Errorf("error getting redis key: %w", err.Err())
+// AddCartQuantity adds quantity, which may be negative, to the product or variant in the cart in one step
+// and returns the new quantity. An item left with nothing is removed. Every change keeps the whole cart
+// for ttl more.
+func (r *postgresProductRepository) AddCartQuantity(ctx context.Context, userID, productID, variantID int64,
+	quantity int, ttl time.Duration) (int, error) {
+
+	key, field := userCartKey(userID), cartField(productID, variantID)
+	var total *redis.IntCmd
+	_, err := r.rc.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		total = pipe.HIncrBy(ctx, key, field, int64(quantity))
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error adding cart item: %w", err)
+	}
+
+	if total.Val() <= 0 {
+		if err := r.rc.HDel(ctx, key, field).Err(); err != nil {
+			return 0, fmt.Errorf("error removing cart item: %w", err)
+		}
+	}
+
+	return int(total.Val()), nil
+}
+
+func (r *postgresProductRepository) RemoveFromCart(ctx context.Context, userID, productID, variantID int64) error {
+	removed, err := r.rc.HDel(ctx, userCartKey(userID), cartField(productID, variantID)).Result()
+	if err != nil {
+		return fmt.Errorf("error removing cart item: %w", err)
+	}
+	if removed == 0 {
+		return ErrCartItemNotFound
 	}
 
 	return nil
 }
 
 func (r *postgresProductRepository) CheckAccess(ctx context.Context, productID int64) (int64, error) {
-	query := `SELECT seller_id FROM products WHERE id = $1;`
+	query := `SELECT seller_id FROM products WHERE id = $1 AND deleted_at IS NULL;`
 	row := r.pool.QueryRow(ctx, query, productID)
 	var sellerID int64
 	err := row.Scan(&sellerID)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return -1, ErrProductNotFound
+	}
 	if err != nil {
 		return -1, fmt.Errorf("error checking access: %w", err)
 	}
@@ -203,42 +822,464 @@ func (r *postgresProductRepository) CheckAccess(ctx context.Context, productID i
 	return sellerID, nil
 }
 
-func (r *postgresProductRepository) BuyProduct(ctx context.Context, productID int64, userID int64) error {
+// BuyProduct takes one item out of the stock of the product, or of its variant when variantID is set.
+// A product with variants can only be bought as one of them.
+func (r *postgresProductRepository) BuyProduct(ctx context.Context, productID, variantID, userID int64) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	// The product row is locked first in both cases, the variant totals are written to it.
+	// Stock reserved by pending orders cannot be bought.
 	var currentAmount int
+	var hasVariants bool
 	err = tx.QueryRow(ctx,
-		"SELECT amount FROM products WHERE id = $1 FOR UPDATE",
-		productID).Scan(&currentAmount)
-
+		`SELECT amount - reserved, EXISTS (SELECT 1 FROM product_variants WHERE product_id = $1)
+		FROM products WHERE id = $1 AND `+visibleProduct+` FOR UPDATE`,
+		productID).Scan(&currentAmount, &hasVariants)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrProductNotFound
+	}
 	if err != nil {
 		return fmt.Errorf("failed to query product amount: %w", err)
 	}
 
-	if currentAmount <= 0 {
-		return fmt.Errorf("product out of stock")
+	if variantID == 0 {
+		if hasVariants {
+			return ErrVariantRequired
+		}
+		if currentAmount <= 0 {
+			return ErrOutOfStock
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE products SET amount = amount - 1 WHERE id = $1",
+			productID)
+		if err != nil {
+			return fmt.Errorf("failed to update product amount: %w", err)
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO purchases (user_id, product_id, title, price, created_at)
+			SELECT $2, id, title, price, NOW() FROM products WHERE id = $1`,
+			productID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to record purchase: %w", err)
+		}
+	} else {
+		var variantAmount int
+		err = tx.QueryRow(ctx,
+			"SELECT amount - reserved FROM product_variants WHERE id = $1 AND product_id = $2 FOR UPDATE",
+			variantID, productID).Scan(&variantAmount)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrVariantNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query variant amount: %w", err)
+		}
+		if variantAmount <= 0 {
+			return ErrOutOfStock
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE product_variants SET amount = amount - 1, updated_at = NOW() WHERE id = $1",
+			variantID)
+		if err != nil {
+			return fmt.Errorf("failed to update variant amount: %w", err)
+		}
+		if err := syncVariantTotals(ctx, tx, productID); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO purchases (user_id, product_id, variant_id, title, sku, price, created_at)
+			SELECT $2, p.id, v.id, p.title, v.sku, v.price, NOW()
+			FROM products p JOIN product_variants v ON v.product_id = p.id
+			WHERE v.id = $1`,
+			variantID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to record purchase: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	_, err = tx.Exec(ctx,
-		"UPDATE products SET amount = amount - 1 WHERE id = $1",
-		productID)
+	// The purchase is done, a cart left out of date only shows one item too many
+	key, field := userCartKey(userID), cartField(productID, variantID)
+	left, err := r.rc.HIncrBy(ctx, key, field, -1).Result()
+	if err == nil && left <= 0 {
+		err = r.rc.HDel(ctx, key, field).Err()
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update product amount: %w", err)
+		return fmt.Errorf("failed to update cart: %w", err)
 	}
 
-	rowRedis := r.rc.Del(ctx, fmt.Sprintf("%s_%d_%d", cartKey, userID, productID))
+	return nil
+}
 
-	if rowRedis.Err() != nil {
-		return fmt.Errorf("failed to delete redis key: %w", rowRedis.Err())
+func (r *postgresProductRepository) ListAllProducts(ctx context.Context,
+	filter model.AdminProductFilter) ([]model.Product, error) {
+
+	query := `SELECT id,
+	title,
+	seller_name,
+	seller_id,
+	product_description,
+	product_image,
+	price,
+	amount,
+	reserved,
+	GREATEST(amount - reserved, 0),
+	is_hidden,
+	status,
+	publish_at,
+	category_id
+	FROM products
+	WHERE deleted_at IS NULL
+	AND ($1 = '' OR title ILIKE '%' || $1 || '%')
+	AND ($2 = 0 OR seller_id = $2)
+	AND (NOT $3 OR is_hidden)
+	ORDER BY id
+	LIMIT $4 OFFSET $5;`
+	rows, err := r.pool.Query(ctx, query,
+		filter.Query, filter.SellerID, filter.HiddenOnly, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %w", err)
 	}
+	defer rows.Close()
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	var products []model.Product
+	for rows.Next() {
+		var p model.Product
+		err := rows.Scan(
+			&p.ID,
+			&p.Title,
+			&p.SellerName,
+			&p.SellerID,
+			&p.ProductDescription,
+			&p.ProductImage,
+			&p.Price,
+			&p.Amount,
+			&p.Reserved,
+			&p.Available,
+			&p.Hidden,
+			&p.Status,
+			&p.PublishAt,
+			&p.CategoryID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return products, nil
+}
+
+// ListSellerProducts returns the products of the seller in any status, newest first. The deleted
+// products are listed apart, when filter.Deleted is set.
+func (r *postgresProductRepository) ListSellerProducts(ctx context.Context,
+	filter model.SellerProductFilter) ([]model.Product, error) {
+
+	query := `SELECT id,
+	title,
+	seller_name,
+	seller_id,
+	product_description,
+	product_image,
+	price,
+	amount,
+	reserved,
+	GREATEST(amount - reserved, 0),
+	is_hidden,
+	status,
+	publish_at,
+	deleted_at,
+	category_id,
+	attributes,
+	created_at
+	FROM products
+	WHERE seller_id = $1 AND ($2 = '' OR status = $2) AND (deleted_at IS NOT NULL) = $3
+	ORDER BY created_at DESC, id DESC
+	LIMIT $4 OFFSET $5;`
+	rows, err := r.pool.Query(ctx, query,
+		filter.SellerID, filter.Status, filter.Deleted, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []model.Product
+	for rows.Next() {
+		var p model.Product
+		err := rows.Scan(
+			&p.ID,
+			&p.Title,
+			&p.SellerName,
+			&p.SellerID,
+			&p.ProductDescription,
+			&p.ProductImage,
+			&p.Price,
+			&p.Amount,
+			&p.Reserved,
+			&p.Available,
+			&p.Hidden,
+			&p.Status,
+			&p.PublishAt,
+			&p.DeletedAt,
+			&p.CategoryID,
+			&p.Attributes,
+			&p.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return products, nil
+}
+
+// SetProductStatus moves the product to the status if its current status is one of from,
+// so concurrent changes cannot make a transition that is not allowed
+func (r *postgresProductRepository) SetProductStatus(ctx context.Context, productID int64, status string,
+	publishAt *time.Time, from []string) error {
+
+	query := `UPDATE products SET status = $2, publish_at = $3, updated_at = NOW()
+	WHERE id = $1 AND status = ANY($4) AND deleted_at IS NULL;`
+	tag, err := r.pool.Exec(ctx, query, productID, status, publishAt, from)
+	if err != nil {
+		return fmt.Errorf("failed to update product status: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	if _, err := r.CheckAccess(ctx, productID); err != nil {
+		return err
+	}
+
+	return ErrInvalidStatusTransition
+}
+
+// PublishScheduled publishes the scheduled products whose time has come and returns how many
+func (r *postgresProductRepository) PublishScheduled(ctx context.Context) (int64, error) {
+	query := `UPDATE products SET status = 'published', publish_at = NULL, updated_at = NOW()
+	WHERE status = 'scheduled' AND publish_at <= NOW() AND deleted_at IS NULL;`
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish scheduled products: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *postgresProductRepository) SetProductHidden(ctx context.Context, productID int64, hidden bool) error {
+	query := `UPDATE products SET is_hidden = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL;`
+	tag, err := r.pool.Exec(ctx, query, productID, hidden)
+	if err != nil {
+		return fmt.Errorf("failed to update product visibility: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProductNotFound
+	}
+
+	return nil
+}
+
+// GetCart returns the items in the cart with their current price and stock. Items of deleted products
+// or variants are left out, items of products that are not sold right now have nothing available.
+func (r *postgresProductRepository) GetCart(ctx context.Context, userID int64) ([]model.CartItem, error) {
+	fields, err := r.rc.HGetAll(ctx, userCartKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error getting cart: %w", err)
+	}
+
+	// Fields are the product id, followed by the variant id for a variant
+	var productIDs, variantIDs []int64
+	var quantities []int32
+	for field, value := range fields {
+		productPart, variantPart, hasVariant := strings.Cut(field, "_")
+		productID, err := strconv.ParseInt(productPart, 10, 64)
+		if err != nil {
+			continue
+		}
+		var variantID int64
+		if hasVariant {
+			if variantID, err = strconv.ParseInt(variantPart, 10, 64); err != nil {
+				continue
+			}
+		}
+		quantity, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || quantity <= 0 {
+			continue
+		}
+		productIDs = append(productIDs, productID)
+		variantIDs = append(variantIDs, variantID)
+		quantities = append(quantities, int32(quantity))
+	}
+
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT p.id, v.id, p.title, COALESCE(v.sku, ''), COALESCE(v.price, p.price), c.quantity,
+	CASE WHEN ` + visibleProduct + ` THEN GREATEST(COALESCE(v.amount - v.reserved, p.amount - p.reserved), 0) ELSE 0 END
+	FROM unnest($1::bigint[], $2::bigint[], $3::int[]) AS c(product_id, variant_id, quantity)
+	JOIN products p ON p.id = c.product_id AND p.deleted_at IS NULL
+	LEFT JOIN product_variants v ON v.id = c.variant_id AND v.product_id = p.id
+	WHERE c.variant_id = 0 OR v.id IS NOT NULL
+	ORDER BY p.id, v.id NULLS FIRST;`
+	rows, err := r.pool.Query(ctx, query, productIDs, variantIDs, quantities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cart products: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.CartItem
+	for rows.Next() {
+		var item model.CartItem
+		err := rows.Scan(&item.ProductID, &item.VariantID, &item.Title, &item.SKU, &item.Price,
+			&item.Quantity, &item.Available)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cart product: %w", err)
+		}
+		item.LineTotal = item.Price * int64(item.Quantity)
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *postgresProductRepository) ClearCart(ctx context.Context, userID int64) error {
+	if err := r.rc.Del(ctx, userCartKey(userID)).Err(); err != nil {
+		return fmt.Errorf("error deleting cart: %w", err)
 	}
 
 	return nil
 }
+
+// takeFromCartScript takes the quantities in ARGV, given as field and quantity pairs, out of the cart
+// in KEYS[1] and removes the items left with nothing
+var takeFromCartScript = redis.NewScript(`
+for i = 1, #ARGV, 2 do
+	local left = redis.call("HINCRBY", KEYS[1], ARGV[i], -tonumber(ARGV[i + 1]))
+	if left <= 0 then
+		redis.call("HDEL", KEYS[1], ARGV[i])
+	end
+end
+return 0
+`)
+
+// TakeFromCart takes the ordered items out of the cart. Items added while the order was placed stay.
+func (r *postgresProductRepository) TakeFromCart(ctx context.Context, userID int64, items []model.CartItem) error {
+	args := make([]any, 0, 2*len(items))
+	for _, item := range items {
+		args = append(args, cartField(item.ProductID, variantIDOf(item)), item.Quantity)
+	}
+
+	if err := takeFromCartScript.Run(ctx, r.rc, []string{userCartKey(userID)}, args...).Err(); err != nil {
+		return fmt.Errorf("error taking items out of cart: %w", err)
+	}
+
+	return nil
+}
+
+// GetProductsBySeller returns every product of the seller, hidden and deleted ones included.
+// Deleted products have their deleted_at set.
+func (r *postgresProductRepository) GetProductsBySeller(ctx context.Context, sellerID int64) ([]model.Product, error) {
+	query := `SELECT id,
+	title,
+	seller_name,
+	seller_id,
+	product_description,
+	product_image,
+	price,
+	amount,
+	reserved,
+	GREATEST(amount - reserved, 0),
+	is_hidden,
+	category_id,
+	deleted_at
+	FROM products
+	WHERE seller_id = $1
+	ORDER BY id;`
+	rows, err := r.pool.Query(ctx, query, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []model.Product
+	for rows.Next() {
+		var p model.Product
+		err := rows.Scan(
+			&p.ID,
+			&p.Title,
+			&p.SellerName,
+			&p.SellerID,
+			&p.ProductDescription,
+			&p.ProductImage,
+			&p.Price,
+			&p.Amount,
+			&p.Reserved,
+			&p.Available,
+			&p.Hidden,
+			&p.CategoryID,
+			&p.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return products, nil
+}
+
+func (r *postgresProductRepository) GetPurchases(ctx context.Context, userID int64) ([]model.Purchase, error) {
+	query := `SELECT id, product_id, variant_id, title, COALESCE(sku, ''), price, created_at
+	FROM purchases
+	WHERE user_id = $1
+	ORDER BY created_at, id;`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purchases: %w", err)
+	}
+	defer rows.Close()
+
+	var purchases []model.Purchase
+	for rows.Next() {
+		var p model.Purchase
+		if err := rows.Scan(&p.ID, &p.ProductID, &p.VariantID, &p.Title, &p.SKU, &p.Price, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase: %w", err)
+		}
+		purchases = append(purchases, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return purchases, nil
+}
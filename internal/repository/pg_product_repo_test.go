@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+)
+
+func TestCatalogFilterSearch(t *testing.T) {
+	b := catalogFilter(model.ProductFilter{Query: "iphone 15", MaxPrice: 1000})
+
+	assert.Equal(t, "WHERE NOT is_hidden AND status = 'published' AND deleted_at IS NULL AND (search_vector @@ (websearch_to_tsquery('russian', $1) || "+
+		"websearch_to_tsquery('english', $1)) OR $1 <% title) AND price <= $2", b.where())
+	assert.Equal(t, []interface{}{"iphone 15", int64(1000)}, b.args)
+}
+
+func TestMarkHighlights(t *testing.T) {
+	assert.Equal(t, "new <mark>iPhone</mark> &lt;b&gt;15&lt;/b&gt;",
+		markHighlights("new "+highlightStart+"iPhone"+highlightStop+" <b>15</b>"))
+}
+
+func TestCatalogFilterAttributes(t *testing.T) {
+	b := catalogFilter(model.ProductFilter{Attributes: []model.AttributeFilter{
+		{Code: "ram_gb", Op: model.AttributeOpGte, Value: "16"},
+		{Code: "gpu", Op: model.AttributeOpEq, Value: "RTX 3070"},
+		{Code: "touch", Op: model.AttributeOpNe, Value: "true"},
+	}})
+
+	assert.Equal(t, "WHERE NOT is_hidden AND status = 'published' AND deleted_at IS NULL AND jsonb_path_exists(attributes, $1::jsonpath, $2::jsonb) "+
+		"AND (attributes @> $3::jsonb) "+
+		"AND (attributes -> $4 IS NOT NULL AND NOT (attributes @> $5::jsonb OR attributes @> $6::jsonb))", b.where())
+	assert.Equal(t, []interface{}{
+		`$."ram_gb" ? (@ >= $v)`, `{"v":16}`,
+		`{"gpu":"RTX 3070"}`,
+		"touch", `{"touch":"true"}`, `{"touch":true}`,
+	}, b.args)
+}
@@ -2,17 +2,37 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserAlreadyExists = errors.New("user with this name or email already exists")
+)
+
+// uniqueViolation is the postgres error code for a broken unique constraint
+const uniqueViolation = "23505"
+
 type UserRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetUserByID(ctx context.Context, id int64) (*model.User, error)
 	CreateUser(ctx context.Context, usr model.User, passwordHash string) (int64, error)
 	GetHashedPassword(ctx context.Context, email string) (string, error)
+	ListUsers(ctx context.Context, filter model.UserFilter) ([]model.User, error)
+	SetUserBanned(ctx context.Context, id int64, banned bool) error
+	UpdateUserRole(ctx context.Context, id int64, role string) error
+	MarkEmailVerified(ctx context.Context, id int64, email string) error
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	UpdateUserProfile(ctx context.Context, id int64, userName, email string) error
+	GetCredentialsInfo(ctx context.Context, id int64) (*model.CredentialsInfo, error)
+	AnonymizeUser(ctx context.Context, id int64, deleteProducts bool) error
 }
 
 type postgresUserRepository struct {
@@ -24,10 +44,28 @@ func NewPostgresUserRepository(pool *pgxpool.Pool) UserRepository {
 }
 
 func (r *postgresUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	query := `SELECT id, user_name, email, user_role FROM users WHERE email = $1;`
+	query := `SELECT id, user_name, email, user_role, banned_at, email_verified_at, deleted_at FROM users WHERE email = $1;`
 	row := r.pool.QueryRow(ctx, query, email)
 	var usr model.User
-	err := row.Scan(&usr.ID, &usr.UserName, &usr.Email, &usr.Role)
+	err := row.Scan(&usr.ID, &usr.UserName, &usr.Email, &usr.Role, &usr.BannedAt, &usr.EmailVerifiedAt, &usr.DeletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error performing get user query: %w", err)
+	}
+
+	return &usr, nil
+}
+
+func (r *postgresUserRepository) GetUserByID(ctx context.Context, id int64) (*model.User, error) {
+	query := `SELECT id, user_name, email, user_role, banned_at, email_verified_at, deleted_at FROM users WHERE id = $1;`
+	row := r.pool.QueryRow(ctx, query, id)
+	var usr model.User
+	err := row.Scan(&usr.ID, &usr.UserName, &usr.Email, &usr.Role, &usr.BannedAt, &usr.EmailVerifiedAt, &usr.DeletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error performing get user query: %w", err)
 	}
@@ -40,6 +78,9 @@ func (r *postgresUserRepository) GetHashedPassword(ctx context.Context, email st
 	row := r.pool.QueryRow(ctx, query, email)
 	var hashedPass string
 	err := row.Scan(&hashedPass)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrUserNotFound
+	}
 	if err != nil {
 		return "", fmt.Errorf("error scanning hashed password: %w", err)
 	}
@@ -62,6 +103,9 @@ func (r *postgresUserRepository) CreateUser(ctx context.Context, usr model.User,
                  RETURNING id`
 
 	err = tx.QueryRow(ctx, userQuery, usr.UserName, usr.Email, usr.Role).Scan(&userID)
+	if isUniqueViolation(err) {
+		return 0, ErrUserAlreadyExists
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert user: %w", err)
 	}
@@ -81,3 +125,221 @@ func (r *postgresUserRepository) CreateUser(ctx context.Context, usr model.User,
 
 	return userID, nil
 }
+
+func (r *postgresUserRepository) ListUsers(ctx context.Context, filter model.UserFilter) ([]model.User, error) {
+	query := `SELECT id, user_name, email, user_role, banned_at, email_verified_at, deleted_at
+	FROM users
+	WHERE ($1 = '' OR user_name ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%')
+	AND ($2 = '' OR user_role = $2)
+	ORDER BY id
+	LIMIT $3 OFFSET $4;`
+	rows, err := r.pool.Query(ctx, query, filter.Query, filter.Role, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var usr model.User
+		err := rows.Scan(&usr.ID, &usr.UserName, &usr.Email, &usr.Role, &usr.BannedAt, &usr.EmailVerifiedAt, &usr.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, usr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *postgresUserRepository) SetUserBanned(ctx context.Context, id int64, banned bool) error {
+	query := `UPDATE users
+	SET banned_at = CASE WHEN $2 THEN COALESCE(banned_at, NOW()) ELSE NULL END,
+	updated_at = NOW()
+	WHERE id = $1;`
+	tag, err := r.pool.Exec(ctx, query, id, banned)
+	if err != nil {
+		return fmt.Errorf("failed to update user ban: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepository) UpdateUserRole(ctx context.Context, id int64, role string) error {
+	query := `UPDATE users SET user_role = $2, updated_at = NOW() WHERE id = $1;`
+	tag, err := r.pool.Exec(ctx, query, id, role)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified marks the email of the user verified, unless the user has another email by now
+func (r *postgresUserRepository) MarkEmailVerified(ctx context.Context, id int64, email string) error {
+	query := `UPDATE users
+	SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+	WHERE id = $1 AND email = $2;`
+	tag, err := r.pool.Exec(ctx, query, id, email)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	query := `UPDATE users_creds SET password_hash = $2, updated_at = NOW() WHERE user_id = $1;`
+	tag, err := r.pool.Exec(ctx, query, id, passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdateUserProfile renames the user and copies the new name and email to users_creds
+// and the user's products. A changed email has to be verified again.
+func (r *postgresUserRepository) UpdateUserProfile(ctx context.Context, id int64, userName, email string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The referencing columns are only consistent again after all updates below
+	_, err = tx.Exec(ctx, `SET CONSTRAINTS users_creds_email_fkey, products_seller_name_fkey DEFERRED;`)
+	if err != nil {
+		return fmt.Errorf("failed to defer constraints: %w", err)
+	}
+
+	userQuery := `UPDATE users
+	SET user_name = $2, email = $3,
+	email_verified_at = CASE WHEN email = $3 THEN email_verified_at ELSE NULL END,
+	updated_at = NOW()
+	WHERE id = $1;`
+	tag, err := tx.Exec(ctx, userQuery, id, userName, email)
+	if isUniqueViolation(err) {
+		return ErrUserAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	credsQuery := `UPDATE users_creds SET email = $2, updated_at = NOW() WHERE user_id = $1;`
+	if _, err := tx.Exec(ctx, credsQuery, id, email); err != nil {
+		return fmt.Errorf("failed to update user credentials: %w", err)
+	}
+
+	productsQuery := `UPDATE products SET seller_name = $2, updated_at = NOW() WHERE seller_id = $1;`
+	if _, err := tx.Exec(ctx, productsQuery, id, userName); err != nil {
+		return fmt.Errorf("failed to update seller name: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepository) GetCredentialsInfo(ctx context.Context, id int64) (*model.CredentialsInfo, error) {
+	query := `SELECT created_at, updated_at FROM users_creds WHERE user_id = $1;`
+	var info model.CredentialsInfo
+	err := r.pool.QueryRow(ctx, query, id).Scan(&info.CreatedAt, &info.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error performing get credentials query: %w", err)
+	}
+
+	return &info, nil
+}
+
+// AnonymizeUser erases the personal data of the user but keeps the row, so purchases
+// and, unless deleteProducts is set, the listed products stay consistent
+func (r *postgresUserRepository) AnonymizeUser(ctx context.Context, id int64, deleteProducts bool) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `SET CONSTRAINTS users_creds_email_fkey, products_seller_name_fkey DEFERRED;`)
+	if err != nil {
+		return fmt.Errorf("failed to defer constraints: %w", err)
+	}
+
+	userName := fmt.Sprintf("deleted_user_%d", id)
+	email := fmt.Sprintf("deleted_%d@deleted.invalid", id)
+
+	userQuery := `UPDATE users
+	SET user_name = $2, email = $3, email_verified_at = NULL, deleted_at = NOW(), updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL;`
+	tag, err := tx.Exec(ctx, userQuery, id, userName, email)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	// An empty hash never matches a password, so nobody can log in anymore
+	credsQuery := `UPDATE users_creds SET email = $2, password_hash = '', updated_at = NOW() WHERE user_id = $1;`
+	if _, err := tx.Exec(ctx, credsQuery, id, email); err != nil {
+		return fmt.Errorf("failed to erase user credentials: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()) WHERE user_id = $1;`,
+		id); err != nil {
+		return fmt.Errorf("failed to revoke api keys: %w", err)
+	}
+
+	// Signing in at a provider must not lead back to the anonymized account
+	if _, err := tx.Exec(ctx, `DELETE FROM user_identities WHERE user_id = $1;`, id); err != nil {
+		return fmt.Errorf("failed to unlink identities: %w", err)
+	}
+
+	if deleteProducts {
+		// The purge job removes them for good after the retention period
+		_, err = tx.Exec(ctx, `UPDATE products SET seller_name = $2, deleted_at = COALESCE(deleted_at, NOW()),
+		updated_at = NOW() WHERE seller_id = $1;`, id, userName)
+	} else {
+		_, err = tx.Exec(ctx, `UPDATE products SET seller_name = $2, is_hidden = TRUE, updated_at = NOW()
+		WHERE seller_id = $1;`, id, userName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to handle seller products: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
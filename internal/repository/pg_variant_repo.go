@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrVariantNotFound      = errors.New("variant not found")
+	ErrVariantAlreadyExists = errors.New("variant with this sku or options already exists")
+	ErrVariantRequired      = errors.New("product has variants, one must be chosen")
+	ErrOutOfStock           = errors.New("product out of stock")
+	ErrProductReserved      = errors.New("product has stock reserved by pending orders")
+)
+
+// OutOfStockError names the item that does not have enough stock, it matches ErrOutOfStock
+type OutOfStockError struct {
+	Item string
+}
+
+func (e *OutOfStockError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrOutOfStock, e.Item)
+}
+
+func (e *OutOfStockError) Unwrap() error {
+	return ErrOutOfStock
+}
+
+type VariantRepository interface {
+	ListVariants(ctx context.Context, productID int64) ([]model.ProductVariant, error)
+	GetVariant(ctx context.Context, productID, variantID int64) (*model.ProductVariant, error)
+	CreateVariant(ctx context.Context, variant model.ProductVariant) (int64, error)
+	UpdateVariant(ctx context.Context, variant model.ProductVariant) error
+	DeleteVariant(ctx context.Context, productID, variantID int64) error
+}
+
+type postgresVariantRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresVariantRepository(pool *pgxpool.Pool) VariantRepository {
+	return &postgresVariantRepository{pool: pool}
+}
+
+func (r *postgresVariantRepository) ListVariants(ctx context.Context, productID int64) ([]model.ProductVariant, error) {
+	query := `SELECT id, product_id, sku, options, price, amount, reserved, GREATEST(amount - reserved, 0)
+	FROM product_variants
+	WHERE product_id = $1
+	ORDER BY id;`
+	rows, err := r.pool.Query(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []model.ProductVariant
+	for rows.Next() {
+		var v model.ProductVariant
+		if err := rows.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Options, &v.Price, &v.Amount,
+			&v.Reserved, &v.Available); err != nil {
+			return nil, fmt.Errorf("failed to scan variant: %w", err)
+		}
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return variants, nil
+}
+
+func (r *postgresVariantRepository) GetVariant(ctx context.Context, productID, variantID int64) (*model.ProductVariant, error) {
+	query := `SELECT id, product_id, sku, options, price, amount, reserved, GREATEST(amount - reserved, 0)
+	FROM product_variants
+	WHERE id = $1 AND product_id = $2;`
+	var v model.ProductVariant
+	err := r.pool.QueryRow(ctx, query, variantID, productID).
+		Scan(&v.ID, &v.ProductID, &v.SKU, &v.Options, &v.Price, &v.Amount,
+			&v.Reserved, &v.Available)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant: %w", err)
+	}
+
+	return &v, nil
+}
+
+func (r *postgresVariantRepository) CreateVariant(ctx context.Context, variant model.ProductVariant) (int64, error) {
+	var id int64
+	err := r.inTx(ctx, variant.ProductID, func(tx pgx.Tx) error {
+		// The first variant takes over the stock of the product, which must not hold reservations then
+		var reservedWithoutVariants bool
+		err := tx.QueryRow(ctx, `SELECT reserved > 0 AND NOT EXISTS (SELECT 1 FROM product_variants WHERE product_id = $1)
+		FROM products WHERE id = $1`, variant.ProductID).Scan(&reservedWithoutVariants)
+		if err != nil {
+			return fmt.Errorf("failed to query product reservations: %w", err)
+		}
+		if reservedWithoutVariants {
+			return ErrProductReserved
+		}
+
+		query := `INSERT INTO product_variants (product_id, sku, options, price, amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id;`
+		err = tx.QueryRow(ctx, query,
+			variant.ProductID, variant.SKU, variant.Options, variant.Price, variant.Amount).Scan(&id)
+		if isUniqueViolation(err) {
+			return ErrVariantAlreadyExists
+		}
+		if isForeignKeyViolation(err) {
+			return ErrProductNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create variant: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+func (r *postgresVariantRepository) UpdateVariant(ctx context.Context, variant model.ProductVariant) error {
+	return r.inTx(ctx, variant.ProductID, func(tx pgx.Tx) error {
+		query := `UPDATE product_variants
+		SET sku = $3, options = $4, price = $5, amount = $6, updated_at = NOW()
+		WHERE id = $1 AND product_id = $2;`
+		tag, err := tx.Exec(ctx, query,
+			variant.ID, variant.ProductID, variant.SKU, variant.Options, variant.Price, variant.Amount)
+		if isUniqueViolation(err) {
+			return ErrVariantAlreadyExists
+		}
+		if isCheckViolation(err) {
+			return ErrAmountBelowReserved
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update variant: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrVariantNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteVariant removes the variant. Purchases of it keep their sku. A variant with stock reserved
+// by pending orders cannot be deleted, paying them would take the stock of the product instead.
+func (r *postgresVariantRepository) DeleteVariant(ctx context.Context, productID, variantID int64) error {
+	return r.inTx(ctx, productID, func(tx pgx.Tx) error {
+		var reserved int
+		err := tx.QueryRow(ctx, `SELECT reserved FROM product_variants WHERE id = $1 AND product_id = $2`,
+			variantID, productID).Scan(&reserved)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrVariantNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query variant reservations: %w", err)
+		}
+		if reserved > 0 {
+			return ErrProductReserved
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM product_variants WHERE id = $1 AND product_id = $2;`,
+			variantID, productID)
+		if err != nil {
+			return fmt.Errorf("failed to delete variant: %w", err)
+		}
+		return nil
+	})
+}
+
+// inTx runs a change of the variants of the product and updates the product totals in the same transaction.
+// The product is locked first, in the same order as checkout locks the stock.
+func (r *postgresVariantRepository) inTx(ctx context.Context, productID int64, fn func(tx pgx.Tx) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked int
+	err = tx.QueryRow(ctx, "SELECT 1 FROM products WHERE id = $1 FOR UPDATE", productID).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := syncVariantTotals(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// syncVariantTotals sets the price of a product with variants to the lowest variant price and
+// its amount and reserved stock to their totals, so the catalog filters and sorts keep working on products.
+// A product whose last variant was deleted keeps its price and has no stock left.
+func syncVariantTotals(ctx context.Context, tx pgx.Tx, productID int64) error {
+	query := `UPDATE products p
+	SET price = COALESCE(v.min_price, p.price), amount = COALESCE(v.total, 0), reserved = COALESCE(v.reserved, 0),
+		updated_at = NOW()
+	FROM (SELECT MIN(price) AS min_price, SUM(amount) AS total, SUM(reserved) AS reserved
+		FROM product_variants WHERE product_id = $1) v
+	WHERE p.id = $1;`
+	if _, err := tx.Exec(ctx, query, productID); err != nil {
+		return fmt.Errorf("failed to update product totals: %w", err)
+	}
+
+	return nil
+}
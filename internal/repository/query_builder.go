@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// whereBuilder collects the conditions of a WHERE clause together with their arguments.
+// Conditions use ? placeholders, numbered when the clause is built.
+type whereBuilder struct {
+	conds []string
+	args  []interface{}
+}
+
+func (b *whereBuilder) add(cond string, args ...interface{}) {
+	for _, arg := range args {
+		b.args = append(b.args, arg)
+		cond = strings.Replace(cond, "?", fmt.Sprintf("$%d", len(b.args)), 1)
+	}
+	b.conds = append(b.conds, cond)
+}
+
+// addIf adds the condition only when ok is set, for filters that are optional
+func (b *whereBuilder) addIf(ok bool, cond string, args ...interface{}) {
+	if ok {
+		b.add(cond, args...)
+	}
+}
+
+// arg appends an argument that is not part of a condition, such as a LIMIT, and returns its placeholder
+func (b *whereBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *whereBuilder) where() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conds, " AND ")
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhereBuilder(t *testing.T) {
+	var b whereBuilder
+	assert.Equal(t, "", b.where())
+
+	b.add("NOT is_hidden")
+	b.add("price >= ?", int64(100))
+	b.addIf(false, "seller_id = ?", int64(2))
+	b.addIf(true, "(price, id) > (?, ?)", int64(500), int64(7))
+
+	assert.Equal(t, "WHERE NOT is_hidden AND price >= $1 AND (price, id) > ($2, $3)", b.where())
+	assert.Equal(t, "$4", b.arg(20))
+	assert.Equal(t, []interface{}{int64(100), int64(500), int64(7), 20}, b.args)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresKey = "login_fail"
+	loginLockKey     = "login_lock"
+)
+
+// LoginAttemptRepository counts failed logins per subject (an email or a client IP)
+// and keeps temporary lockouts
+type LoginAttemptRepository interface {
+	RegisterFailure(ctx context.Context, subject string, window time.Duration) (int64, error)
+	Lock(ctx context.Context, subject string, duration time.Duration) error
+	LockedFor(ctx context.Context, subject string) (time.Duration, error)
+	Reset(ctx context.Context, subject string) error
+}
+
+type redisLoginAttemptRepository struct {
+	rc *redis.Client
+}
+
+func NewRedisLoginAttemptRepository(rc *redis.Client) LoginAttemptRepository {
+	return &redisLoginAttemptRepository{rc: rc}
+}
+
+// RegisterFailure increments the failure counter of the subject and returns its new value.
+// The counter is forgotten after window without failures.
+func (r *redisLoginAttemptRepository) RegisterFailure(ctx context.Context, subject string,
+	window time.Duration) (int64, error) {
+
+	key := fmt.Sprintf("%s_%s", loginFailuresKey, subject)
+
+	var incr *redis.IntCmd
+	_, err := r.rc.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error registering login failure: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+func (r *redisLoginAttemptRepository) Lock(ctx context.Context, subject string, duration time.Duration) error {
+	err := r.rc.Set(ctx, fmt.Sprintf("%s_%s", loginLockKey, subject), "1", duration).Err()
+	if err != nil {
+		return fmt.Errorf("error locking login: %w", err)
+	}
+
+	return nil
+}
+
+// LockedFor returns how long the subject stays locked, zero if it is not locked
+func (r *redisLoginAttemptRepository) LockedFor(ctx context.Context, subject string) (time.Duration, error) {
+	ttl, err := r.rc.PTTL(ctx, fmt.Sprintf("%s_%s", loginLockKey, subject)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("error checking login lock: %w", err)
+	}
+
+	// Negative values mean the key does not exist or has no expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (r *redisLoginAttemptRepository) Reset(ctx context.Context, subject string) error {
+	err := r.rc.Del(ctx,
+		fmt.Sprintf("%s_%s", loginFailuresKey, subject),
+		fmt.Sprintf("%s_%s", loginLockKey, subject),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("error resetting login attempts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const oidcStateKey = "oidc_state"
+
+// OIDCStateRepository keeps pending logins at external providers by the hash of their state parameter
+type OIDCStateRepository interface {
+	SaveState(ctx context.Context, stateHash string, state model.OIDCLoginState, ttl time.Duration) error
+	ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error)
+}
+
+type redisOIDCStateRepository struct {
+	rc *redis.Client
+}
+
+func NewRedisOIDCStateRepository(rc *redis.Client) OIDCStateRepository {
+	return &redisOIDCStateRepository{rc: rc}
+}
+
+func (r *redisOIDCStateRepository) SaveState(ctx context.Context, stateHash string,
+	state model.OIDCLoginState, ttl time.Duration) error {
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("error marshalling login state: %w", err)
+	}
+
+	err = r.rc.Set(ctx, fmt.Sprintf("%s_%s", oidcStateKey, stateHash), data, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("error saving login state: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeState returns the pending login and deletes it, so a callback cannot be replayed
+func (r *redisOIDCStateRepository) ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error) {
+	data, err := r.rc.GetDel(ctx, fmt.Sprintf("%s_%s", oidcStateKey, stateHash)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error consuming login state: %w", err)
+	}
+
+	var state model.OIDCLoginState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error unmarshalling login state: %w", err)
+	}
+
+	return &state, nil
+}
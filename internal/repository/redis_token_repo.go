@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	refreshTokenKey = "refresh"
+	revokedTokenKey = "revoked"
+	tokenVersionKey = "token_version"
+)
+
+// Purposes of single-use tokens sent to users by email
+const (
+	PurposeEmailVerification = "verify_email"
+	PurposePasswordReset     = "password_reset"
+)
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+	ErrTokenNotOwned = errors.New("token belongs to another user")
+)
+
+// revokeRefreshTokenScript deletes a refresh token only if it belongs to the user in ARGV[1].
+// It returns -1 for a missing token, 0 for a token of another user and 1 once it is deleted.
+var revokeRefreshTokenScript = redis.NewScript(`
+local val = redis.call("GET", KEYS[1])
+if not val then
+	return -1
+end
+if string.match(val, "^(%d+):") ~= ARGV[1] then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+return 1
+`)
+
+type TokenRepository interface {
+	SaveRefreshToken(ctx context.Context, tokenHash string, userID, version int64, ttl time.Duration) error
+	ConsumeRefreshToken(ctx context.Context, tokenHash string) (int64, int64, error)
+	RevokeRefreshToken(ctx context.Context, tokenHash string, userID int64) error
+	RevokeAccessToken(ctx context.Context, jti string, ttl time.Duration) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeUserTokens(ctx context.Context, userID int64) error
+	GetUserTokenVersion(ctx context.Context, userID int64) (int64, error)
+	SaveOneTimeToken(ctx context.Context, purpose, tokenHash string, userID int64, email string,
+		ttl time.Duration) error
+	ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (int64, string, error)
+}
+
+type redisTokenRepository struct {
+	rc *redis.Client
+}
+
+func NewRedisTokenRepository(rc *redis.Client) TokenRepository {
+	return &redisTokenRepository{rc: rc}
+}
+
+// SaveRefreshToken stores the refresh token of the user with the token version it was issued under
+func (r *redisTokenRepository) SaveRefreshToken(ctx context.Context, tokenHash string, userID, version int64,
+	ttl time.Duration) error {
+
+	value := fmt.Sprintf("%d:v%d", userID, version)
+	err := r.rc.Set(ctx, fmt.Sprintf("%s_%s", refreshTokenKey, tokenHash), value, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("error saving refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeRefreshToken atomically reads and deletes a refresh token so it can be used only once.
+// It returns the owner of the token and the token version it was issued under.
+func (r *redisTokenRepository) ConsumeRefreshToken(ctx context.Context, tokenHash string) (int64, int64, error) {
+	val, err := r.rc.GetDel(ctx, fmt.Sprintf("%s_%s", refreshTokenKey, tokenHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return -1, 0, ErrTokenNotFound
+	}
+	if err != nil {
+		return -1, 0, fmt.Errorf("error consuming refresh token: %w", err)
+	}
+
+	// Tokens saved before token versions hold their issue time instead, they have to log in again
+	var userID, version int64
+	if _, err := fmt.Sscanf(val, "%d:v%d", &userID, &version); err != nil {
+		return -1, 0, ErrTokenNotFound
+	}
+
+	return userID, version, nil
+}
+
+// RevokeRefreshToken deletes the refresh token of the user. A token of another user is left alone.
+func (r *redisTokenRepository) RevokeRefreshToken(ctx context.Context, tokenHash string, userID int64) error {
+	res, err := revokeRefreshTokenScript.Run(ctx, r.rc,
+		[]string{fmt.Sprintf("%s_%s", refreshTokenKey, tokenHash)}, userID).Int()
+	if err != nil {
+		return fmt.Errorf("error revoking refresh token: %w", err)
+	}
+
+	switch res {
+	case -1:
+		return ErrTokenNotFound
+	case 0:
+		return ErrTokenNotOwned
+	}
+
+	return nil
+}
+
+func (r *redisTokenRepository) RevokeAccessToken(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	err := r.rc.Set(ctx, fmt.Sprintf("%s_%s", revokedTokenKey, jti), "1", ttl).Err()
+	if err != nil {
+		return fmt.Errorf("error revoking access token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *redisTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := r.rc.Exists(ctx, fmt.Sprintf("%s_%s", revokedTokenKey, jti)).Result()
+	if err != nil {
+		return false, fmt.Errorf("error checking revoked token: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// RevokeUserTokens invalidates every token of the user issued up to now by moving the user
+// to the next token version. The version is kept without expiry, so it never goes back.
+func (r *redisTokenRepository) RevokeUserTokens(ctx context.Context, userID int64) error {
+	err := r.rc.Incr(ctx, fmt.Sprintf("%s_%d", tokenVersionKey, userID)).Err()
+	if err != nil {
+		return fmt.Errorf("error revoking user tokens: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserTokenVersion returns the version new tokens of the user are issued under,
+// 0 if the user's tokens were never revoked
+func (r *redisTokenRepository) GetUserTokenVersion(ctx context.Context, userID int64) (int64, error) {
+	val, err := r.rc.Get(ctx, fmt.Sprintf("%s_%d", tokenVersionKey, userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting user token version: %w", err)
+	}
+
+	return val, nil
+}
+
+// SaveOneTimeToken stores a single-use token of the user, bound to the email it is sent to
+func (r *redisTokenRepository) SaveOneTimeToken(ctx context.Context, purpose, tokenHash string,
+	userID int64, email string, ttl time.Duration) error {
+
+	value := fmt.Sprintf("%d:%s", userID, email)
+	err := r.rc.Set(ctx, fmt.Sprintf("%s_%s", purpose, tokenHash), value, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("error saving %s token: %w", purpose, err)
+	}
+
+	return nil
+}
+
+// ConsumeOneTimeToken returns the owner of the token and the email it was sent to, and deletes it in the same step
+func (r *redisTokenRepository) ConsumeOneTimeToken(ctx context.Context, purpose,
+	tokenHash string) (int64, string, error) {
+
+	val, err := r.rc.GetDel(ctx, fmt.Sprintf("%s_%s", purpose, tokenHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return -1, "", ErrTokenNotFound
+	}
+	if err != nil {
+		return -1, "", fmt.Errorf("error consuming %s token: %w", purpose, err)
+	}
+
+	// Tokens saved before they were bound to an email hold the user id only, a new one has to be requested
+	id, email, ok := strings.Cut(val, ":")
+	userID, err := strconv.ParseInt(id, 10, 64)
+	if !ok || err != nil {
+		return -1, "", ErrTokenNotFound
+	}
+
+	return userID, email, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AccountService interface {
+	ExportUserData(ctx context.Context, user model.User) (*model.UserExport, error)
+	DeleteAccount(ctx context.Context, user model.User, password string) error
+}
+
+type accountService struct {
+	userRepo    repository.UserRepository
+	productRepo repository.ProductRepository
+	orderRepo   repository.OrderRepository
+	tokenRepo   repository.TokenRepository
+	authCfg     config.AuthConfig
+	accountCfg  config.AccountConfig
+}
+
+func NewAccountService(userRepo repository.UserRepository, productRepo repository.ProductRepository,
+	orderRepo repository.OrderRepository, tokenRepo repository.TokenRepository, authCfg config.AuthConfig,
+	accountCfg config.AccountConfig) AccountService {
+	return &accountService{
+		userRepo:    userRepo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+		tokenRepo:   tokenRepo,
+		authCfg:     authCfg,
+		accountCfg:  accountCfg,
+	}
+}
+
+func (s *accountService) ExportUserData(ctx context.Context, user model.User) (*model.UserExport, error) {
+	creds, err := s.userRepo.GetCredentialsInfo(ctx, user.ID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.GetProductsBySeller(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.productRepo.GetCart(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	purchases, err := s.productRepo.GetPurchases(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.orderRepo.GetOrdersByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	returns, err := s.orderRepo.GetReturnsByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty lists instead of nulls so the archive has the same shape for everyone
+	export := &model.UserExport{
+		ExportedAt:  time.Now().UTC(),
+		Profile:     user,
+		Credentials: *creds,
+		Products:    append([]model.Product{}, products...),
+		Cart:        append([]model.CartItem{}, cart...),
+		Purchases:   append([]model.Purchase{}, purchases...),
+		Orders:      append([]model.Order{}, orders...),
+		Returns:     append([]model.OrderReturn{}, returns...),
+	}
+
+	return export, nil
+}
+
+func (s *accountService) DeleteAccount(ctx context.Context, user model.User, password string) error {
+	hashedPassword, err := s.userRepo.GetHashedPassword(ctx, user.Email)
+	if err != nil {
+		return fmt.Errorf("failed to get user credentials: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
+		return ErrWrongPassword
+	}
+
+	deleteProducts := s.accountCfg.DeletedSellerProducts == config.DeletedSellerProductsDelete
+	err = s.userRepo.AnonymizeUser(ctx, user.ID, deleteProducts)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.productRepo.ClearCart(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return s.tokenRepo.RevokeUserTokens(ctx, user.ID)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+)
+
+func (r *fakeUserRepo) GetCredentialsInfo(_ context.Context, id int64) (*model.CredentialsInfo, error) {
+	if id != r.user.ID {
+		return nil, repository.ErrUserNotFound
+	}
+	return &model.CredentialsInfo{}, nil
+}
+
+// fakeAccountProductRepo has nothing listed, in the cart or bought the legacy way
+type fakeAccountProductRepo struct {
+	repository.ProductRepository
+}
+
+func (r *fakeAccountProductRepo) GetProductsBySeller(context.Context, int64) ([]model.Product, error) {
+	return nil, nil
+}
+
+func (r *fakeAccountProductRepo) GetCart(context.Context, int64) ([]model.CartItem, error) {
+	return nil, nil
+}
+
+func (r *fakeAccountProductRepo) GetPurchases(context.Context, int64) ([]model.Purchase, error) {
+	return nil, nil
+}
+
+func (r *fakeOrderRepo) GetOrdersByUser(_ context.Context, userID int64) ([]model.Order, error) {
+	var orders []model.Order
+	for _, order := range r.orders {
+		if order.UserID == userID {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *fakeOrderRepo) GetReturnsByUser(_ context.Context, userID int64) ([]model.OrderReturn, error) {
+	var returns []model.OrderReturn
+	for _, ret := range r.returns {
+		if ret.RequestedBy == userID {
+			returns = append(returns, ret)
+		}
+	}
+	return returns, nil
+}
+
+func TestExportUserData(t *testing.T) {
+	buyer := model.User{ID: 1, Email: "buyer@example.com"}
+	returnID, itemID := int64(3), int64(5)
+	order := model.Order{
+		ID:      10,
+		UserID:  buyer.ID,
+		Status:  model.OrderStatusDelivered,
+		Items:   []model.OrderItem{{ID: itemID, Title: "Lamp", Quantity: 2, ReturnedQuantity: 1}},
+		Refunds: []model.Refund{{ID: 4, OrderID: 10, ReturnID: &returnID, OrderItemID: &itemID, Amount: 1500}},
+	}
+	ret := model.OrderReturn{ID: returnID, OrderID: 10, OrderItemID: itemID, RequestedBy: buyer.ID, Quantity: 1}
+
+	orderRepo := &fakeOrderRepo{
+		orders:  []model.Order{order, {ID: 11, UserID: 2}},
+		returns: []model.OrderReturn{ret, {ID: 6, OrderID: 11, RequestedBy: 2}},
+	}
+	userRepo := &fakeUserRepo{user: buyer}
+	svc := NewAccountService(userRepo, &fakeAccountProductRepo{}, orderRepo, nil, config.AuthConfig{},
+		config.AccountConfig{})
+
+	export, err := svc.ExportUserData(context.Background(), buyer)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []model.Order{order}, export.Orders)
+	assert.Equal(t, []model.OrderReturn{ret}, export.Returns)
+	assert.NotNil(t, export.Products)
+	assert.NotNil(t, export.Purchases)
+
+	// someone without orders still gets empty lists
+	userRepo.user = model.User{ID: 3, Email: "new@example.com"}
+	export, err = svc.ExportUserData(context.Background(), userRepo.user)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, export.Orders)
+	assert.Empty(t, export.Orders)
+	assert.NotNil(t, export.Returns)
+	assert.Empty(t, export.Returns)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+)
+
+type AdminService interface {
+	ListUsers(ctx context.Context, filter model.UserFilter) ([]model.User, error)
+	BanUser(ctx context.Context, admin model.User, userID int64) error
+	UnbanUser(ctx context.Context, admin model.User, userID int64) error
+	ChangeUserRole(ctx context.Context, admin model.User, userID int64, role string) error
+	UnlockUser(ctx context.Context, userID int64) error
+	GetUserCart(ctx context.Context, userID int64) ([]model.CartItem, error)
+	ListProducts(ctx context.Context, filter model.AdminProductFilter) ([]model.Product, error)
+	DeleteProduct(ctx context.Context, productID int64) error
+	SetProductHidden(ctx context.Context, productID int64, hidden bool) error
+}
+
+type adminService struct {
+	userRepo    repository.UserRepository
+	productRepo repository.ProductRepository
+	tokenRepo   repository.TokenRepository
+	loginRepo   repository.LoginAttemptRepository
+	authCfg     config.AuthConfig
+}
+
+func NewAdminService(userRepo repository.UserRepository, productRepo repository.ProductRepository,
+	tokenRepo repository.TokenRepository, loginRepo repository.LoginAttemptRepository,
+	authCfg config.AuthConfig) AdminService {
+	return &adminService{
+		userRepo:    userRepo,
+		productRepo: productRepo,
+		tokenRepo:   tokenRepo,
+		loginRepo:   loginRepo,
+		authCfg:     authCfg,
+	}
+}
+
+func (s *adminService) ListUsers(ctx context.Context, filter model.UserFilter) ([]model.User, error) {
+	return s.userRepo.ListUsers(ctx, filter)
+}
+
+func (s *adminService) BanUser(ctx context.Context, admin model.User, userID int64) error {
+	if admin.ID == userID {
+		return ErrCannotModifySelf
+	}
+
+	err := s.userRepo.SetUserBanned(ctx, userID, true)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.tokenRepo.RevokeUserTokens(ctx, userID)
+}
+
+func (s *adminService) UnbanUser(ctx context.Context, admin model.User, userID int64) error {
+	err := s.userRepo.SetUserBanned(ctx, userID, false)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+
+	return err
+}
+
+func (s *adminService) ChangeUserRole(ctx context.Context, admin model.User, userID int64, role string) error {
+	if role != model.RoleCustomer && role != model.RoleSeller && role != model.RoleAdmin {
+		return ErrInvalidRole
+	}
+
+	if admin.ID == userID {
+		return ErrCannotModifySelf
+	}
+
+	err := s.userRepo.UpdateUserRole(ctx, userID, role)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Tokens carry the role claim, so the old ones must stop working
+	return s.tokenRepo.RevokeUserTokens(ctx, userID)
+}
+
+// UnlockUser clears the failed login counter and the lockout of the user's email
+func (s *adminService) UnlockUser(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	return s.loginRepo.Reset(ctx, loginEmailSubject(user.Email))
+}
+
+func (s *adminService) GetUserCart(ctx context.Context, userID int64) ([]model.CartItem, error) {
+	_, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	return s.productRepo.GetCart(ctx, userID)
+}
+
+func (s *adminService) ListProducts(ctx context.Context, filter model.AdminProductFilter) ([]model.Product, error) {
+	return s.productRepo.ListAllProducts(ctx, filter)
+}
+
+func (s *adminService) DeleteProduct(ctx context.Context, productID int64) error {
+	err := s.productRepo.DeleteProduct(ctx, productID)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return ErrProductNotFound
+	}
+
+	return err
+}
+
+func (s *adminService) SetProductHidden(ctx context.Context, productID int64, hidden bool) error {
+	err := s.productRepo.SetProductHidden(ctx, productID, hidden)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return ErrProductNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+)
+
+const (
+	apiKeyPrefix = "hb_"
+	// apiKeyShownLength is how much of a key is stored in clear to tell keys apart
+	apiKeyShownLength = len(apiKeyPrefix) + 8
+)
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, user model.User, req model.CreateAPIKeyRequest) (*model.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context, user model.User) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, user model.User, keyID int64) error
+	AuthenticateAPIKey(ctx context.Context, key string) (*model.User, []string, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository) APIKeyService {
+	return &apiKeyService{apiKeyRepo: apiKeyRepo, userRepo: userRepo}
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, user model.User,
+	req model.CreateAPIKeyRequest) (*model.CreatedAPIKey, error) {
+
+	if len(req.Scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !slices.Contains(model.APIKeyScopes, scope) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	secret, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := apiKeyPrefix + secret
+
+	created, err := s.apiKeyRepo.CreateAPIKey(ctx, model.APIKey{
+		UserID: user.ID,
+		Name:   req.Name,
+		Prefix: key[:apiKeyShownLength],
+		Scopes: scopes,
+	}, utils.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIKey{APIKey: *created, Key: key}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, user model.User) ([]model.APIKey, error) {
+	return s.apiKeyRepo.ListAPIKeys(ctx, user.ID)
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, user model.User, keyID int64) error {
+	err := s.apiKeyRepo.RevokeAPIKey(ctx, user.ID, keyID)
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return ErrAPIKeyNotFound
+	}
+
+	return err
+}
+
+// AuthenticateAPIKey returns the owner of an active key and the scopes granted to it
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*model.User, []string, error) {
+	apiKey, err := s.apiKeyRepo.UseAPIKey(ctx, utils.HashToken(key))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, apiKey.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user details: %w", err)
+	}
+
+	// Keys die with the account, and stop working while it is banned
+	if user.BannedAt != nil || user.DeletedAt != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	return user, apiKey.Scopes, nil
+}
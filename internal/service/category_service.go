@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+)
+
+const (
+	maxCategoryNameLength = 100
+
+	maxAttributeCodeLength  = 50
+	maxAttributeUnitLength  = 20
+	maxAttributeEnumValues  = 100
+	maxAttributeValueLength = 100
+)
+
+var (
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// Attribute codes are used as filter names, e.g. ram_gb>=16
+	attributeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+type CategoryService interface {
+	GetCategoryTree(ctx context.Context) ([]model.Category, error)
+	CreateCategory(ctx context.Context, req model.CategoryRequest) (*model.Category, error)
+	UpdateCategory(ctx context.Context, id int64, req model.CategoryRequest) (*model.Category, error)
+	DeleteCategory(ctx context.Context, id int64) error
+	ListAttributes(ctx context.Context, categoryID int64) ([]model.AttributeDefinition, error)
+	CreateAttribute(ctx context.Context, categoryID int64,
+		req model.AttributeDefinitionRequest) (*model.AttributeDefinition, error)
+	UpdateAttribute(ctx context.Context, categoryID, attributeID int64,
+		req model.AttributeDefinitionRequest) (*model.AttributeDefinition, error)
+	DeleteAttribute(ctx context.Context, categoryID, attributeID int64) error
+}
+
+type categoryService struct {
+	repo          repository.CategoryRepository
+	attributeRepo repository.AttributeRepository
+}
+
+func NewCategoryService(repo repository.CategoryRepository, attributeRepo repository.AttributeRepository) CategoryService {
+	return &categoryService{repo: repo, attributeRepo: attributeRepo}
+}
+
+// GetCategoryTree returns the top level categories with their subcategories nested, ordered by name
+func (s *categoryService) GetCategoryTree(ctx context.Context) ([]model.Category, error) {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+func (s *categoryService) CreateCategory(ctx context.Context, req model.CategoryRequest) (*model.Category, error) {
+	category, err := categoryFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.CreateCategory(ctx, category)
+	if err != nil {
+		return nil, mapCategoryError(err)
+	}
+	category.ID = id
+
+	return &category, nil
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, id int64,
+	req model.CategoryRequest) (*model.Category, error) {
+
+	category, err := categoryFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	category.ID = id
+
+	if category.ParentID != nil {
+		categories, err := s.repo.ListCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Moving a category under itself or one of its subcategories would detach a cycle from the tree
+		if *category.ParentID == id || isDescendant(categories, *category.ParentID, id) {
+			return nil, ErrCategoryCycle
+		}
+	}
+
+	if err := s.repo.UpdateCategory(ctx, category); err != nil {
+		return nil, mapCategoryError(err)
+	}
+
+	return &category, nil
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, id int64) error {
+	return mapCategoryError(s.repo.DeleteCategory(ctx, id))
+}
+
+// ListAttributes returns the attribute schema of the category, including the inherited attributes
+func (s *categoryService) ListAttributes(ctx context.Context, categoryID int64) ([]model.AttributeDefinition, error) {
+	if _, err := s.repo.GetCategory(ctx, categoryID); err != nil {
+		return nil, mapCategoryError(err)
+	}
+
+	attrs, err := s.attributeRepo.ListAttributes(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if attrs == nil {
+		attrs = []model.AttributeDefinition{}
+	}
+
+	return attrs, nil
+}
+
+func (s *categoryService) CreateAttribute(ctx context.Context, categoryID int64,
+	req model.AttributeDefinitionRequest) (*model.AttributeDefinition, error) {
+
+	attr, err := attributeFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	attr.CategoryID = categoryID
+
+	id, err := s.attributeRepo.CreateAttribute(ctx, attr)
+	if err != nil {
+		return nil, mapCategoryError(err)
+	}
+	attr.ID = id
+
+	return &attr, nil
+}
+
+func (s *categoryService) UpdateAttribute(ctx context.Context, categoryID, attributeID int64,
+	req model.AttributeDefinitionRequest) (*model.AttributeDefinition, error) {
+
+	attr, err := attributeFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	attr.ID = attributeID
+	attr.CategoryID = categoryID
+
+	if err := s.attributeRepo.UpdateAttribute(ctx, attr); err != nil {
+		return nil, mapCategoryError(err)
+	}
+
+	return &attr, nil
+}
+
+// DeleteAttribute removes the attribute from the schema. Products keep their values of it
+// until they are next updated.
+func (s *categoryService) DeleteAttribute(ctx context.Context, categoryID, attributeID int64) error {
+	return mapCategoryError(s.attributeRepo.DeleteAttribute(ctx, categoryID, attributeID))
+}
+
+func categoryFromRequest(req model.CategoryRequest) (model.Category, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxCategoryNameLength {
+		return model.Category{}, ErrInvalidCategory
+	}
+
+	slug := req.Slug
+	if slug == "" {
+		slug = slugify(name)
+	}
+	if !slugPattern.MatchString(slug) || len(slug) > maxCategoryNameLength {
+		return model.Category{}, ErrInvalidCategory
+	}
+
+	return model.Category{ParentID: req.ParentID, Name: name, Slug: slug}, nil
+}
+
+// attributeFromRequest validates a definition. Only numbers have units and only enums have values.
+func attributeFromRequest(req model.AttributeDefinitionRequest) (model.AttributeDefinition, error) {
+	attr := model.AttributeDefinition{
+		Code:     req.Code,
+		Name:     strings.TrimSpace(req.Name),
+		Type:     req.Type,
+		Unit:     strings.TrimSpace(req.Unit),
+		Values:   []string{},
+		Required: req.Required,
+	}
+
+	if !attributeCodePattern.MatchString(attr.Code) || len(attr.Code) > maxAttributeCodeLength {
+		return model.AttributeDefinition{}, ErrInvalidAttribute
+	}
+	if attr.Name == "" || utf8.RuneCountInString(attr.Name) > maxCategoryNameLength {
+		return model.AttributeDefinition{}, ErrInvalidAttribute
+	}
+	if !slices.Contains(model.AttributeTypes, attr.Type) {
+		return model.AttributeDefinition{}, ErrInvalidAttribute
+	}
+	if attr.Unit != "" && (attr.Type != model.AttributeNumber || len(attr.Unit) > maxAttributeUnitLength) {
+		return model.AttributeDefinition{}, ErrInvalidAttribute
+	}
+
+	if attr.Type != model.AttributeEnum {
+		if len(req.Values) > 0 {
+			return model.AttributeDefinition{}, ErrInvalidAttribute
+		}
+		return attr, nil
+	}
+
+	if len(req.Values) == 0 || len(req.Values) > maxAttributeEnumValues {
+		return model.AttributeDefinition{}, ErrInvalidAttribute
+	}
+	for _, v := range req.Values {
+		v = strings.TrimSpace(v)
+		if v == "" || utf8.RuneCountInString(v) > maxAttributeValueLength || slices.Contains(attr.Values, v) {
+			return model.AttributeDefinition{}, ErrInvalidAttribute
+		}
+		attr.Values = append(attr.Values, v)
+	}
+
+	return attr, nil
+}
+
+// slugify lowercases the name and joins its words with dashes
+func slugify(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// isDescendant reports whether the category id lies below ancestorID
+func isDescendant(categories []model.Category, id, ancestorID int64) bool {
+	parents := make(map[int64]*int64, len(categories))
+	for _, c := range categories {
+		parents[c.ID] = c.ParentID
+	}
+
+	// The walk is bounded by the number of categories in case the stored tree is already broken
+	for i := 0; i < len(categories); i++ {
+		parent := parents[id]
+		if parent == nil {
+			return false
+		}
+		if *parent == ancestorID {
+			return true
+		}
+		id = *parent
+	}
+
+	return false
+}
+
+// buildCategoryTree nests the categories under their parents, keeping the order of the list
+func buildCategoryTree(categories []model.Category) []model.Category {
+	children := make(map[int64][]model.Category)
+	var roots []model.Category
+	for _, c := range categories {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+		} else {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		}
+	}
+
+	var attach func(nodes []model.Category) []model.Category
+	attach = func(nodes []model.Category) []model.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	tree := attach(roots)
+	if tree == nil {
+		tree = []model.Category{}
+	}
+
+	return tree
+}
+
+func mapCategoryError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		return ErrCategoryNotFound
+	case errors.Is(err, repository.ErrCategoryParentNotFound):
+		return ErrUnknownCategory
+	case errors.Is(err, repository.ErrCategoryAlreadyExists):
+		return ErrCategoryAlreadyExists
+	case errors.Is(err, repository.ErrCategoryNotEmpty):
+		return ErrCategoryNotEmpty
+	case errors.Is(err, repository.ErrAttributeNotFound):
+		return ErrAttributeNotFound
+	case errors.Is(err, repository.ErrAttributeAlreadyExists):
+		return ErrAttributeAlreadyExists
+	default:
+		return err
+	}
+}
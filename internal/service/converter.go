@@ -12,5 +12,17 @@ func ConvertRequestToProduct(req model.CreateProductRequest) model.Product {
 		ProductDescription: req.ProductDescription,
 		ProductImage:       req.ProductImage,
 		Price:              req.Price,
+		Amount:             req.Amount,
+		CategoryID:         categoryOrNil(req.CategoryID),
+		Status:             req.Status,
+		PublishAt:          req.PublishAt,
 	}
 }
+
+// categoryOrNil turns the category 0 of a request into no category
+func categoryOrNil(categoryID *int64) *int64 {
+	if categoryID == nil || *categoryID == 0 {
+		return nil
+	}
+	return categoryID
+}
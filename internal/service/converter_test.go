@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+)
+
+func TestConvertRequestToProduct(t *testing.T) {
+	categoryID := int64(3)
+	noCategory := int64(0)
+	publishAt := time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		request  model.CreateProductRequest
+		expected model.Product
+	}{
+		{
+			name: "All fields",
+			request: model.CreateProductRequest{
+				Title:              "Phone",
+				ProductDescription: "A phone",
+				ProductImage:       "https://example.com/phone.png",
+				Price:              1000,
+				Amount:             7,
+				CategoryID:         &categoryID,
+				Status:             model.ProductStatusScheduled,
+				PublishAt:          &publishAt,
+			},
+			expected: model.Product{
+				Title:              "Phone",
+				ProductDescription: "A phone",
+				ProductImage:       "https://example.com/phone.png",
+				Price:              1000,
+				Amount:             7,
+				CategoryID:         &categoryID,
+				Status:             model.ProductStatusScheduled,
+				PublishAt:          &publishAt,
+			},
+		},
+		{
+			name: "Category 0 means no category",
+			request: model.CreateProductRequest{
+				Title:      "Case",
+				Price:      50,
+				Amount:     2,
+				CategoryID: &noCategory,
+			},
+			expected: model.Product{
+				Title:  "Case",
+				Price:  50,
+				Amount: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ConvertRequestToProduct(tt.request))
+		})
+	}
+}
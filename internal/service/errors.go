@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid or expired token")
+	ErrEmailNotVerified = errors.New("email is not verified")
+	ErrForbidden        = errors.New("access denied")
+	ErrProductNotFound  = errors.New("product not found")
+	ErrInvalidSort      = errors.New("invalid sort order")
+	ErrInvalidCursor    = errors.New("invalid or expired cursor")
+
+	ErrInvalidStatus           = errors.New("invalid product status")
+	ErrInvalidStatusTransition = errors.New("product cannot move to this status from its current one")
+	ErrInvalidPublishTime      = errors.New("publish_at must be a future time for scheduled products only")
+
+	ErrInvalidSearchQuery = errors.New("search query must be between 1 and 200 characters")
+
+	ErrVariantNotFound      = errors.New("variant not found")
+	ErrVariantAlreadyExists = errors.New("variant with this sku or options already exists")
+	ErrInvalidVariant       = errors.New("variant needs a valid sku and price, and at most 10 options")
+	ErrVariantRequired      = errors.New("product has variants, one must be chosen")
+	ErrProductHasVariants   = errors.New("price and amount of a product with variants are set on its variants")
+	ErrOutOfStock           = errors.New("product out of stock")
+	ErrAmountBelowReserved  = errors.New("amount cannot be less than the stock reserved by pending orders")
+	ErrProductReserved      = errors.New("stock is reserved by pending orders, try again once they are paid or cancelled")
+
+	ErrCartItemNotFound = errors.New("item is not in the cart")
+	ErrInvalidQuantity  = errors.New("quantity must be between 1 and 99")
+	ErrCartEmpty        = errors.New("cart is empty")
+
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrInvalidOrderStatus     = errors.New("invalid order status")
+	ErrInvalidOrderTransition = errors.New("order cannot move to this status from its current one")
+	ErrInvalidReason          = errors.New("a reason of at most 500 characters is required")
+	ErrReturnNotFound         = errors.New("return not found")
+	ErrReturnNotAllowed       = errors.New("only delivered orders can be returned")
+	ErrReturnResolved         = errors.New("return is already resolved")
+	ErrInvalidReturn          = errors.New("return needs an item of the order and a positive quantity")
+	ErrInvalidReturnStatus    = errors.New("invalid return status")
+	ErrReservationExpired     = errors.New("stock reservation of the order has expired")
+	ErrOrderItemsCancelled    = errors.New("your items of the order are already cancelled")
+
+	ErrImageTooLarge        = errors.New("image is too large")
+	ErrUnsupportedImageType = errors.New("image must be a JPEG, PNG, GIF or WebP file")
+	ErrImageNotFound        = errors.New("image not found")
+	ErrInvalidImage         = errors.New("alt text must be at most 200 characters")
+	ErrInvalidImageOrder    = errors.New("image order must list every image of the product once")
+	ErrTooManyImages        = errors.New("product gallery is full")
+	ErrImageNotInGallery    = errors.New("product_image must be the url of an image of the product gallery")
+
+	ErrUserNotFound     = errors.New("user not found")
+	ErrUserBanned       = errors.New("user is banned")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrCannotModifySelf = errors.New("admins cannot ban or change the role of themselves")
+
+	ErrUserAlreadyExists = errors.New("user with this name or email already exists")
+	ErrWrongPassword     = errors.New("old password is incorrect")
+
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+
+	ErrInvalidScope   = errors.New("invalid api key scope")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid or revoked api key")
+
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrCategoryAlreadyExists = errors.New("category with this slug already exists")
+	ErrCategoryNotEmpty      = errors.New("category has subcategories")
+	ErrCategoryCycle         = errors.New("category cannot be moved under itself")
+	ErrInvalidCategory       = errors.New("category name or slug is invalid")
+	ErrUnknownCategory       = errors.New("referenced category does not exist")
+
+	ErrAttributeNotFound      = errors.New("attribute not found")
+	ErrAttributeAlreadyExists = errors.New("category already has an attribute with this code")
+	ErrInvalidAttribute       = errors.New("attribute needs a lowercase code, a name and a valid type, enums need their values")
+	ErrInvalidAttributes      = errors.New("product attributes do not match the category schema")
+	ErrInvalidAttributeFilter = errors.New("invalid attribute filter")
+
+	ErrUnknownOIDCProvider  = errors.New("unknown identity provider")
+	ErrOIDCLoginFailed      = errors.New("login with the identity provider failed")
+	ErrOIDCEmailNotVerified = errors.New("identity provider did not verify the email")
+)
+
+// LoginLockedError is returned while logins for an email or a client IP are locked
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
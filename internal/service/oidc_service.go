@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/auth"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/oidc"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
+)
+
+// maxUserNameAttempts bounds the tries to find a free user name for a provisioned user
+const maxUserNameAttempts = 5
+
+type OIDCService interface {
+	Providers() []string
+	StartLogin(ctx context.Context, provider string) (authURL, state string, err error)
+	CompleteLogin(ctx context.Context, provider, code, state string) (*model.TokenPair, error)
+}
+
+type oidcService struct {
+	providers    map[string]*oidc.Provider
+	userRepo     repository.UserRepository
+	identityRepo repository.IdentityRepository
+	stateRepo    repository.OIDCStateRepository
+	tokenRepo    repository.TokenRepository
+	sessions     sessionIssuer
+	authCfg      config.AuthConfig
+	oidcCfg      config.OIDCConfig
+}
+
+func NewOIDCService(providers []*oidc.Provider, userRepo repository.UserRepository,
+	identityRepo repository.IdentityRepository, stateRepo repository.OIDCStateRepository,
+	tokenRepo repository.TokenRepository, signer auth.Signer, authCfg config.AuthConfig,
+	oidcCfg config.OIDCConfig) OIDCService {
+
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	return &oidcService{
+		providers:    byName,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		stateRepo:    stateRepo,
+		tokenRepo:    tokenRepo,
+		sessions:     sessionIssuer{tokenRepo: tokenRepo, signer: signer, authCfg: authCfg},
+		authCfg:      authCfg,
+		oidcCfg:      oidcCfg,
+	}
+}
+
+func (s *oidcService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// StartLogin returns the provider address to send the user to and the state the callback must bring back
+func (s *oidcService) StartLogin(ctx context.Context, provider string) (string, string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", ErrUnknownOIDCProvider
+	}
+
+	state, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := utils.GenerateToken(16)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	loginState := model.OIDCLoginState{Provider: provider, CodeVerifier: verifier, Nonce: nonce}
+	if err := s.stateRepo.SaveState(ctx, utils.HashToken(state), loginState, s.oidcCfg.LoginTTL); err != nil {
+		return "", "", err
+	}
+
+	return p.AuthCodeURL(state, nonce, verifier), state, nil
+}
+
+func (s *oidcService) CompleteLogin(ctx context.Context, provider, code, state string) (*model.TokenPair, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	// The state is single use, a replayed callback finds nothing
+	loginState, err := s.stateRepo.ConsumeState(ctx, utils.HashToken(state))
+	if errors.Is(err, repository.ErrTokenNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume login state: %w", err)
+	}
+	if loginState.Provider != provider {
+		return nil, ErrInvalidToken
+	}
+
+	identity, err := p.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCLoginFailed, err)
+	}
+
+	user, err := s.resolveUser(ctx, provider, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.BannedAt != nil {
+		return nil, ErrUserBanned
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrUserNotFound
+	}
+
+	return s.sessions.issueTokens(ctx, user)
+}
+
+// resolveUser finds the user linked to the identity. An unknown identity is linked to the user
+// with the same email, or to a new customer if there is none, but only when the provider
+// has verified the email.
+func (s *oidcService) resolveUser(ctx context.Context, provider string, identity *oidc.Identity) (*model.User, error) {
+	userID, err := s.identityRepo.GetUserIDByIdentity(ctx, provider, identity.Subject)
+	if err == nil {
+		return s.userRepo.GetUserByID(ctx, userID)
+	}
+	if !errors.Is(err, repository.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, identity.Email)
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		user, err = s.provisionUser(ctx, identity)
+	case err == nil:
+		err = s.takeOverUnverifiedUser(ctx, user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.identityRepo.LinkIdentity(ctx, model.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if errors.Is(err, repository.ErrIdentityAlreadyLinked) {
+		// A concurrent callback of the same user linked it first
+		return s.resolveUser(ctx, provider, identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.GetUserByID(ctx, user.ID)
+}
+
+// takeOverUnverifiedUser protects a linked account registered by someone who never proved
+// to own the email: that person could otherwise keep access with the password they chose.
+func (s *oidcService) takeOverUnverifiedUser(ctx context.Context, user *model.User) error {
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	passwordHash, err := unusablePasswordHash()
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.RevokeUserTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return s.userRepo.MarkEmailVerified(ctx, user.ID, user.Email)
+}
+
+// provisionUser creates a customer for the identity. The user has no known password
+// and may set one with a password reset.
+func (s *oidcService) provisionUser(ctx context.Context, identity *oidc.Identity) (*model.User, error) {
+	passwordHash, err := unusablePasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	base := userNameFromIdentity(identity)
+	for attempt := 0; attempt < maxUserNameAttempts; attempt++ {
+		userName := base
+		if attempt > 0 {
+			userName = base + "_" + strings.ToLower(rand.Text()[:6])
+		}
+
+		newUser := model.User{UserName: userName, Email: identity.Email, Role: model.RoleCustomer}
+		userID, err := s.userRepo.CreateUser(ctx, newUser, passwordHash)
+		if errors.Is(err, repository.ErrUserAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.userRepo.MarkEmailVerified(ctx, userID, newUser.Email); err != nil {
+			return nil, err
+		}
+		newUser.ID = userID
+
+		return &newUser, nil
+	}
+
+	return nil, ErrUserAlreadyExists
+}
+
+// userNameFromIdentity derives a user name from the display name or the email of the identity
+func userNameFromIdentity(identity *oidc.Identity) string {
+	source := identity.Name
+	if source == "" {
+		source, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(source) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteRune('_')
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+
+	name := strings.TrimSuffix(b.String(), "_")
+	if len(name) < 3 {
+		return "user"
+	}
+
+	return name
+}
+
+// unusablePasswordHash hashes a random password nobody knows
+func unusablePasswordHash() (string, error) {
+	password, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	return utils.HashPassword(password)
+}
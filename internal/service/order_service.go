@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+)
+
+// orderStatusTransitions lists the statuses an order can be moved to from each status.
+// Delivered and cancelled orders do not move anymore.
+var orderStatusTransitions = map[string][]string{
+	model.OrderStatusPending: {model.OrderStatusPaid, model.OrderStatusCancelled},
+	model.OrderStatusPaid:    {model.OrderStatusShipped, model.OrderStatusCancelled},
+	model.OrderStatusShipped: {model.OrderStatusDelivered},
+}
+
+// maxReasonLength is the longest reason a cancellation or a return can be given
+const maxReasonLength = 500
+
+type OrderService interface {
+	Checkout(ctx context.Context, userID int64) (*model.Order, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error)
+	GetOrder(ctx context.Context, id int64, user model.User) (*model.Order, error)
+	ChangeOrderStatus(ctx context.Context, id int64, req model.OrderStatusRequest, user model.User) (*model.Order, error)
+	CancelOrder(ctx context.Context, id int64, req model.CancelOrderRequest, user model.User) (*model.Order, error)
+	RequestReturn(ctx context.Context, orderID int64, req model.ReturnRequest,
+		user model.User) (*model.OrderReturn, error)
+	ListReturns(ctx context.Context, filter model.ReturnFilter, user model.User) ([]model.OrderReturn, error)
+	AcceptReturn(ctx context.Context, id int64, req model.ResolveReturnRequest,
+		user model.User) (*model.OrderReturn, error)
+	RejectReturn(ctx context.Context, id int64, req model.ResolveReturnRequest,
+		user model.User) (*model.OrderReturn, error)
+	ReleaseExpiredReservations(ctx context.Context) (int64, error)
+}
+
+type orderService struct {
+	repo        repository.OrderRepository
+	productRepo repository.ProductRepository
+	cfg         config.OrderConfig
+}
+
+func NewOrderService(repo repository.OrderRepository, productRepo repository.ProductRepository,
+	cfg config.OrderConfig) OrderService {
+	return &orderService{
+		repo:        repo,
+		productRepo: productRepo,
+		cfg:         cfg,
+	}
+}
+
+// Checkout orders everything in the cart of the user and takes it out of the cart. The stock of all
+// items is reserved in one transaction, so either the whole cart is ordered or nothing is. The order
+// has to be paid before the reservation expires, otherwise it is cancelled and the stock is released.
+func (s *orderService) Checkout(ctx context.Context, userID int64) (*model.Order, error) {
+	items, err := s.productRepo.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	// The order checks the stock again with the rows locked, this only names the item that is short
+	for _, item := range items {
+		if item.Quantity > item.Available {
+			return nil, fmt.Errorf("%w: %s, %d left", ErrOutOfStock, item.Title, item.Available)
+		}
+	}
+
+	order, err := s.repo.CreateOrder(ctx, userID, items, s.cfg.ReservationTTL)
+	if err != nil {
+		return nil, mapVariantError(err)
+	}
+
+	// The order is placed already, a cart that could not be updated is only logged
+	if err := s.productRepo.TakeFromCart(ctx, userID, items); err != nil {
+		log.Printf("failed to take ordered items out of cart of user %d: %v", userID, err)
+	}
+
+	return order, nil
+}
+
+// ListOrders returns the order history of a customer
+func (s *orderService) ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	if filter.Status != "" && !slices.Contains(model.OrderStatuses, filter.Status) {
+		return nil, ErrInvalidOrderStatus
+	}
+
+	orders, err := s.repo.ListOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []model.Order{}
+	}
+
+	return orders, nil
+}
+
+// GetOrder returns an order of the user, or one with items the user sells. Admins can see any order,
+// other orders are reported as not found. A seller only sees their own lines of the order.
+func (s *orderService) GetOrder(ctx context.Context, id int64, user model.User) (*model.Order, error) {
+	order, err := s.getOrder(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role != model.RoleAdmin && order.UserID != user.ID {
+		return sellerView(order, user.ID), nil
+	}
+
+	return order, nil
+}
+
+// getOrder returns the whole order if the user can manage it
+func (s *orderService) getOrder(ctx context.Context, id int64, user model.User) (*model.Order, error) {
+	order, err := s.repo.GetOrder(ctx, id)
+	if errors.Is(err, repository.ErrOrderNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !canManageOrder(order, user) {
+		return nil, ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// canManageOrder allows admins to manage any order, customers their own orders and sellers
+// the orders with their items
+func canManageOrder(order *model.Order, user model.User) bool {
+	if user.Role == model.RoleAdmin || order.UserID == user.ID {
+		return true
+	}
+
+	return slices.ContainsFunc(order.Items, func(item model.OrderItem) bool {
+		return item.SellerID == user.ID
+	})
+}
+
+// sellsWholeOrder reports whether every line of the order is sold by the seller
+func sellsWholeOrder(order *model.Order, sellerID int64) bool {
+	return !slices.ContainsFunc(order.Items, func(item model.OrderItem) bool {
+		return item.SellerID != sellerID
+	})
+}
+
+// sellerView keeps the lines of the seller and the refunds of their returned or cancelled items.
+// The total is the total of these lines that are not cancelled.
+func sellerView(order *model.Order, sellerID int64) *model.Order {
+	if sellsWholeOrder(order, sellerID) {
+		return order
+	}
+
+	view := *order
+	view.Items, view.Refunds, view.Total = nil, nil, 0
+	lines := make(map[int64]bool)
+	for _, item := range order.Items {
+		if item.SellerID == sellerID {
+			view.Items = append(view.Items, item)
+			if item.CancelledAt == nil {
+				view.Total += item.LineTotal
+			}
+			lines[item.ID] = true
+		}
+	}
+	for _, refund := range order.Refunds {
+		if refund.OrderItemID != nil && lines[*refund.OrderItemID] {
+			view.Refunds = append(view.Refunds, refund)
+		}
+	}
+
+	return &view
+}
+
+// ChangeOrderStatus moves the order along orderStatusTransitions and returns it. Paying takes the
+// reserved stock and cancelling goes through CancelOrder, so the stock is given back.
+func (s *orderService) ChangeOrderStatus(ctx context.Context, id int64, req model.OrderStatusRequest,
+	user model.User) (*model.Order, error) {
+
+	if !slices.Contains(model.OrderStatuses, req.Status) {
+		return nil, ErrInvalidOrderStatus
+	}
+
+	order, err := s.repo.GetOrder(ctx, id)
+	if errors.Is(err, repository.ErrOrderNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Status == model.OrderStatusCancelled {
+		return s.cancel(ctx, order, req.Reason, user)
+	}
+	if !slices.Contains(orderStatusTransitions[order.Status], req.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidOrderTransition, order.Status, req.Status)
+	}
+
+	// The status is only changed if nobody changed it since it was read
+	if req.Status == model.OrderStatusPaid {
+		err = s.repo.PayOrder(ctx, id)
+	} else {
+		err = s.repo.SetOrderStatus(ctx, id, req.Status, []string{order.Status})
+	}
+	if errors.Is(err, repository.ErrInvalidOrderTransition) {
+		return nil, fmt.Errorf("%w: order was changed concurrently, try again", ErrInvalidOrderTransition)
+	}
+	if errors.Is(err, repository.ErrReservationExpired) {
+		return nil, ErrReservationExpired
+	}
+	if err != nil {
+		return nil, mapVariantError(err)
+	}
+
+	return s.GetOrder(ctx, id, model.User{Role: model.RoleAdmin})
+}
+
+// CancelOrder cancels an order before it is shipped, its stock is given back and a paid order is refunded.
+// The customer and admins cancel the whole order, and so does a seller of nothing but its items. In an
+// order shared with other sellers a seller cancels only their own lines, the rest goes on.
+func (s *orderService) CancelOrder(ctx context.Context, id int64, req model.CancelOrderRequest,
+	user model.User) (*model.Order, error) {
+
+	order, err := s.getOrder(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != model.RoleAdmin && order.UserID != user.ID && !sellsWholeOrder(order, user.ID) {
+		return s.cancelSellerItems(ctx, order, req.Reason, user)
+	}
+
+	return s.cancel(ctx, order, req.Reason, user)
+}
+
+// cancelSellerItems cancels the lines of the seller, their stock is given back and they are
+// refunded if the order is paid
+func (s *orderService) cancelSellerItems(ctx context.Context, order *model.Order, reason string,
+	user model.User) (*model.Order, error) {
+
+	reason, err := checkReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(orderStatusTransitions[order.Status], model.OrderStatusCancelled) {
+		return nil, fmt.Errorf("%w: %s orders cannot be cancelled", ErrInvalidOrderTransition, order.Status)
+	}
+
+	err = s.repo.CancelSellerItems(ctx, order.ID, user.ID, reason, []string{order.Status})
+	if errors.Is(err, repository.ErrInvalidOrderTransition) {
+		return nil, fmt.Errorf("%w: order was changed concurrently, try again", ErrInvalidOrderTransition)
+	}
+	if errors.Is(err, repository.ErrOrderItemsCancelled) {
+		return nil, ErrOrderItemsCancelled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, order.ID, user)
+}
+
+func (s *orderService) cancel(ctx context.Context, order *model.Order, reason string,
+	user model.User) (*model.Order, error) {
+
+	reason, err := checkReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(orderStatusTransitions[order.Status], model.OrderStatusCancelled) {
+		return nil, fmt.Errorf("%w: %s orders cannot be cancelled", ErrInvalidOrderTransition, order.Status)
+	}
+
+	err = s.repo.CancelOrder(ctx, order.ID, reason, user.ID, []string{order.Status})
+	if errors.Is(err, repository.ErrInvalidOrderTransition) {
+		return nil, fmt.Errorf("%w: order was changed concurrently, try again", ErrInvalidOrderTransition)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, order.ID, model.User{Role: model.RoleAdmin})
+}
+
+// RequestReturn asks the seller to take back some items of a line of a delivered order of the user
+func (s *orderService) RequestReturn(ctx context.Context, orderID int64, req model.ReturnRequest,
+	user model.User) (*model.OrderReturn, error) {
+
+	reason, err := checkReason(req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if req.OrderItemID <= 0 || req.Quantity <= 0 {
+		return nil, ErrInvalidReturn
+	}
+
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if errors.Is(err, repository.ErrOrderNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Only the customer returns items, to the sellers
+	if order.UserID != user.ID {
+		return nil, ErrOrderNotFound
+	}
+	if order.Status != model.OrderStatusDelivered {
+		return nil, ErrReturnNotAllowed
+	}
+
+	ret, err := s.repo.CreateReturn(ctx, model.OrderReturn{
+		OrderID:     orderID,
+		OrderItemID: req.OrderItemID,
+		RequestedBy: user.ID,
+		Quantity:    req.Quantity,
+		Reason:      reason,
+	})
+	if errors.Is(err, repository.ErrOrderNotFound) {
+		return nil, fmt.Errorf("%w: order has no item %d", ErrInvalidReturn, req.OrderItemID)
+	}
+	if errors.Is(err, repository.ErrReturnQuantityExceeded) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReturn, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ListReturns returns the returns the user asked for or has to resolve as a seller, admins see all of them
+func (s *orderService) ListReturns(ctx context.Context, filter model.ReturnFilter,
+	user model.User) ([]model.OrderReturn, error) {
+
+	if filter.Status != "" && !slices.Contains(model.ReturnStatuses, filter.Status) {
+		return nil, ErrInvalidReturnStatus
+	}
+
+	filter.UserID = user.ID
+	if user.Role == model.RoleAdmin {
+		filter.UserID = 0
+	}
+
+	returns, err := s.repo.ListReturns(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if returns == nil {
+		returns = []model.OrderReturn{}
+	}
+
+	return returns, nil
+}
+
+// AcceptReturn takes the items back into stock and refunds them
+func (s *orderService) AcceptReturn(ctx context.Context, id int64, req model.ResolveReturnRequest,
+	user model.User) (*model.OrderReturn, error) {
+
+	return s.resolveReturn(ctx, id, true, req.Note, user)
+}
+
+func (s *orderService) RejectReturn(ctx context.Context, id int64, req model.ResolveReturnRequest,
+	user model.User) (*model.OrderReturn, error) {
+
+	return s.resolveReturn(ctx, id, false, req.Note, user)
+}
+
+// resolveReturn lets the seller of the returned item, or an admin, accept or reject the return
+func (s *orderService) resolveReturn(ctx context.Context, id int64, accept bool, note string,
+	user model.User) (*model.OrderReturn, error) {
+
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxReasonLength {
+		return nil, ErrInvalidReason
+	}
+
+	ret, err := s.repo.GetReturn(ctx, id)
+	if errors.Is(err, repository.ErrReturnNotFound) {
+		return nil, ErrReturnNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != model.RoleAdmin && ret.SellerID != user.ID {
+		return nil, ErrReturnNotFound
+	}
+
+	err = s.repo.ResolveReturn(ctx, id, accept, note)
+	if errors.Is(err, repository.ErrReturnResolved) {
+		return nil, ErrReturnResolved
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetReturn(ctx, id)
+}
+
+// ReleaseExpiredReservations cancels the pending orders that were not paid before their reservation
+// expired and returns how many there were
+func (s *orderService) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	return s.repo.ReleaseExpiredReservations(ctx)
+}
+
+// checkReason requires a reason of at most maxReasonLength characters and returns it trimmed
+func checkReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxReasonLength {
+		return "", ErrInvalidReason
+	}
+
+	return reason, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+)
+
+// fakeOrderRepo keeps the orders and returns of every customer in memory
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	orders  []model.Order
+	returns []model.OrderReturn
+}
+
+func (r *fakeOrderRepo) GetOrder(_ context.Context, id int64) (*model.Order, error) {
+	for _, order := range r.orders {
+		if order.ID == id {
+			order.Items = append([]model.OrderItem{}, order.Items...)
+			return &order, nil
+		}
+	}
+	return nil, repository.ErrOrderNotFound
+}
+
+func (r *fakeOrderRepo) CancelOrder(_ context.Context, id int64, reason string, _ int64, _ []string) error {
+	for i := range r.orders {
+		if r.orders[i].ID == id {
+			r.orders[i].Status, r.orders[i].CancelReason = model.OrderStatusCancelled, reason
+			return nil
+		}
+	}
+	return repository.ErrOrderNotFound
+}
+
+func (r *fakeOrderRepo) CancelSellerItems(_ context.Context, id, sellerID int64, reason string, _ []string) error {
+	for i := range r.orders {
+		if r.orders[i].ID != id {
+			continue
+		}
+		cancelled := false
+		for j, item := range r.orders[i].Items {
+			if item.SellerID == sellerID && item.CancelledAt == nil {
+				now := time.Now()
+				r.orders[i].Items[j].CancelledAt, r.orders[i].Items[j].CancelReason = &now, reason
+				r.orders[i].Total -= item.LineTotal
+				cancelled = true
+			}
+		}
+		if !cancelled {
+			return repository.ErrOrderItemsCancelled
+		}
+		return nil
+	}
+	return repository.ErrOrderNotFound
+}
+
+func TestCancelOrder(t *testing.T) {
+	customer := model.User{ID: 1, Role: model.RoleCustomer}
+	seller := model.User{ID: 7, Role: model.RoleSeller}
+	other := model.User{ID: 8, Role: model.RoleSeller}
+
+	shared := func() model.Order {
+		return model.Order{ID: 10, UserID: customer.ID, Status: model.OrderStatusPaid, Total: 500,
+			Items: []model.OrderItem{
+				{ID: 1, SellerID: seller.ID, Title: "Lamp", Quantity: 1, UnitPrice: 200, LineTotal: 200},
+				{ID: 2, SellerID: other.ID, Title: "Desk", Quantity: 1, UnitPrice: 300, LineTotal: 300},
+			}}
+	}
+
+	tests := []struct {
+		name           string
+		user           model.User
+		status         string
+		expectedStatus string
+		expectedItems  []int64
+		expectedTotal  int64
+		expectedErr    error
+	}{
+		{name: "Customer cancels the whole order", user: customer, status: model.OrderStatusPaid,
+			expectedStatus: model.OrderStatusCancelled, expectedItems: []int64{1, 2}, expectedTotal: 500},
+		{name: "Seller cancels their own lines", user: seller, status: model.OrderStatusPaid,
+			expectedStatus: model.OrderStatusPaid, expectedItems: []int64{1}, expectedTotal: 0},
+		{name: "Shipped order", user: seller, status: model.OrderStatusShipped,
+			expectedErr: ErrInvalidOrderTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := shared()
+			order.Status = tt.status
+			repo := &fakeOrderRepo{orders: []model.Order{order}}
+			svc := NewOrderService(repo, nil, config.OrderConfig{})
+
+			got, err := svc.CancelOrder(context.Background(), 10, model.CancelOrderRequest{Reason: "cannot ship"},
+				tt.user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.expectedStatus, got.Status)
+			assert.Equal(t, tt.expectedTotal, got.Total)
+			var ids []int64
+			for _, item := range got.Items {
+				ids = append(ids, item.ID)
+			}
+			assert.Equal(t, tt.expectedItems, ids)
+
+			// The other seller keeps their line, and the customer pays for it alone
+			if tt.user == seller {
+				assert.NotNil(t, repo.orders[0].Items[0].CancelledAt)
+				assert.Nil(t, repo.orders[0].Items[1].CancelledAt)
+				assert.Equal(t, int64(300), repo.orders[0].Total)
+
+				_, err = svc.CancelOrder(context.Background(), 10, model.CancelOrderRequest{Reason: "again"},
+					tt.user)
+				assert.ErrorIs(t, err, ErrOrderItemsCancelled)
+			}
+		})
+	}
+}
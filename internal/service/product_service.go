@@ -1,64 +1,311 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/storage"
 )
 
+const (
+	// maxSearchQueryLength bounds the search text, in characters
+	maxSearchQueryLength = 200
+
+	maxVariantOptions      = 10
+	maxVariantOptionLength = 50
+
+	maxProductImages = 20
+	maxAltTextLength = 200
+
+	maxAttributeFilters = 10
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// productStatusTransitions lists the statuses a product can be moved to from each status.
+// Scheduled products are also published by PublishScheduledProducts when their time comes.
+var productStatusTransitions = map[string][]string{
+	model.ProductStatusDraft:     {model.ProductStatusScheduled, model.ProductStatusPublished, model.ProductStatusArchived},
+	model.ProductStatusScheduled: {model.ProductStatusDraft, model.ProductStatusPublished, model.ProductStatusArchived},
+	model.ProductStatusPublished: {model.ProductStatusDraft, model.ProductStatusArchived},
+	model.ProductStatusArchived:  {model.ProductStatusDraft, model.ProductStatusPublished},
+}
+
+// priceBucketBounds split the price facet into buckets: under 1000, 1000 to 5000 and so on up to 100000 and over
+var priceBucketBounds = []int64{1000, 5000, 10000, 50000, 100000}
+
+// imageExtensions lists the accepted image types with the extension they are stored with
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
 type ProductService interface {
-	GetAllProducts(ctx context.Context) ([]model.Product, error)
+	ListProducts(ctx context.Context, filter model.ProductFilter) (*model.ProductPage, error)
+	SearchProducts(ctx context.Context, filter model.ProductFilter) (*model.ProductSearchPage, error)
 	GetProductByID(ctx context.Context, id int64) (*model.Product, error)
 	CreateProduct(ctx context.Context, ProductReq model.CreateProductRequest, seller model.User) (int64, error)
-	UpdateProduct(ctx context.Context, productReq model.UpdateProductRequest, productID, userID int64) (int64, error)
-	DeleteProduct(ctx context.Context, id int64) error
-	AddToCart(ctx context.Context, productID, userID int64) error
-	BuyProduct(ctx context.Context, productID, userID int64) error
+	UpdateProduct(ctx context.Context, productReq model.UpdateProductRequest, productID int64, user model.User) (int64, error)
+	DeleteProduct(ctx context.Context, id int64, user model.User) error
+	RestoreProduct(ctx context.Context, id int64, user model.User) error
+	GetCart(ctx context.Context, userID int64) (*model.Cart, error)
+	AddToCart(ctx context.Context, productID, variantID, userID int64, quantity int) (*model.Cart, error)
+	UpdateCartItem(ctx context.Context, productID, variantID, userID int64, quantity int) (*model.Cart, error)
+	RemoveFromCart(ctx context.Context, productID, variantID, userID int64) (*model.Cart, error)
+	ClearCart(ctx context.Context, userID int64) error
+	BuyProduct(ctx context.Context, productID, variantID, userID int64) error
+	CreateVariant(ctx context.Context, productID int64, req model.VariantRequest,
+		user model.User) (*model.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productID, variantID int64, req model.VariantRequest,
+		user model.User) (*model.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID, variantID int64, user model.User) error
+	UploadProductImage(ctx context.Context, productID int64, upload model.ImageUpload,
+		user model.User) (*model.ProductImage, error)
+	UpdateProductImage(ctx context.Context, productID, imageID int64, req model.UpdateImageRequest,
+		user model.User) (*model.ProductImage, error)
+	ReorderProductImages(ctx context.Context, productID int64, imageIDs []int64,
+		user model.User) ([]model.ProductImage, error)
+	DeleteProductImage(ctx context.Context, productID, imageID int64, user model.User) error
+	ListSellerProducts(ctx context.Context, filter model.SellerProductFilter) ([]model.Product, error)
+	ChangeProductStatus(ctx context.Context, productID int64, req model.ProductStatusRequest,
+		user model.User) (*model.ProductStatusRequest, error)
+	PublishScheduledProducts(ctx context.Context) (int64, error)
+	PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int64, error)
 }
 
 type productService struct {
-	repo repository.ProductRepository
+	repo          repository.ProductRepository
+	categoryRepo  repository.CategoryRepository
+	variantRepo   repository.VariantRepository
+	imageRepo     repository.ImageRepository
+	attributeRepo repository.AttributeRepository
+	blobs         storage.BlobStorage
+	storageCfg    config.StorageConfig
+	cartCfg       config.CartConfig
 }
 
-func NewProductService(repo repository.ProductRepository) ProductService {
-	return &productService{repo: repo}
+func NewProductService(repo repository.ProductRepository, categoryRepo repository.CategoryRepository,
+	variantRepo repository.VariantRepository, imageRepo repository.ImageRepository,
+	attributeRepo repository.AttributeRepository, blobs storage.BlobStorage,
+	storageCfg config.StorageConfig, cartCfg config.CartConfig) ProductService {
+
+	return &productService{
+		repo:          repo,
+		categoryRepo:  categoryRepo,
+		variantRepo:   variantRepo,
+		imageRepo:     imageRepo,
+		attributeRepo: attributeRepo,
+		blobs:         blobs,
+		storageCfg:    storageCfg,
+		cartCfg:       cartCfg,
+	}
 }
 
-func (s *productService) GetAllProducts(ctx context.Context) ([]model.Product, error) {
-	return s.repo.GetAllProducts(ctx)
+// ListProducts returns a page of the catalog. The next page is read with the returned cursor
+// and the same filter; a cursor is only valid for the sort order it was issued for.
+func (s *productService) ListProducts(ctx context.Context, filter model.ProductFilter) (*model.ProductPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = model.ProductSortNewest
+	}
+	if !slices.Contains(model.ProductSorts, filter.Sort) {
+		return nil, ErrInvalidSort
+	}
+	if err := validateAttributeFilters(filter.Attributes); err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodeProductCursor(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra product tells whether there is a next page
+	pageFilter := filter
+	pageFilter.Limit = filter.Limit + 1
+	products, err := s.repo.ListProducts(ctx, pageFilter, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.repo.CountProducts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.ProductPage{Items: products, Total: total, Limit: filter.Limit}
+	if len(products) > filter.Limit {
+		page.Items = products[:filter.Limit]
+		next := encodeProductCursor(productCursor(filter.Sort, page.Items[len(page.Items)-1]))
+		page.NextCursor = &next
+	}
+	if page.Items == nil {
+		page.Items = []model.Product{}
+	}
+
+	ids := make([]int64, len(page.Items))
+	for i, p := range page.Items {
+		ids[i] = p.ID
+	}
+	galleries, err := s.imageRepo.ListImages(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Items {
+		page.Items[i].Images = galleries[page.Items[i].ID]
+	}
+
+	if filter.Facets {
+		if page.Facets, err = s.repo.CountFacets(ctx, filter, priceBucketBounds); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
 }
 
+// SearchProducts returns a page of the products matching filter.Query, the most relevant first
+// unless another sort is asked for. Pages are read like those of ListProducts.
+func (s *productService) SearchProducts(ctx context.Context, filter model.ProductFilter) (*model.ProductSearchPage, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" || utf8.RuneCountInString(filter.Query) > maxSearchQueryLength {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = model.ProductSortRelevance
+	}
+	if !slices.Contains(model.ProductSearchSorts, filter.Sort) {
+		return nil, ErrInvalidSort
+	}
+	if err := validateAttributeFilters(filter.Attributes); err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodeProductCursor(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pageFilter := filter
+	pageFilter.Limit = filter.Limit + 1
+	hits, err := s.repo.SearchProducts(ctx, pageFilter, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.repo.CountProducts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.ProductSearchPage{Items: hits, Total: total, Limit: filter.Limit}
+	if len(hits) > filter.Limit {
+		page.Items = hits[:filter.Limit]
+		last := page.Items[len(page.Items)-1]
+		c := productCursor(filter.Sort, last.Product)
+		c.Rank = last.Rank
+		next := encodeProductCursor(c)
+		page.NextCursor = &next
+	}
+	if page.Items == nil {
+		page.Items = []model.ProductSearchHit{}
+	}
+
+	ids := make([]int64, len(page.Items))
+	for i, h := range page.Items {
+		ids[i] = h.ID
+	}
+	galleries, err := s.imageRepo.ListImages(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Items {
+		page.Items[i].Images = galleries[page.Items[i].ID]
+	}
+
+	if filter.Facets {
+		if page.Facets, err = s.repo.CountFacets(ctx, filter, priceBucketBounds); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// GetProductByID returns the product with its variants and gallery, or nil if there is no visible product with the id
 func (s *productService) GetProductByID(ctx context.Context, id int64) (*model.Product, error) {
-	return s.repo.GetProductByID(ctx, id)
+	product, err := s.repo.GetProductByID(ctx, id)
+	if err != nil || product == nil {
+		return product, err
+	}
+
+	product.Variants, err = s.variantRepo.ListVariants(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	galleries, err := s.imageRepo.ListImages(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	product.Images = galleries[id]
+
+	return product, nil
 }
 
 func (s *productService) CreateProduct(ctx context.Context, ProductReq model.CreateProductRequest, seller model.User) (int64, error) {
 
+	if err := s.checkCategory(ctx, ProductReq.CategoryID); err != nil {
+		return -1, err
+	}
+
 	newProduct := ConvertRequestToProduct(ProductReq)
+	attrs, err := s.checkAttributes(ctx, newProduct.CategoryID, ProductReq.Attributes)
+	if err != nil {
+		return -1, err
+	}
+	newProduct.Attributes = attrs
+
+	// New listings are live unless the seller prepares them as drafts or schedules them
+	if newProduct.Status == "" {
+		newProduct.Status = model.ProductStatusPublished
+	}
+	if newProduct.Status == model.ProductStatusArchived {
+		return -1, ErrInvalidStatus
+	}
+	if newProduct.PublishAt, err = checkPublishAt(newProduct.Status, newProduct.PublishAt); err != nil {
+		return -1, err
+	}
+
 	newProduct.SellerID = seller.ID
 	newProduct.SellerName = seller.UserName
 	return s.repo.CreateProduct(ctx, newProduct)
 }
 
 func (s *productService) UpdateProduct(ctx context.Context, productReq model.UpdateProductRequest,
-	productID, userID int64) (int64, error) {
+	productID int64, user model.User) (int64, error) {
 
-	existingProduct, err := s.repo.GetProductByID(ctx, productID)
-	if err != nil {
-		return -1, fmt.Errorf("error getting product data: %w", err)
-	}
-
-	if existingProduct == nil {
-		return -1, fmt.Errorf("product does not exist")
-	}
-
-	if existingProduct.SellerID != userID {
-		return -1, errors.New("seller ID and product ID does not match")
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return -1, err
 	}
 
 	query := "UPDATE products SET "
@@ -79,10 +326,15 @@ func (s *productService) UpdateProduct(ctx context.Context, productReq model.Upd
 		paramCount++
 	}
 
-	if productReq.ProductImage != "" {
-		updates = append(updates, fmt.Sprintf("product_image = $%d", paramCount))
-		params = append(params, productReq.ProductImage)
-		paramCount++
+	if productReq.Price != 0 || productReq.Amount != 0 {
+		// The price and the stock of a product with variants follow its variants
+		variants, err := s.variantRepo.ListVariants(ctx, productID)
+		if err != nil {
+			return -1, err
+		}
+		if len(variants) > 0 {
+			return -1, ErrProductHasVariants
+		}
 	}
 
 	if productReq.Price != 0 {
@@ -97,33 +349,818 @@ func (s *productService) UpdateProduct(ctx context.Context, productReq model.Upd
 		paramCount++
 	}
 
-	if len(updates) == 0 {
+	if productReq.CategoryID != nil {
+		if err := s.checkCategory(ctx, productReq.CategoryID); err != nil {
+			return -1, err
+		}
+
+		// 0 removes the product from its category
+		var categoryID *int64
+		if *productReq.CategoryID != 0 {
+			categoryID = productReq.CategoryID
+		}
+		updates = append(updates, fmt.Sprintf("category_id = $%d", paramCount))
+		params = append(params, categoryID)
+		paramCount++
+	}
+
+	// New attributes, or the current ones when the category changes, must fit the schema of the category
+	if productReq.Attributes != nil || productReq.CategoryID != nil {
+		categoryID, attrs, err := s.repo.GetProductAttributes(ctx, productID)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return -1, ErrProductNotFound
+		}
+		if err != nil {
+			return -1, err
+		}
+		if productReq.CategoryID != nil {
+			categoryID = categoryOrNil(productReq.CategoryID)
+		}
+		if productReq.Attributes != nil {
+			attrs = productReq.Attributes
+		}
+
+		attrs, err = s.checkAttributes(ctx, categoryID, attrs)
+		if err != nil {
+			return -1, err
+		}
+		updates = append(updates, fmt.Sprintf("attributes = $%d", paramCount))
+		params = append(params, attrs)
+		paramCount++
+	}
+
+	if len(updates) == 0 && productReq.ProductImage == "" {
 		return -1, errors.New("nothing to update")
 	}
 
-	query += strings.Join(updates, ", ")
-	query += ", updated_at = NOW() WHERE id = $" + strconv.Itoa(paramCount)
-	params = append(params, productID)
+	// product_image picks the primary image out of the gallery, new images are uploaded to the gallery
+	var primary *model.ProductImage
+	if productReq.ProductImage != "" {
+		galleries, err := s.imageRepo.ListImages(ctx, []int64{productID})
+		if err != nil {
+			return -1, err
+		}
+		for _, image := range galleries[productID] {
+			if image.URL == productReq.ProductImage {
+				primary = &image
+				primary.Primary = true
+				break
+			}
+		}
+		if primary == nil {
+			return -1, ErrImageNotInGallery
+		}
+	}
+
+	id := productID
+	if len(updates) > 0 {
+		query += strings.Join(updates, ", ")
+		query += ", updated_at = NOW() WHERE deleted_at IS NULL AND id = $" + strconv.Itoa(paramCount)
+		params = append(params, productID)
+
+		query += " RETURNING id;"
+
+		var err error
+		id, err = s.repo.UpdateProduct(ctx, query, params)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return -1, ErrProductNotFound
+		}
+		if errors.Is(err, repository.ErrAmountBelowReserved) {
+			return -1, ErrAmountBelowReserved
+		}
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	if primary != nil {
+		if err := s.imageRepo.UpdateImage(ctx, *primary); err != nil {
+			return -1, mapImageError(err)
+		}
+	}
+
+	return id, nil
+}
+
+func (s *productService) DeleteProduct(ctx context.Context, id int64, user model.User) error {
+	if err := s.checkOwnership(ctx, id, user); err != nil {
+		return err
+	}
+
+	err := s.repo.DeleteProduct(ctx, id)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return ErrProductNotFound
+	}
+
+	return err
+}
+
+// RestoreProduct brings back a deleted product. Admins can restore any product, sellers only
+// their own; the products of other sellers are reported as not found.
+func (s *productService) RestoreProduct(ctx context.Context, id int64, user model.User) error {
+	var sellerID int64
+	if user.Role != model.RoleAdmin {
+		sellerID = user.ID
+	}
+
+	err := s.repo.RestoreProduct(ctx, id, sellerID)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return ErrProductNotFound
+	}
+
+	return err
+}
+
+// PurgeDeletedProducts removes the products deleted longer than retention ago and their images,
+// it runs as a background job
+func (s *productService) PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int64, error) {
+	purged, urls, err := s.repo.PurgeDeletedProducts(ctx, retention)
+	if err != nil {
+		return 0, err
+	}
+
+	// The products are gone already, an image left in the storage is only logged
+	for _, url := range urls {
+		if key, ok := s.blobs.KeyForURL(url); ok {
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				log.Printf("failed to delete image %s: %v", key, err)
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+// checkCategory makes sure a product is not assigned to a category that does not exist.
+// Both nil and 0 mean no category.
+func (s *productService) checkCategory(ctx context.Context, categoryID *int64) error {
+	if categoryID == nil || *categoryID == 0 {
+		return nil
+	}
+
+	_, err := s.categoryRepo.GetCategory(ctx, *categoryID)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return ErrUnknownCategory
+	}
+
+	return err
+}
+
+// ListSellerProducts returns the products of a seller in any status, for the seller's own views
+func (s *productService) ListSellerProducts(ctx context.Context,
+	filter model.SellerProductFilter) ([]model.Product, error) {
+
+	if filter.Status != "" && !slices.Contains(model.ProductStatuses, filter.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	products, err := s.repo.ListSellerProducts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []model.Product{}
+	}
+
+	return products, nil
+}
+
+// ChangeProductStatus moves the product along productStatusTransitions and returns its new status
+func (s *productService) ChangeProductStatus(ctx context.Context, productID int64, req model.ProductStatusRequest,
+	user model.User) (*model.ProductStatusRequest, error) {
+
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(model.ProductStatuses, req.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	publishAt, err := checkPublishAt(req.Status, req.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var from []string
+	for status, to := range productStatusTransitions {
+		if slices.Contains(to, req.Status) {
+			from = append(from, status)
+		}
+	}
+	// A scheduled product can be rescheduled
+	if req.Status == model.ProductStatusScheduled {
+		from = append(from, model.ProductStatusScheduled)
+	}
+
+	err = s.repo.SetProductStatus(ctx, productID, req.Status, publishAt, from)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return nil, ErrProductNotFound
+	}
+	if errors.Is(err, repository.ErrInvalidStatusTransition) {
+		return nil, ErrInvalidStatusTransition
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ProductStatusRequest{Status: req.Status, PublishAt: publishAt}, nil
+}
+
+// PublishScheduledProducts publishes the scheduled products that are due, it runs as a background job
+func (s *productService) PublishScheduledProducts(ctx context.Context) (int64, error) {
+	return s.repo.PublishScheduled(ctx)
+}
+
+// checkPublishAt requires a time in the future for a scheduled product and none for other statuses.
+// The time is returned in UTC, as timestamps are stored.
+func checkPublishAt(status string, publishAt *time.Time) (*time.Time, error) {
+	if status != model.ProductStatusScheduled {
+		if publishAt != nil {
+			return nil, ErrInvalidPublishTime
+		}
+		return nil, nil
+	}
+
+	if publishAt == nil || !publishAt.After(time.Now()) {
+		return nil, ErrInvalidPublishTime
+	}
+	utc := publishAt.UTC()
+
+	return &utc, nil
+}
 
-	query += " RETURNING id;"
+// checkAttributes validates the attributes against the schema of the category and returns them with
+// strings trimmed. A product without a category has no attributes.
+func (s *productService) checkAttributes(ctx context.Context, categoryID *int64,
+	attrs model.ProductAttributes) (model.ProductAttributes, error) {
 
-	return s.repo.UpdateProduct(ctx, query, params)
+	var schema []model.AttributeDefinition
+	if categoryID != nil {
+		var err error
+		schema, err = s.attributeRepo.ListAttributes(ctx, *categoryID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return validateAttributes(schema, attrs)
 }
 
-func (s *productService) DeleteProduct(ctx context.Context, id int64) error {
-	return s.repo.DeleteProduct(ctx, id)
+func validateAttributes(schema []model.AttributeDefinition,
+	attrs model.ProductAttributes) (model.ProductAttributes, error) {
+
+	defs := make(map[string]model.AttributeDefinition, len(schema))
+	for _, def := range schema {
+		defs[def.Code] = def
+	}
+
+	valid := make(model.ProductAttributes, len(attrs))
+	for code, value := range attrs {
+		def, ok := defs[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown attribute %q", ErrInvalidAttributes, code)
+		}
+		v, ok := attributeValue(def, value)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid value of %q", ErrInvalidAttributes, code)
+		}
+		valid[code] = v
+	}
+
+	for _, def := range schema {
+		if _, ok := valid[def.Code]; def.Required && !ok {
+			return nil, fmt.Errorf("%w: %q is required", ErrInvalidAttributes, def.Code)
+		}
+	}
+
+	return valid, nil
 }
 
-func (s *productService) AddToCart(ctx context.Context, productID, userID int64) error {
-	return s.repo.AddToCart(ctx, productID, userID)
+// attributeValue checks the type of a value decoded from JSON
+func attributeValue(def model.AttributeDefinition, value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if def.Type == model.AttributeEnum {
+			return v, slices.Contains(def.Values, v)
+		}
+		return v, def.Type == model.AttributeString && v != "" && utf8.RuneCountInString(v) <= maxAttributeValueLength
+	case float64:
+		return v, def.Type == model.AttributeNumber
+	case bool:
+		return v, def.Type == model.AttributeBoolean
+	default:
+		return nil, false
+	}
 }
 
-func (s *productService) BuyProduct(ctx context.Context, productID, userID int64) error {
-	err := s.repo.CheckCart(ctx, productID, userID)
+// validateAttributeFilters accepts comparisons of numbers only, equality applies to any value
+func validateAttributeFilters(filters []model.AttributeFilter) error {
+	if len(filters) > maxAttributeFilters {
+		return ErrInvalidAttributeFilter
+	}
+
+	for _, f := range filters {
+		if !attributeCodePattern.MatchString(f.Code) || len(f.Code) > maxAttributeCodeLength {
+			return ErrInvalidAttributeFilter
+		}
+		if f.Value == "" || utf8.RuneCountInString(f.Value) > maxAttributeValueLength {
+			return ErrInvalidAttributeFilter
+		}
 
+		switch f.Op {
+		case model.AttributeOpEq, model.AttributeOpNe:
+		case model.AttributeOpGt, model.AttributeOpGte, model.AttributeOpLt, model.AttributeOpLte:
+			n, err := strconv.ParseFloat(f.Value, 64)
+			if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+				return ErrInvalidAttributeFilter
+			}
+		default:
+			return ErrInvalidAttributeFilter
+		}
+	}
+
+	return nil
+}
+
+// checkOwnership allows admins to manage any product and sellers only their own
+func (s *productService) checkOwnership(ctx context.Context, productID int64, user model.User) error {
+	// Admins can manage any product, but it still has to exist
+	sellerID, err := s.repo.CheckAccess(ctx, productID)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return ErrProductNotFound
+	}
 	if err != nil {
+		return fmt.Errorf("error checking product access: %w", err)
+	}
+
+	if user.Role != model.RoleAdmin && sellerID != user.ID {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// maxCartQuantity is the most items of one product or variant a cart can hold
+const maxCartQuantity = 99
+
+// GetCart returns the cart of the user with the line and cart totals at the current prices
+func (s *productService) GetCart(ctx context.Context, userID int64) (*model.Cart, error) {
+	items, err := s.repo.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart := &model.Cart{Items: []model.CartItem{}}
+	for _, item := range items {
+		cart.Items = append(cart.Items, item)
+		cart.TotalQuantity += item.Quantity
+		cart.Total += item.LineTotal
+	}
+
+	return cart, nil
+}
+
+// AddToCart adds quantity items of the product, or of its variant when variantID is set, to the cart
+func (s *productService) AddToCart(ctx context.Context, productID, variantID, userID int64,
+	quantity int) (*model.Cart, error) {
+
+	if quantity <= 0 || quantity > maxCartQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	available, err := s.availableStock(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if available <= 0 {
+		return nil, ErrOutOfStock
+	}
+
+	// The quantity is added in one step, so concurrent additions cannot overwrite each other.
+	// Only this addition is taken back when the total is too much.
+	total, err := s.repo.AddCartQuantity(ctx, userID, productID, variantID, quantity, s.cartCfg.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if total > maxCartQuantity || total > available {
+		if _, err := s.repo.AddCartQuantity(ctx, userID, productID, variantID, -quantity, s.cartCfg.TTL); err != nil {
+			return nil, err
+		}
+		if total > maxCartQuantity {
+			return nil, ErrInvalidQuantity
+		}
+		return nil, fmt.Errorf("%w: only %d left", ErrOutOfStock, available)
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// UpdateCartItem sets the quantity of an item already in the cart, 0 removes it
+func (s *productService) UpdateCartItem(ctx context.Context, productID, variantID, userID int64,
+	quantity int) (*model.Cart, error) {
+
+	if quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if quantity == 0 {
+		return s.RemoveFromCart(ctx, productID, variantID, userID)
+	}
+
+	current, err := s.repo.GetCartQuantity(ctx, userID, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, ErrCartItemNotFound
+	}
+
+	return s.setCartQuantity(ctx, productID, variantID, userID, quantity)
+}
+
+func (s *productService) RemoveFromCart(ctx context.Context, productID, variantID, userID int64) (*model.Cart, error) {
+	err := s.repo.RemoveFromCart(ctx, userID, productID, variantID)
+	if errors.Is(err, repository.ErrCartItemNotFound) {
+		return nil, ErrCartItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+func (s *productService) ClearCart(ctx context.Context, userID int64) error {
+	return s.repo.ClearCart(ctx, userID)
+}
+
+// setCartQuantity puts quantity items in the cart if that many are in stock and returns the cart
+func (s *productService) setCartQuantity(ctx context.Context, productID, variantID, userID int64,
+	quantity int) (*model.Cart, error) {
+
+	if quantity > maxCartQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	available, err := s.availableStock(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if available <= 0 {
+		return nil, ErrOutOfStock
+	}
+	if quantity > available {
+		return nil, fmt.Errorf("%w: only %d left", ErrOutOfStock, available)
+	}
+
+	if err := s.repo.SetCartQuantity(ctx, userID, productID, variantID, quantity, s.cartCfg.TTL); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// availableStock returns the stock of the product, or of its variant when variantID is set,
+// that is not reserved by pending orders
+func (s *productService) availableStock(ctx context.Context, productID, variantID int64) (int, error) {
+	// Drafts, archived and hidden products cannot be bought
+	product, err := s.GetProductByID(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+	if product == nil {
+		return 0, ErrProductNotFound
+	}
+
+	if variantID != 0 {
+		variant, err := s.variantRepo.GetVariant(ctx, productID, variantID)
+		if err != nil {
+			return 0, mapVariantError(err)
+		}
+		return variant.Available, nil
+	}
+	if len(product.Variants) > 0 {
+		return 0, ErrVariantRequired
+	}
+
+	return product.Available, nil
+}
+
+// BuyProduct buys one of the items in the cart
+func (s *productService) BuyProduct(ctx context.Context, productID, variantID, userID int64) error {
+	quantity, err := s.repo.GetCartQuantity(ctx, userID, productID, variantID)
+	if err != nil {
+		return err
+	}
+	if quantity == 0 {
+		return ErrCartItemNotFound
+	}
+
+	return mapVariantError(s.repo.BuyProduct(ctx, productID, variantID, userID))
+}
+
+func (s *productService) CreateVariant(ctx context.Context, productID int64, req model.VariantRequest,
+	user model.User) (*model.ProductVariant, error) {
+
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return nil, err
+	}
+	if req.Price == nil {
+		return nil, ErrInvalidVariant
+	}
+
+	variant := model.ProductVariant{ProductID: productID, SKU: req.SKU, Options: map[string]string{}}
+	applyVariantRequest(&variant, req)
+	if err := validateVariant(variant); err != nil {
+		return nil, err
+	}
+
+	id, err := s.variantRepo.CreateVariant(ctx, variant)
+	if err != nil {
+		return nil, mapVariantError(err)
+	}
+	variant.ID = id
+
+	return &variant, nil
+}
+
+func (s *productService) UpdateVariant(ctx context.Context, productID, variantID int64, req model.VariantRequest,
+	user model.User) (*model.ProductVariant, error) {
+
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.variantRepo.GetVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, mapVariantError(err)
+	}
+
+	applyVariantRequest(variant, req)
+	if err := validateVariant(*variant); err != nil {
+		return nil, err
+	}
+	// The repository checks it again, pending orders may reserve more in the meantime
+	if variant.Amount < variant.Reserved {
+		return nil, ErrAmountBelowReserved
+	}
+
+	if err := s.variantRepo.UpdateVariant(ctx, *variant); err != nil {
+		return nil, mapVariantError(err)
+	}
+
+	return variant, nil
+}
+
+// DeleteVariant removes a variant that has no stock reserved by pending orders
+func (s *productService) DeleteVariant(ctx context.Context, productID, variantID int64, user model.User) error {
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return err
+	}
+
+	// The repository checks it again with the product locked
+	variant, err := s.variantRepo.GetVariant(ctx, productID, variantID)
+	if err != nil {
+		return mapVariantError(err)
+	}
+	if variant.Reserved > 0 {
+		return ErrProductReserved
+	}
+
+	return mapVariantError(s.variantRepo.DeleteVariant(ctx, productID, variantID))
+}
+
+// UploadProductImage stores the image and adds it to the gallery of the product. The type is
+// sniffed from the content, whatever the client claims.
+func (s *productService) UploadProductImage(ctx context.Context, productID int64, upload model.ImageUpload,
+	user model.User) (*model.ProductImage, error) {
+
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return nil, err
+	}
+
+	upload.AltText = strings.TrimSpace(upload.AltText)
+	if utf8.RuneCountInString(upload.AltText) > maxAltTextLength {
+		return nil, ErrInvalidImage
+	}
+	if int64(len(upload.Data)) > s.storageCfg.MaxImageSize {
+		return nil, ErrImageTooLarge
+	}
+	ext, ok := imageExtensions[http.DetectContentType(upload.Data)]
+	if !ok {
+		return nil, ErrUnsupportedImageType
+	}
+
+	galleries, err := s.imageRepo.ListImages(ctx, []int64{productID})
+	if err != nil {
+		return nil, err
+	}
+	// A full gallery fails before the upload is stored, AddImage checks it again under the product lock
+	if len(galleries[productID]) >= maxProductImages {
+		return nil, ErrTooManyImages
+	}
+
+	// A new name for every upload, so caches never serve a previous image
+	key := fmt.Sprintf("products/%d/%s%s", productID, strings.ToLower(rand.Text()), ext)
+	url, err := s.blobs.Put(ctx, key, bytes.NewReader(upload.Data))
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := s.imageRepo.AddImage(ctx, model.ProductImage{
+		ProductID: productID,
+		URL:       url,
+		AltText:   upload.AltText,
+		Primary:   upload.Primary,
+	}, maxProductImages)
+	if err != nil {
+		if delErr := s.blobs.Delete(ctx, key); delErr != nil {
+			log.Printf("failed to delete unused image %s: %v", key, delErr)
+		}
+		return nil, mapImageError(err)
+	}
+
+	return image, nil
+}
+
+func (s *productService) UpdateProductImage(ctx context.Context, productID, imageID int64,
+	req model.UpdateImageRequest, user model.User) (*model.ProductImage, error) {
+
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return nil, err
+	}
+
+	image, err := s.imageRepo.GetImage(ctx, productID, imageID)
+	if err != nil {
+		return nil, mapImageError(err)
+	}
+
+	if req.AltText != nil {
+		image.AltText = strings.TrimSpace(*req.AltText)
+		if utf8.RuneCountInString(image.AltText) > maxAltTextLength {
+			return nil, ErrInvalidImage
+		}
+	}
+	image.Primary = image.Primary || req.Primary
+
+	if err := s.imageRepo.UpdateImage(ctx, *image); err != nil {
+		return nil, mapImageError(err)
+	}
+
+	return image, nil
+}
+
+// ReorderProductImages sets the display order of the gallery and returns it
+func (s *productService) ReorderProductImages(ctx context.Context, productID int64, imageIDs []int64,
+	user model.User) ([]model.ProductImage, error) {
+
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.imageRepo.ReorderImages(ctx, productID, imageIDs); err != nil {
+		return nil, mapImageError(err)
+	}
+
+	galleries, err := s.imageRepo.ListImages(ctx, []int64{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]model.ProductImage{}, galleries[productID]...), nil
+}
+
+// DeleteProductImage removes the image from the gallery, and from the storage if it was uploaded
+func (s *productService) DeleteProductImage(ctx context.Context, productID, imageID int64, user model.User) error {
+	if err := s.checkOwnership(ctx, productID, user); err != nil {
+		return err
+	}
+
+	image, err := s.imageRepo.DeleteImage(ctx, productID, imageID)
+	if err != nil {
+		return mapImageError(err)
+	}
+
+	if key, ok := s.blobs.KeyForURL(image.URL); ok {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("failed to delete image %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+func mapImageError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrImageNotFound):
+		return ErrImageNotFound
+	case errors.Is(err, repository.ErrInvalidImageOrder):
+		return ErrInvalidImageOrder
+	case errors.Is(err, repository.ErrGalleryFull):
+		return ErrTooManyImages
+	case errors.Is(err, repository.ErrProductNotFound):
+		return ErrProductNotFound
+	default:
+		return err
+	}
+}
+
+// applyVariantRequest copies the fields set in the request to the variant
+func applyVariantRequest(variant *model.ProductVariant, req model.VariantRequest) {
+	if req.SKU != "" {
+		variant.SKU = strings.TrimSpace(req.SKU)
+	}
+	if req.Options != nil {
+		variant.Options = make(map[string]string, len(req.Options))
+		for name, value := range req.Options {
+			variant.Options[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	if req.Price != nil {
+		variant.Price = *req.Price
+	}
+	if req.Amount != nil {
+		variant.Amount = *req.Amount
+	}
+}
+
+func validateVariant(variant model.ProductVariant) error {
+	if !skuPattern.MatchString(variant.SKU) || variant.Price < 0 || variant.Amount < 0 ||
+		len(variant.Options) > maxVariantOptions {
+		return ErrInvalidVariant
+	}
+
+	for name, value := range variant.Options {
+		if name == "" || value == "" || len(name) > maxVariantOptionLength || len(value) > maxVariantOptionLength {
+			return ErrInvalidVariant
+		}
+	}
+
+	return nil
+}
+
+func mapVariantError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrVariantNotFound):
+		return ErrVariantNotFound
+	case errors.Is(err, repository.ErrVariantAlreadyExists):
+		return ErrVariantAlreadyExists
+	case errors.Is(err, repository.ErrVariantRequired):
+		return ErrVariantRequired
+	case errors.Is(err, repository.ErrOutOfStock):
+		// The repository names the item that is short, the client needs to know which one
+		var outOfStock *repository.OutOfStockError
+		if errors.As(err, &outOfStock) {
+			return fmt.Errorf("%w: %s", ErrOutOfStock, outOfStock.Item)
+		}
+		return ErrOutOfStock
+	case errors.Is(err, repository.ErrProductNotFound):
+		return ErrProductNotFound
+	case errors.Is(err, repository.ErrAmountBelowReserved):
+		return ErrAmountBelowReserved
+	case errors.Is(err, repository.ErrProductReserved):
+		return ErrProductReserved
+	default:
 		return err
 	}
+}
+
+// productCursor returns the position after the product in the sort order
+func productCursor(sort string, last model.Product) model.ProductCursor {
+	cursor := model.ProductCursor{Sort: sort, ID: last.ID}
+	switch sort {
+	case model.ProductSortNewest:
+		cursor.CreatedAt = last.CreatedAt
+	case model.ProductSortPriceAsc, model.ProductSortPriceDesc:
+		cursor.Price = last.Price
+	case model.ProductSortTitle:
+		cursor.Title = last.Title
+	}
+
+	return cursor
+}
+
+// encodeProductCursor returns the opaque form of the cursor given to clients
+func encodeProductCursor(cursor model.ProductCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeProductCursor returns the cursor of the filter, or nil for the first page
+func decodeProductCursor(filter model.ProductFilter) (*model.ProductCursor, error) {
+	if filter.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor model.ProductCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != filter.Sort {
+		return nil, ErrInvalidCursor
+	}
 
-	return s.repo.BuyProduct(ctx, productID, userID)
+	return &cursor, nil
 }
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+)
+
+// fakeProductRepo knows the seller of every product, the methods it does not override panic
+type fakeProductRepo struct {
+	repository.ProductRepository
+	sellers map[int64]int64
+}
+
+func (r *fakeProductRepo) CheckAccess(_ context.Context, productID int64) (int64, error) {
+	sellerID, ok := r.sellers[productID]
+	if !ok {
+		return -1, repository.ErrProductNotFound
+	}
+	return sellerID, nil
+}
+
+// fakeVariantRepo keeps the variants in memory by id
+type fakeVariantRepo struct {
+	repository.VariantRepository
+	variants map[int64]model.ProductVariant
+}
+
+func (r *fakeVariantRepo) GetVariant(_ context.Context, productID, variantID int64) (*model.ProductVariant, error) {
+	variant, ok := r.variants[variantID]
+	if !ok || variant.ProductID != productID {
+		return nil, repository.ErrVariantNotFound
+	}
+	return &variant, nil
+}
+
+func (r *fakeVariantRepo) DeleteVariant(_ context.Context, productID, variantID int64) error {
+	if _, err := r.GetVariant(context.Background(), productID, variantID); err != nil {
+		return err
+	}
+	delete(r.variants, variantID)
+	return nil
+}
+
+func TestDeleteVariant(t *testing.T) {
+	seller := model.User{ID: 7, Role: model.RoleSeller}
+
+	tests := []struct {
+		name        string
+		variantID   int64
+		expectedErr error
+	}{
+		{name: "Variant without reservations", variantID: 1},
+		{name: "Variant held by pending orders", variantID: 2, expectedErr: ErrProductReserved},
+		{name: "Unknown variant", variantID: 3, expectedErr: ErrVariantNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants := &fakeVariantRepo{variants: map[int64]model.ProductVariant{
+				1: {ID: 1, ProductID: 10, Amount: 5},
+				2: {ID: 2, ProductID: 10, Amount: 5, Reserved: 2},
+			}}
+			svc := NewProductService(&fakeProductRepo{sellers: map[int64]int64{10: seller.ID}}, nil, variants,
+				nil, nil, nil, config.StorageConfig{}, config.CartConfig{})
+
+			err := svc.DeleteVariant(context.Background(), 10, tt.variantID, seller)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				assert.NotContains(t, variants.variants, tt.variantID)
+			}
+			if tt.expectedErr == ErrProductReserved {
+				assert.Contains(t, variants.variants, tt.variantID)
+			}
+		})
+	}
+}
+
+func TestMapVariantErrorOutOfStock(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedMessage string
+	}{
+		{
+			name:            "Item is named",
+			err:             fmt.Errorf("failed to create order: %w", &repository.OutOfStockError{Item: "Lamp L"}),
+			expectedMessage: "product out of stock: Lamp L",
+		},
+		{
+			name:            "Item is unknown",
+			err:             repository.ErrOutOfStock,
+			expectedMessage: "product out of stock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mapVariantError(tt.err)
+			assert.ErrorIs(t, err, ErrOutOfStock)
+			assert.EqualError(t, err, tt.expectedMessage)
+		})
+	}
+}
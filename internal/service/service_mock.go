@@ -6,18 +6,19 @@ package service
 
 import (
 	"context"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
 )
 
-// NewMockProductService creates a new instance of MockProductService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewMockAPIKeyService creates a new instance of MockAPIKeyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockProductService(t interface {
+func NewMockAPIKeyService(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockProductService {
-	mock := &MockProductService{}
+}) *MockAPIKeyService {
+	mock := &MockAPIKeyService{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -25,392 +26,384 @@ func NewMockProductService(t interface {
 	return mock
 }
 
-// MockProductService is an autogenerated mock type for the ProductService type
-type MockProductService struct {
+// MockAPIKeyService is an autogenerated mock type for the APIKeyService type
+type MockAPIKeyService struct {
 	mock.Mock
 }
 
-type MockProductService_Expecter struct {
+type MockAPIKeyService_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockProductService) EXPECT() *MockProductService_Expecter {
-	return &MockProductService_Expecter{mock: &_m.Mock}
+func (_m *MockAPIKeyService) EXPECT() *MockAPIKeyService_Expecter {
+	return &MockAPIKeyService_Expecter{mock: &_m.Mock}
 }
 
-// AddToCart provides a mock function for the type MockProductService
-func (_mock *MockProductService) AddToCart(ctx context.Context, productID int64, userID int64) error {
-	ret := _mock.Called(ctx, productID, userID)
+// AuthenticateAPIKey provides a mock function for the type MockAPIKeyService
+func (_mock *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*model.User, []string, error) {
+	ret := _mock.Called(ctx, key)
 
 	if len(ret) == 0 {
-		panic("no return value specified for AddToCart")
+		panic("no return value specified for AuthenticateAPIKey")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
-		r0 = returnFunc(ctx, productID, userID)
+	var r0 *model.User
+	var r1 []string
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.User, []string, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.User); ok {
+		r0 = returnFunc(ctx, key)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) []string); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = returnFunc(ctx, key)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
 }
 
-// MockProductService_AddToCart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddToCart'
-type MockProductService_AddToCart_Call struct {
+// MockAPIKeyService_AuthenticateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthenticateAPIKey'
+type MockAPIKeyService_AuthenticateAPIKey_Call struct {
 	*mock.Call
 }
 
-// AddToCart is a helper method to define mock.On call
+// AuthenticateAPIKey is a helper method to define mock.On call
 //   - ctx
-//   - productID
-//   - userID
-func (_e *MockProductService_Expecter) AddToCart(ctx interface{}, productID interface{}, userID interface{}) *MockProductService_AddToCart_Call {
-	return &MockProductService_AddToCart_Call{Call: _e.mock.On("AddToCart", ctx, productID, userID)}
+//   - key
+func (_e *MockAPIKeyService_Expecter) AuthenticateAPIKey(ctx interface{}, key interface{}) *MockAPIKeyService_AuthenticateAPIKey_Call {
+	return &MockAPIKeyService_AuthenticateAPIKey_Call{Call: _e.mock.On("AuthenticateAPIKey", ctx, key)}
 }
 
-func (_c *MockProductService_AddToCart_Call) Run(run func(ctx context.Context, productID int64, userID int64)) *MockProductService_AddToCart_Call {
+func (_c *MockAPIKeyService_AuthenticateAPIKey_Call) Run(run func(ctx context.Context, key string)) *MockAPIKeyService_AuthenticateAPIKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockProductService_AddToCart_Call) Return(err error) *MockProductService_AddToCart_Call {
-	_c.Call.Return(err)
+func (_c *MockAPIKeyService_AuthenticateAPIKey_Call) Return(user *model.User, strings []string, err error) *MockAPIKeyService_AuthenticateAPIKey_Call {
+	_c.Call.Return(user, strings, err)
 	return _c
 }
 
-func (_c *MockProductService_AddToCart_Call) RunAndReturn(run func(ctx context.Context, productID int64, userID int64) error) *MockProductService_AddToCart_Call {
+func (_c *MockAPIKeyService_AuthenticateAPIKey_Call) RunAndReturn(run func(ctx context.Context, key string) (*model.User, []string, error)) *MockAPIKeyService_AuthenticateAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// BuyProduct provides a mock function for the type MockProductService
-func (_mock *MockProductService) BuyProduct(ctx context.Context, productID int64, userID int64) error {
-	ret := _mock.Called(ctx, productID, userID)
+// CreateAPIKey provides a mock function for the type MockAPIKeyService
+func (_mock *MockAPIKeyService) CreateAPIKey(ctx context.Context, user model.User, req model.CreateAPIKeyRequest) (*model.CreatedAPIKey, error) {
+	ret := _mock.Called(ctx, user, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for BuyProduct")
+		panic("no return value specified for CreateAPIKey")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
-		r0 = returnFunc(ctx, productID, userID)
+	var r0 *model.CreatedAPIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, model.CreateAPIKeyRequest) (*model.CreatedAPIKey, error)); ok {
+		return returnFunc(ctx, user, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, model.CreateAPIKeyRequest) *model.CreatedAPIKey); ok {
+		r0 = returnFunc(ctx, user, req)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CreatedAPIKey)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.User, model.CreateAPIKeyRequest) error); ok {
+		r1 = returnFunc(ctx, user, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockProductService_BuyProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BuyProduct'
-type MockProductService_BuyProduct_Call struct {
+// MockAPIKeyService_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type MockAPIKeyService_CreateAPIKey_Call struct {
 	*mock.Call
 }
 
-// BuyProduct is a helper method to define mock.On call
+// CreateAPIKey is a helper method to define mock.On call
 //   - ctx
-//   - productID
-//   - userID
-func (_e *MockProductService_Expecter) BuyProduct(ctx interface{}, productID interface{}, userID interface{}) *MockProductService_BuyProduct_Call {
-	return &MockProductService_BuyProduct_Call{Call: _e.mock.On("BuyProduct", ctx, productID, userID)}
+//   - user
+//   - req
+func (_e *MockAPIKeyService_Expecter) CreateAPIKey(ctx interface{}, user interface{}, req interface{}) *MockAPIKeyService_CreateAPIKey_Call {
+	return &MockAPIKeyService_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, user, req)}
 }
 
-func (_c *MockProductService_BuyProduct_Call) Run(run func(ctx context.Context, productID int64, userID int64)) *MockProductService_BuyProduct_Call {
+func (_c *MockAPIKeyService_CreateAPIKey_Call) Run(run func(ctx context.Context, user model.User, req model.CreateAPIKeyRequest)) *MockAPIKeyService_CreateAPIKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+		run(args[0].(context.Context), args[1].(model.User), args[2].(model.CreateAPIKeyRequest))
 	})
 	return _c
 }
 
-func (_c *MockProductService_BuyProduct_Call) Return(err error) *MockProductService_BuyProduct_Call {
-	_c.Call.Return(err)
+func (_c *MockAPIKeyService_CreateAPIKey_Call) Return(createdAPIKey *model.CreatedAPIKey, err error) *MockAPIKeyService_CreateAPIKey_Call {
+	_c.Call.Return(createdAPIKey, err)
 	return _c
 }
 
-func (_c *MockProductService_BuyProduct_Call) RunAndReturn(run func(ctx context.Context, productID int64, userID int64) error) *MockProductService_BuyProduct_Call {
+func (_c *MockAPIKeyService_CreateAPIKey_Call) RunAndReturn(run func(ctx context.Context, user model.User, req model.CreateAPIKeyRequest) (*model.CreatedAPIKey, error)) *MockAPIKeyService_CreateAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateProduct provides a mock function for the type MockProductService
-func (_mock *MockProductService) CreateProduct(ctx context.Context, ProductReq model.CreateProductRequest, seller model.User) (int64, error) {
-	ret := _mock.Called(ctx, ProductReq, seller)
+// ListAPIKeys provides a mock function for the type MockAPIKeyService
+func (_mock *MockAPIKeyService) ListAPIKeys(ctx context.Context, user model.User) ([]model.APIKey, error) {
+	ret := _mock.Called(ctx, user)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateProduct")
+		panic("no return value specified for ListAPIKeys")
 	}
 
-	var r0 int64
+	var r0 []model.APIKey
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, model.CreateProductRequest, model.User) (int64, error)); ok {
-		return returnFunc(ctx, ProductReq, seller)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User) ([]model.APIKey, error)); ok {
+		return returnFunc(ctx, user)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, model.CreateProductRequest, model.User) int64); ok {
-		r0 = returnFunc(ctx, ProductReq, seller)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User) []model.APIKey); ok {
+		r0 = returnFunc(ctx, user)
 	} else {
-		r0 = ret.Get(0).(int64)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.APIKey)
+		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, model.CreateProductRequest, model.User) error); ok {
-		r1 = returnFunc(ctx, ProductReq, seller)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.User) error); ok {
+		r1 = returnFunc(ctx, user)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockProductService_CreateProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateProduct'
-type MockProductService_CreateProduct_Call struct {
+// MockAPIKeyService_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type MockAPIKeyService_ListAPIKeys_Call struct {
 	*mock.Call
 }
 
-// CreateProduct is a helper method to define mock.On call
+// ListAPIKeys is a helper method to define mock.On call
 //   - ctx
-//   - ProductReq
-//   - seller
-func (_e *MockProductService_Expecter) CreateProduct(ctx interface{}, ProductReq interface{}, seller interface{}) *MockProductService_CreateProduct_Call {
-	return &MockProductService_CreateProduct_Call{Call: _e.mock.On("CreateProduct", ctx, ProductReq, seller)}
+//   - user
+func (_e *MockAPIKeyService_Expecter) ListAPIKeys(ctx interface{}, user interface{}) *MockAPIKeyService_ListAPIKeys_Call {
+	return &MockAPIKeyService_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", ctx, user)}
 }
 
-func (_c *MockProductService_CreateProduct_Call) Run(run func(ctx context.Context, ProductReq model.CreateProductRequest, seller model.User)) *MockProductService_CreateProduct_Call {
+func (_c *MockAPIKeyService_ListAPIKeys_Call) Run(run func(ctx context.Context, user model.User)) *MockAPIKeyService_ListAPIKeys_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(model.CreateProductRequest), args[2].(model.User))
+		run(args[0].(context.Context), args[1].(model.User))
 	})
 	return _c
 }
 
-func (_c *MockProductService_CreateProduct_Call) Return(n int64, err error) *MockProductService_CreateProduct_Call {
-	_c.Call.Return(n, err)
+func (_c *MockAPIKeyService_ListAPIKeys_Call) Return(apiKeys []model.APIKey, err error) *MockAPIKeyService_ListAPIKeys_Call {
+	_c.Call.Return(apiKeys, err)
 	return _c
 }
 
-func (_c *MockProductService_CreateProduct_Call) RunAndReturn(run func(ctx context.Context, ProductReq model.CreateProductRequest, seller model.User) (int64, error)) *MockProductService_CreateProduct_Call {
+func (_c *MockAPIKeyService_ListAPIKeys_Call) RunAndReturn(run func(ctx context.Context, user model.User) ([]model.APIKey, error)) *MockAPIKeyService_ListAPIKeys_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteProduct provides a mock function for the type MockProductService
-func (_mock *MockProductService) DeleteProduct(ctx context.Context, id int64) error {
-	ret := _mock.Called(ctx, id)
+// RevokeAPIKey provides a mock function for the type MockAPIKeyService
+func (_mock *MockAPIKeyService) RevokeAPIKey(ctx context.Context, user model.User, keyID int64) error {
+	ret := _mock.Called(ctx, user, keyID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteProduct")
+		panic("no return value specified for RevokeAPIKey")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, int64) error); ok {
+		r0 = returnFunc(ctx, user, keyID)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockProductService_DeleteProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProduct'
-type MockProductService_DeleteProduct_Call struct {
+// MockAPIKeyService_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type MockAPIKeyService_RevokeAPIKey_Call struct {
 	*mock.Call
 }
 
-// DeleteProduct is a helper method to define mock.On call
+// RevokeAPIKey is a helper method to define mock.On call
 //   - ctx
-//   - id
-func (_e *MockProductService_Expecter) DeleteProduct(ctx interface{}, id interface{}) *MockProductService_DeleteProduct_Call {
-	return &MockProductService_DeleteProduct_Call{Call: _e.mock.On("DeleteProduct", ctx, id)}
+//   - user
+//   - keyID
+func (_e *MockAPIKeyService_Expecter) RevokeAPIKey(ctx interface{}, user interface{}, keyID interface{}) *MockAPIKeyService_RevokeAPIKey_Call {
+	return &MockAPIKeyService_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, user, keyID)}
 }
 
-func (_c *MockProductService_DeleteProduct_Call) Run(run func(ctx context.Context, id int64)) *MockProductService_DeleteProduct_Call {
+func (_c *MockAPIKeyService_RevokeAPIKey_Call) Run(run func(ctx context.Context, user model.User, keyID int64)) *MockAPIKeyService_RevokeAPIKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int64))
+		run(args[0].(context.Context), args[1].(model.User), args[2].(int64))
 	})
 	return _c
 }
 
-func (_c *MockProductService_DeleteProduct_Call) Return(err error) *MockProductService_DeleteProduct_Call {
+func (_c *MockAPIKeyService_RevokeAPIKey_Call) Return(err error) *MockAPIKeyService_RevokeAPIKey_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockProductService_DeleteProduct_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockProductService_DeleteProduct_Call {
+func (_c *MockAPIKeyService_RevokeAPIKey_Call) RunAndReturn(run func(ctx context.Context, user model.User, keyID int64) error) *MockAPIKeyService_RevokeAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetAllProducts provides a mock function for the type MockProductService
-func (_mock *MockProductService) GetAllProducts(ctx context.Context) ([]model.Product, error) {
-	ret := _mock.Called(ctx)
-
-	if len(ret) == 0 {
-		panic("no return value specified for GetAllProducts")
-	}
-
-	var r0 []model.Product
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]model.Product, error)); ok {
-		return returnFunc(ctx)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context) []model.Product); ok {
-		r0 = returnFunc(ctx)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Product)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = returnFunc(ctx)
-	} else {
-		r1 = ret.Error(1)
-	}
-	return r0, r1
-}
+// NewMockAccountService creates a new instance of MockAccountService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAccountService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAccountService {
+	mock := &MockAccountService{}
+	mock.Mock.Test(t)
 
-// MockProductService_GetAllProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllProducts'
-type MockProductService_GetAllProducts_Call struct {
-	*mock.Call
-}
+	t.Cleanup(func() { mock.AssertExpectations(t) })
 
-// GetAllProducts is a helper method to define mock.On call
-//   - ctx
-func (_e *MockProductService_Expecter) GetAllProducts(ctx interface{}) *MockProductService_GetAllProducts_Call {
-	return &MockProductService_GetAllProducts_Call{Call: _e.mock.On("GetAllProducts", ctx)}
+	return mock
 }
 
-func (_c *MockProductService_GetAllProducts_Call) Run(run func(ctx context.Context)) *MockProductService_GetAllProducts_Call {
-	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
-	})
-	return _c
+// MockAccountService is an autogenerated mock type for the AccountService type
+type MockAccountService struct {
+	mock.Mock
 }
 
-func (_c *MockProductService_GetAllProducts_Call) Return(products []model.Product, err error) *MockProductService_GetAllProducts_Call {
-	_c.Call.Return(products, err)
-	return _c
+type MockAccountService_Expecter struct {
+	mock *mock.Mock
 }
 
-func (_c *MockProductService_GetAllProducts_Call) RunAndReturn(run func(ctx context.Context) ([]model.Product, error)) *MockProductService_GetAllProducts_Call {
-	_c.Call.Return(run)
-	return _c
+func (_m *MockAccountService) EXPECT() *MockAccountService_Expecter {
+	return &MockAccountService_Expecter{mock: &_m.Mock}
 }
 
-// GetProductByID provides a mock function for the type MockProductService
-func (_mock *MockProductService) GetProductByID(ctx context.Context, id int64) (*model.Product, error) {
-	ret := _mock.Called(ctx, id)
+// DeleteAccount provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) DeleteAccount(ctx context.Context, user model.User, password string) error {
+	ret := _mock.Called(ctx, user, password)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetProductByID")
+		panic("no return value specified for DeleteAccount")
 	}
 
-	var r0 *model.Product
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.Product, error)); ok {
-		return returnFunc(ctx, id)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.Product); ok {
-		r0 = returnFunc(ctx, id)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Product)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = returnFunc(ctx, id)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, string) error); ok {
+		r0 = returnFunc(ctx, user, password)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockProductService_GetProductByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProductByID'
-type MockProductService_GetProductByID_Call struct {
+// MockAccountService_DeleteAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAccount'
+type MockAccountService_DeleteAccount_Call struct {
 	*mock.Call
 }
 
-// GetProductByID is a helper method to define mock.On call
+// DeleteAccount is a helper method to define mock.On call
 //   - ctx
-//   - id
-func (_e *MockProductService_Expecter) GetProductByID(ctx interface{}, id interface{}) *MockProductService_GetProductByID_Call {
-	return &MockProductService_GetProductByID_Call{Call: _e.mock.On("GetProductByID", ctx, id)}
+//   - user
+//   - password
+func (_e *MockAccountService_Expecter) DeleteAccount(ctx interface{}, user interface{}, password interface{}) *MockAccountService_DeleteAccount_Call {
+	return &MockAccountService_DeleteAccount_Call{Call: _e.mock.On("DeleteAccount", ctx, user, password)}
 }
 
-func (_c *MockProductService_GetProductByID_Call) Run(run func(ctx context.Context, id int64)) *MockProductService_GetProductByID_Call {
+func (_c *MockAccountService_DeleteAccount_Call) Run(run func(ctx context.Context, user model.User, password string)) *MockAccountService_DeleteAccount_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int64))
+		run(args[0].(context.Context), args[1].(model.User), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockProductService_GetProductByID_Call) Return(product *model.Product, err error) *MockProductService_GetProductByID_Call {
-	_c.Call.Return(product, err)
+func (_c *MockAccountService_DeleteAccount_Call) Return(err error) *MockAccountService_DeleteAccount_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockProductService_GetProductByID_Call) RunAndReturn(run func(ctx context.Context, id int64) (*model.Product, error)) *MockProductService_GetProductByID_Call {
+func (_c *MockAccountService_DeleteAccount_Call) RunAndReturn(run func(ctx context.Context, user model.User, password string) error) *MockAccountService_DeleteAccount_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateProduct provides a mock function for the type MockProductService
-func (_mock *MockProductService) UpdateProduct(ctx context.Context, productReq model.UpdateProductRequest, productID int64, userID int64) (int64, error) {
-	ret := _mock.Called(ctx, productReq, productID, userID)
+// ExportUserData provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) ExportUserData(ctx context.Context, user model.User) (*model.UserExport, error) {
+	ret := _mock.Called(ctx, user)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateProduct")
+		panic("no return value specified for ExportUserData")
 	}
 
-	var r0 int64
+	var r0 *model.UserExport
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UpdateProductRequest, int64, int64) (int64, error)); ok {
-		return returnFunc(ctx, productReq, productID, userID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User) (*model.UserExport, error)); ok {
+		return returnFunc(ctx, user)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UpdateProductRequest, int64, int64) int64); ok {
-		r0 = returnFunc(ctx, productReq, productID, userID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User) *model.UserExport); ok {
+		r0 = returnFunc(ctx, user)
 	} else {
-		r0 = ret.Get(0).(int64)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserExport)
+		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, model.UpdateProductRequest, int64, int64) error); ok {
-		r1 = returnFunc(ctx, productReq, productID, userID)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.User) error); ok {
+		r1 = returnFunc(ctx, user)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockProductService_UpdateProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProduct'
-type MockProductService_UpdateProduct_Call struct {
+// MockAccountService_ExportUserData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportUserData'
+type MockAccountService_ExportUserData_Call struct {
 	*mock.Call
 }
 
-// UpdateProduct is a helper method to define mock.On call
+// ExportUserData is a helper method to define mock.On call
 //   - ctx
-//   - productReq
-//   - productID
-//   - userID
-func (_e *MockProductService_Expecter) UpdateProduct(ctx interface{}, productReq interface{}, productID interface{}, userID interface{}) *MockProductService_UpdateProduct_Call {
-	return &MockProductService_UpdateProduct_Call{Call: _e.mock.On("UpdateProduct", ctx, productReq, productID, userID)}
+//   - user
+func (_e *MockAccountService_Expecter) ExportUserData(ctx interface{}, user interface{}) *MockAccountService_ExportUserData_Call {
+	return &MockAccountService_ExportUserData_Call{Call: _e.mock.On("ExportUserData", ctx, user)}
 }
 
-func (_c *MockProductService_UpdateProduct_Call) Run(run func(ctx context.Context, productReq model.UpdateProductRequest, productID int64, userID int64)) *MockProductService_UpdateProduct_Call {
+func (_c *MockAccountService_ExportUserData_Call) Run(run func(ctx context.Context, user model.User)) *MockAccountService_ExportUserData_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(model.UpdateProductRequest), args[2].(int64), args[3].(int64))
+		run(args[0].(context.Context), args[1].(model.User))
 	})
 	return _c
 }
 
-func (_c *MockProductService_UpdateProduct_Call) Return(n int64, err error) *MockProductService_UpdateProduct_Call {
-	_c.Call.Return(n, err)
+func (_c *MockAccountService_ExportUserData_Call) Return(userExport *model.UserExport, err error) *MockAccountService_ExportUserData_Call {
+	_c.Call.Return(userExport, err)
 	return _c
 }
 
-func (_c *MockProductService_UpdateProduct_Call) RunAndReturn(run func(ctx context.Context, productReq model.UpdateProductRequest, productID int64, userID int64) (int64, error)) *MockProductService_UpdateProduct_Call {
+func (_c *MockAccountService_ExportUserData_Call) RunAndReturn(run func(ctx context.Context, user model.User) (*model.UserExport, error)) *MockAccountService_ExportUserData_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewMockUserService creates a new instance of MockUserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewMockAdminService creates a new instance of MockAdminService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockUserService(t interface {
+func NewMockAdminService(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockUserService {
-	mock := &MockUserService{}
+}) *MockAdminService {
+	mock := &MockAdminService{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -418,113 +411,3303 @@ func NewMockUserService(t interface {
 	return mock
 }
 
-// MockUserService is an autogenerated mock type for the UserService type
-type MockUserService struct {
+// MockAdminService is an autogenerated mock type for the AdminService type
+type MockAdminService struct {
 	mock.Mock
 }
 
-type MockUserService_Expecter struct {
+type MockAdminService_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockUserService) EXPECT() *MockUserService_Expecter {
-	return &MockUserService_Expecter{mock: &_m.Mock}
+func (_m *MockAdminService) EXPECT() *MockAdminService_Expecter {
+	return &MockAdminService_Expecter{mock: &_m.Mock}
 }
 
-// CreateUser provides a mock function for the type MockUserService
-func (_mock *MockUserService) CreateUser(ctx context.Context, usr model.UserRegister, hashedPassword string) (int64, error) {
-	ret := _mock.Called(ctx, usr, hashedPassword)
+// BanUser provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) BanUser(ctx context.Context, admin model.User, userID int64) error {
+	ret := _mock.Called(ctx, admin, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateUser")
+		panic("no return value specified for BanUser")
 	}
 
-	var r0 int64
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserRegister, string) (int64, error)); ok {
-		return returnFunc(ctx, usr, hashedPassword)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserRegister, string) int64); ok {
-		r0 = returnFunc(ctx, usr, hashedPassword)
-	} else {
-		r0 = ret.Get(0).(int64)
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, model.UserRegister, string) error); ok {
-		r1 = returnFunc(ctx, usr, hashedPassword)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, int64) error); ok {
+		r0 = returnFunc(ctx, admin, userID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockUserService_CreateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUser'
-type MockUserService_CreateUser_Call struct {
+// MockAdminService_BanUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BanUser'
+type MockAdminService_BanUser_Call struct {
 	*mock.Call
 }
 
-// CreateUser is a helper method to define mock.On call
+// BanUser is a helper method to define mock.On call
 //   - ctx
-//   - usr
-//   - hashedPassword
-func (_e *MockUserService_Expecter) CreateUser(ctx interface{}, usr interface{}, hashedPassword interface{}) *MockUserService_CreateUser_Call {
-	return &MockUserService_CreateUser_Call{Call: _e.mock.On("CreateUser", ctx, usr, hashedPassword)}
+//   - admin
+//   - userID
+func (_e *MockAdminService_Expecter) BanUser(ctx interface{}, admin interface{}, userID interface{}) *MockAdminService_BanUser_Call {
+	return &MockAdminService_BanUser_Call{Call: _e.mock.On("BanUser", ctx, admin, userID)}
 }
 
-func (_c *MockUserService_CreateUser_Call) Run(run func(ctx context.Context, usr model.UserRegister, hashedPassword string)) *MockUserService_CreateUser_Call {
+func (_c *MockAdminService_BanUser_Call) Run(run func(ctx context.Context, admin model.User, userID int64)) *MockAdminService_BanUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(model.UserRegister), args[2].(string))
+		run(args[0].(context.Context), args[1].(model.User), args[2].(int64))
 	})
 	return _c
 }
 
-func (_c *MockUserService_CreateUser_Call) Return(n int64, err error) *MockUserService_CreateUser_Call {
-	_c.Call.Return(n, err)
+func (_c *MockAdminService_BanUser_Call) Return(err error) *MockAdminService_BanUser_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockUserService_CreateUser_Call) RunAndReturn(run func(ctx context.Context, usr model.UserRegister, hashedPassword string) (int64, error)) *MockUserService_CreateUser_Call {
+func (_c *MockAdminService_BanUser_Call) RunAndReturn(run func(ctx context.Context, admin model.User, userID int64) error) *MockAdminService_BanUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUserByEmail provides a mock function for the type MockUserService
-func (_mock *MockUserService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	ret := _mock.Called(ctx, email)
+// ChangeUserRole provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) ChangeUserRole(ctx context.Context, admin model.User, userID int64, role string) error {
+	ret := _mock.Called(ctx, admin, userID, role)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserByEmail")
+		panic("no return value specified for ChangeUserRole")
 	}
 
-	var r0 *model.User
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.User, error)); ok {
-		return returnFunc(ctx, email)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.User); ok {
-		r0 = returnFunc(ctx, email)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.User)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, email)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, int64, string) error); ok {
+		r0 = returnFunc(ctx, admin, userID, role)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockUserService_GetUserByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByEmail'
-type MockUserService_GetUserByEmail_Call struct {
+// MockAdminService_ChangeUserRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangeUserRole'
+type MockAdminService_ChangeUserRole_Call struct {
 	*mock.Call
 }
 
-// GetUserByEmail is a helper method to define mock.On call
+// ChangeUserRole is a helper method to define mock.On call
 //   - ctx
-//   - email
-func (_e *MockUserService_Expecter) GetUserByEmail(ctx interface{}, email interface{}) *MockUserService_GetUserByEmail_Call {
-	return &MockUserService_GetUserByEmail_Call{Call: _e.mock.On("GetUserByEmail", ctx, email)}
+//   - admin
+//   - userID
+//   - role
+func (_e *MockAdminService_Expecter) ChangeUserRole(ctx interface{}, admin interface{}, userID interface{}, role interface{}) *MockAdminService_ChangeUserRole_Call {
+	return &MockAdminService_ChangeUserRole_Call{Call: _e.mock.On("ChangeUserRole", ctx, admin, userID, role)}
+}
+
+func (_c *MockAdminService_ChangeUserRole_Call) Run(run func(ctx context.Context, admin model.User, userID int64, role string)) *MockAdminService_ChangeUserRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.User), args[2].(int64), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockAdminService_ChangeUserRole_Call) Return(err error) *MockAdminService_ChangeUserRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAdminService_ChangeUserRole_Call) RunAndReturn(run func(ctx context.Context, admin model.User, userID int64, role string) error) *MockAdminService_ChangeUserRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteProduct provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) DeleteProduct(ctx context.Context, productID int64) error {
+	ret := _mock.Called(ctx, productID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProduct")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, productID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAdminService_DeleteProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProduct'
+type MockAdminService_DeleteProduct_Call struct {
+	*mock.Call
+}
+
+// DeleteProduct is a helper method to define mock.On call
+//   - ctx
+//   - productID
+func (_e *MockAdminService_Expecter) DeleteProduct(ctx interface{}, productID interface{}) *MockAdminService_DeleteProduct_Call {
+	return &MockAdminService_DeleteProduct_Call{Call: _e.mock.On("DeleteProduct", ctx, productID)}
+}
+
+func (_c *MockAdminService_DeleteProduct_Call) Run(run func(ctx context.Context, productID int64)) *MockAdminService_DeleteProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockAdminService_DeleteProduct_Call) Return(err error) *MockAdminService_DeleteProduct_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAdminService_DeleteProduct_Call) RunAndReturn(run func(ctx context.Context, productID int64) error) *MockAdminService_DeleteProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserCart provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) GetUserCart(ctx context.Context, userID int64) ([]model.CartItem, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserCart")
+	}
+
+	var r0 []model.CartItem
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.CartItem, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.CartItem); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.CartItem)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAdminService_GetUserCart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserCart'
+type MockAdminService_GetUserCart_Call struct {
+	*mock.Call
+}
+
+// GetUserCart is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockAdminService_Expecter) GetUserCart(ctx interface{}, userID interface{}) *MockAdminService_GetUserCart_Call {
+	return &MockAdminService_GetUserCart_Call{Call: _e.mock.On("GetUserCart", ctx, userID)}
+}
+
+func (_c *MockAdminService_GetUserCart_Call) Run(run func(ctx context.Context, userID int64)) *MockAdminService_GetUserCart_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockAdminService_GetUserCart_Call) Return(cartItems []model.CartItem, err error) *MockAdminService_GetUserCart_Call {
+	_c.Call.Return(cartItems, err)
+	return _c
+}
+
+func (_c *MockAdminService_GetUserCart_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]model.CartItem, error)) *MockAdminService_GetUserCart_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListProducts provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) ListProducts(ctx context.Context, filter model.AdminProductFilter) ([]model.Product, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListProducts")
+	}
+
+	var r0 []model.Product
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.AdminProductFilter) ([]model.Product, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.AdminProductFilter) []model.Product); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Product)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.AdminProductFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAdminService_ListProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListProducts'
+type MockAdminService_ListProducts_Call struct {
+	*mock.Call
+}
+
+// ListProducts is a helper method to define mock.On call
+//   - ctx
+//   - filter
+func (_e *MockAdminService_Expecter) ListProducts(ctx interface{}, filter interface{}) *MockAdminService_ListProducts_Call {
+	return &MockAdminService_ListProducts_Call{Call: _e.mock.On("ListProducts", ctx, filter)}
+}
+
+func (_c *MockAdminService_ListProducts_Call) Run(run func(ctx context.Context, filter model.AdminProductFilter)) *MockAdminService_ListProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.AdminProductFilter))
+	})
+	return _c
+}
+
+func (_c *MockAdminService_ListProducts_Call) Return(products []model.Product, err error) *MockAdminService_ListProducts_Call {
+	_c.Call.Return(products, err)
+	return _c
+}
+
+func (_c *MockAdminService_ListProducts_Call) RunAndReturn(run func(ctx context.Context, filter model.AdminProductFilter) ([]model.Product, error)) *MockAdminService_ListProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUsers provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) ListUsers(ctx context.Context, filter model.UserFilter) ([]model.User, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []model.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserFilter) ([]model.User, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserFilter) []model.User); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.UserFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAdminService_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
+type MockAdminService_ListUsers_Call struct {
+	*mock.Call
+}
+
+// ListUsers is a helper method to define mock.On call
+//   - ctx
+//   - filter
+func (_e *MockAdminService_Expecter) ListUsers(ctx interface{}, filter interface{}) *MockAdminService_ListUsers_Call {
+	return &MockAdminService_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, filter)}
+}
+
+func (_c *MockAdminService_ListUsers_Call) Run(run func(ctx context.Context, filter model.UserFilter)) *MockAdminService_ListUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.UserFilter))
+	})
+	return _c
+}
+
+func (_c *MockAdminService_ListUsers_Call) Return(users []model.User, err error) *MockAdminService_ListUsers_Call {
+	_c.Call.Return(users, err)
+	return _c
+}
+
+func (_c *MockAdminService_ListUsers_Call) RunAndReturn(run func(ctx context.Context, filter model.UserFilter) ([]model.User, error)) *MockAdminService_ListUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetProductHidden provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) SetProductHidden(ctx context.Context, productID int64, hidden bool) error {
+	ret := _mock.Called(ctx, productID, hidden)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetProductHidden")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, bool) error); ok {
+		r0 = returnFunc(ctx, productID, hidden)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAdminService_SetProductHidden_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetProductHidden'
+type MockAdminService_SetProductHidden_Call struct {
+	*mock.Call
+}
+
+// SetProductHidden is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - hidden
+func (_e *MockAdminService_Expecter) SetProductHidden(ctx interface{}, productID interface{}, hidden interface{}) *MockAdminService_SetProductHidden_Call {
+	return &MockAdminService_SetProductHidden_Call{Call: _e.mock.On("SetProductHidden", ctx, productID, hidden)}
+}
+
+func (_c *MockAdminService_SetProductHidden_Call) Run(run func(ctx context.Context, productID int64, hidden bool)) *MockAdminService_SetProductHidden_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockAdminService_SetProductHidden_Call) Return(err error) *MockAdminService_SetProductHidden_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAdminService_SetProductHidden_Call) RunAndReturn(run func(ctx context.Context, productID int64, hidden bool) error) *MockAdminService_SetProductHidden_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnbanUser provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) UnbanUser(ctx context.Context, admin model.User, userID int64) error {
+	ret := _mock.Called(ctx, admin, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnbanUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, int64) error); ok {
+		r0 = returnFunc(ctx, admin, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAdminService_UnbanUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnbanUser'
+type MockAdminService_UnbanUser_Call struct {
+	*mock.Call
+}
+
+// UnbanUser is a helper method to define mock.On call
+//   - ctx
+//   - admin
+//   - userID
+func (_e *MockAdminService_Expecter) UnbanUser(ctx interface{}, admin interface{}, userID interface{}) *MockAdminService_UnbanUser_Call {
+	return &MockAdminService_UnbanUser_Call{Call: _e.mock.On("UnbanUser", ctx, admin, userID)}
+}
+
+func (_c *MockAdminService_UnbanUser_Call) Run(run func(ctx context.Context, admin model.User, userID int64)) *MockAdminService_UnbanUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.User), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockAdminService_UnbanUser_Call) Return(err error) *MockAdminService_UnbanUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAdminService_UnbanUser_Call) RunAndReturn(run func(ctx context.Context, admin model.User, userID int64) error) *MockAdminService_UnbanUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnlockUser provides a mock function for the type MockAdminService
+func (_mock *MockAdminService) UnlockUser(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlockUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAdminService_UnlockUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlockUser'
+type MockAdminService_UnlockUser_Call struct {
+	*mock.Call
+}
+
+// UnlockUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockAdminService_Expecter) UnlockUser(ctx interface{}, userID interface{}) *MockAdminService_UnlockUser_Call {
+	return &MockAdminService_UnlockUser_Call{Call: _e.mock.On("UnlockUser", ctx, userID)}
+}
+
+func (_c *MockAdminService_UnlockUser_Call) Run(run func(ctx context.Context, userID int64)) *MockAdminService_UnlockUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockAdminService_UnlockUser_Call) Return(err error) *MockAdminService_UnlockUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAdminService_UnlockUser_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockAdminService_UnlockUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockCategoryService creates a new instance of MockCategoryService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCategoryService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCategoryService {
+	mock := &MockCategoryService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCategoryService is an autogenerated mock type for the CategoryService type
+type MockCategoryService struct {
+	mock.Mock
+}
+
+type MockCategoryService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCategoryService) EXPECT() *MockCategoryService_Expecter {
+	return &MockCategoryService_Expecter{mock: &_m.Mock}
+}
+
+// CreateAttribute provides a mock function for the type MockCategoryService
+func (_mock *MockCategoryService) CreateAttribute(ctx context.Context, categoryID int64, req model.AttributeDefinitionRequest) (*model.AttributeDefinition, error) {
+	ret := _mock.Called(ctx, categoryID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAttribute")
+	}
+
+	var r0 *model.AttributeDefinition
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.AttributeDefinitionRequest) (*model.AttributeDefinition, error)); ok {
+		return returnFunc(ctx, categoryID, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.AttributeDefinitionRequest) *model.AttributeDefinition); ok {
+		r0 = returnFunc(ctx, categoryID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AttributeDefinition)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.AttributeDefinitionRequest) error); ok {
+		r1 = returnFunc(ctx, categoryID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategoryService_CreateAttribute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAttribute'
+type MockCategoryService_CreateAttribute_Call struct {
+	*mock.Call
+}
+
+// CreateAttribute is a helper method to define mock.On call
+//   - ctx
+//   - categoryID
+//   - req
+func (_e *MockCategoryService_Expecter) CreateAttribute(ctx interface{}, categoryID interface{}, req interface{}) *MockCategoryService_CreateAttribute_Call {
+	return &MockCategoryService_CreateAttribute_Call{Call: _e.mock.On("CreateAttribute", ctx, categoryID, req)}
+}
+
+func (_c *MockCategoryService_CreateAttribute_Call) Run(run func(ctx context.Context, categoryID int64, req model.AttributeDefinitionRequest)) *MockCategoryService_CreateAttribute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.AttributeDefinitionRequest))
+	})
+	return _c
+}
+
+func (_c *MockCategoryService_CreateAttribute_Call) Return(attributeDefinition *model.AttributeDefinition, err error) *MockCategoryService_CreateAttribute_Call {
+	_c.Call.Return(attributeDefinition, err)
+	return _c
+}
+
+func (_c *MockCategoryService_CreateAttribute_Call) RunAndReturn(run func(ctx context.Context, categoryID int64, req model.AttributeDefinitionRequest) (*model.AttributeDefinition, error)) *MockCategoryService_CreateAttribute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateCategory provides a mock function for the type MockCategoryService
+func (_mock *MockCategoryService) CreateCategory(ctx context.Context, req model.CategoryRequest) (*model.Category, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCategory")
+	}
+
+	var r0 *model.Category
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.CategoryRequest) (*model.Category, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.CategoryRequest) *model.Category); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Category)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.CategoryRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategoryService_CreateCategory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateCategory'
+type MockCategoryService_CreateCategory_Call struct {
+	*mock.Call
+}
+
+// CreateCategory is a helper method to define mock.On call
+//   - ctx
+//   - req
+func (_e *MockCategoryService_Expecter) CreateCategory(ctx interface{}, req interface{}) *MockCategoryService_CreateCategory_Call {
+	return &MockCategoryService_CreateCategory_Call{Call: _e.mock.On("CreateCategory", ctx, req)}
+}
+
+func (_c *MockCategoryService_CreateCategory_Call) Run(run func(ctx context.Context, req model.CategoryRequest)) *MockCategoryService_CreateCategory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.CategoryRequest))
+	})
+	return _c
+}
+
+func (_c *MockCategoryService_CreateCategory_Call) Return(category *model.Category, err error) *MockCategoryService_CreateCategory_Call {
+	_c.Call.Return(category, err)
+	return _c
+}
+
+func (_c *MockCategoryService_CreateCategory_Call) RunAndReturn(run func(ctx context.Context, req model.CategoryRequest) (*model.Category, error)) *MockCategoryService_CreateCategory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAttribute provides a mock function for the type MockCategoryService
+func (_mock *MockCategoryService) DeleteAttribute(ctx context.Context, categoryID int64, attributeID int64) error {
+	ret := _mock.Called(ctx, categoryID, attributeID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAttribute")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, categoryID, attributeID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCategoryService_DeleteAttribute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAttribute'
+type MockCategoryService_DeleteAttribute_Call struct {
+	*mock.Call
+}
+
+// DeleteAttribute is a helper method to define mock.On call
+//   - ctx
+//   - categoryID
+//   - attributeID
+func (_e *MockCategoryService_Expecter) DeleteAttribute(ctx interface{}, categoryID interface{}, attributeID interface{}) *MockCategoryService_DeleteAttribute_Call {
+	return &MockCategoryService_DeleteAttribute_Call{Call: _e.mock.On("DeleteAttribute", ctx, categoryID, attributeID)}
+}
+
+func (_c *MockCategoryService_DeleteAttribute_Call) Run(run func(ctx context.Context, categoryID int64, attributeID int64)) *MockCategoryService_DeleteAttribute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockCategoryService_DeleteAttribute_Call) Return(err error) *MockCategoryService_DeleteAttribute_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCategoryService_DeleteAttribute_Call) RunAndReturn(run func(ctx context.Context, categoryID int64, attributeID int64) error) *MockCategoryService_DeleteAttribute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteCategory provides a mock function for the type MockCategoryService
+func (_mock *MockCategoryService) DeleteCategory(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCategory")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCategoryService_DeleteCategory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteCategory'
+type MockCategoryService_DeleteCategory_Call struct {
+	*mock.Call
+}
+
+// DeleteCategory is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockCategoryService_Expecter) DeleteCategory(ctx interface{}, id interface{}) *MockCategoryService_DeleteCategory_Call {
+	return &MockCategoryService_DeleteCategory_Call{Call: _e.mock.On("DeleteCategory", ctx, id)}
+}
+
+func (_c *MockCategoryService_DeleteCategory_Call) Run(run func(ctx context.Context, id int64)) *MockCategoryService_DeleteCategory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockCategoryService_DeleteCategory_Call) Return(err error) *MockCategoryService_DeleteCategory_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCategoryService_DeleteCategory_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockCategoryService_DeleteCategory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCategoryTree provides a mock function for the type MockCategoryService
+func (_mock *MockCategoryService) GetCategoryTree(ctx context.Context) ([]model.Category, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCategoryTree")
+	}
+
+	var r0 []model.Category
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]model.Category, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []model.Category); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Category)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategoryService_GetCategoryTree_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCategoryTree'
+type MockCategoryService_GetCategoryTree_Call struct {
+	*mock.Call
+}
+
+// GetCategoryTree is a helper method to define mock.On call
+//   - ctx
+func (_e *MockCategoryService_Expecter) GetCategoryTree(ctx interface{}) *MockCategoryService_GetCategoryTree_Call {
+	return &MockCategoryService_GetCategoryTree_Call{Call: _e.mock.On("GetCategoryTree", ctx)}
+}
+
+func (_c *MockCategoryService_GetCategoryTree_Call) Run(run func(ctx context.Context)) *MockCategoryService_GetCategoryTree_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockCategoryService_GetCategoryTree_Call) Return(categorys []model.Category, err error) *MockCategoryService_GetCategoryTree_Call {
+	_c.Call.Return(categorys, err)
+	return _c
+}
+
+func (_c *MockCategoryService_GetCategoryTree_Call) RunAndReturn(run func(ctx context.Context) ([]model.Category, error)) *MockCategoryService_GetCategoryTree_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAttributes provides a mock function for the type MockCategoryService
+func (_mock *MockCategoryService) ListAttributes(ctx context.Context, categoryID int64) ([]model.AttributeDefinition, error) {
+	ret := _mock.Called(ctx, categoryID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAttributes")
+	}
+
+	var r0 []model.AttributeDefinition
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.AttributeDefinition, error)); ok {
+		return returnFunc(ctx, categoryID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.AttributeDefinition); ok {
+		r0 = returnFunc(ctx, categoryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AttributeDefinition)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, categoryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategoryService_ListAttributes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAttributes'
+type MockCategoryService_ListAttributes_Call struct {
+	*mock.Call
+}
+
+// ListAttributes is a helper method to define mock.On call
+//   - ctx
+//   - categoryID
+func (_e *MockCategoryService_Expecter) ListAttributes(ctx interface{}, categoryID interface{}) *MockCategoryService_ListAttributes_Call {
+	return &MockCategoryService_ListAttributes_Call{Call: _e.mock.On("ListAttributes", ctx, categoryID)}
+}
+
+func (_c *MockCategoryService_ListAttributes_Call) Run(run func(ctx context.Context, categoryID int64)) *MockCategoryService_ListAttributes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockCategoryService_ListAttributes_Call) Return(attributeDefinitions []model.AttributeDefinition, err error) *MockCategoryService_ListAttributes_Call {
+	_c.Call.Return(attributeDefinitions, err)
+	return _c
+}
+
+func (_c *MockCategoryService_ListAttributes_Call) RunAndReturn(run func(ctx context.Context, categoryID int64) ([]model.AttributeDefinition, error)) *MockCategoryService_ListAttributes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateAttribute provides a mock function for the type MockCategoryService
+func (_mock *MockCategoryService) UpdateAttribute(ctx context.Context, categoryID int64, attributeID int64, req model.AttributeDefinitionRequest) (*model.AttributeDefinition, error) {
+	ret := _mock.Called(ctx, categoryID, attributeID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAttribute")
+	}
+
+	var r0 *model.AttributeDefinition
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, model.AttributeDefinitionRequest) (*model.AttributeDefinition, error)); ok {
+		return returnFunc(ctx, categoryID, attributeID, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, model.AttributeDefinitionRequest) *model.AttributeDefinition); ok {
+		r0 = returnFunc(ctx, categoryID, attributeID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AttributeDefinition)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, model.AttributeDefinitionRequest) error); ok {
+		r1 = returnFunc(ctx, categoryID, attributeID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategoryService_UpdateAttribute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAttribute'
+type MockCategoryService_UpdateAttribute_Call struct {
+	*mock.Call
+}
+
+// UpdateAttribute is a helper method to define mock.On call
+//   - ctx
+//   - categoryID
+//   - attributeID
+//   - req
+func (_e *MockCategoryService_Expecter) UpdateAttribute(ctx interface{}, categoryID interface{}, attributeID interface{}, req interface{}) *MockCategoryService_UpdateAttribute_Call {
+	return &MockCategoryService_UpdateAttribute_Call{Call: _e.mock.On("UpdateAttribute", ctx, categoryID, attributeID, req)}
+}
+
+func (_c *MockCategoryService_UpdateAttribute_Call) Run(run func(ctx context.Context, categoryID int64, attributeID int64, req model.AttributeDefinitionRequest)) *MockCategoryService_UpdateAttribute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(model.AttributeDefinitionRequest))
+	})
+	return _c
+}
+
+func (_c *MockCategoryService_UpdateAttribute_Call) Return(attributeDefinition *model.AttributeDefinition, err error) *MockCategoryService_UpdateAttribute_Call {
+	_c.Call.Return(attributeDefinition, err)
+	return _c
+}
+
+func (_c *MockCategoryService_UpdateAttribute_Call) RunAndReturn(run func(ctx context.Context, categoryID int64, attributeID int64, req model.AttributeDefinitionRequest) (*model.AttributeDefinition, error)) *MockCategoryService_UpdateAttribute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateCategory provides a mock function for the type MockCategoryService
+func (_mock *MockCategoryService) UpdateCategory(ctx context.Context, id int64, req model.CategoryRequest) (*model.Category, error) {
+	ret := _mock.Called(ctx, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCategory")
+	}
+
+	var r0 *model.Category
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.CategoryRequest) (*model.Category, error)); ok {
+		return returnFunc(ctx, id, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.CategoryRequest) *model.Category); ok {
+		r0 = returnFunc(ctx, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Category)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.CategoryRequest) error); ok {
+		r1 = returnFunc(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategoryService_UpdateCategory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateCategory'
+type MockCategoryService_UpdateCategory_Call struct {
+	*mock.Call
+}
+
+// UpdateCategory is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - req
+func (_e *MockCategoryService_Expecter) UpdateCategory(ctx interface{}, id interface{}, req interface{}) *MockCategoryService_UpdateCategory_Call {
+	return &MockCategoryService_UpdateCategory_Call{Call: _e.mock.On("UpdateCategory", ctx, id, req)}
+}
+
+func (_c *MockCategoryService_UpdateCategory_Call) Run(run func(ctx context.Context, id int64, req model.CategoryRequest)) *MockCategoryService_UpdateCategory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.CategoryRequest))
+	})
+	return _c
+}
+
+func (_c *MockCategoryService_UpdateCategory_Call) Return(category *model.Category, err error) *MockCategoryService_UpdateCategory_Call {
+	_c.Call.Return(category, err)
+	return _c
+}
+
+func (_c *MockCategoryService_UpdateCategory_Call) RunAndReturn(run func(ctx context.Context, id int64, req model.CategoryRequest) (*model.Category, error)) *MockCategoryService_UpdateCategory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOIDCService creates a new instance of MockOIDCService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOIDCService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOIDCService {
+	mock := &MockOIDCService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOIDCService is an autogenerated mock type for the OIDCService type
+type MockOIDCService struct {
+	mock.Mock
+}
+
+type MockOIDCService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOIDCService) EXPECT() *MockOIDCService_Expecter {
+	return &MockOIDCService_Expecter{mock: &_m.Mock}
+}
+
+// CompleteLogin provides a mock function for the type MockOIDCService
+func (_mock *MockOIDCService) CompleteLogin(ctx context.Context, provider string, code string, state string) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, provider, code, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteLogin")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, provider, code, state)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, provider, code, state)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = returnFunc(ctx, provider, code, state)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOIDCService_CompleteLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CompleteLogin'
+type MockOIDCService_CompleteLogin_Call struct {
+	*mock.Call
+}
+
+// CompleteLogin is a helper method to define mock.On call
+//   - ctx
+//   - provider
+//   - code
+//   - state
+func (_e *MockOIDCService_Expecter) CompleteLogin(ctx interface{}, provider interface{}, code interface{}, state interface{}) *MockOIDCService_CompleteLogin_Call {
+	return &MockOIDCService_CompleteLogin_Call{Call: _e.mock.On("CompleteLogin", ctx, provider, code, state)}
+}
+
+func (_c *MockOIDCService_CompleteLogin_Call) Run(run func(ctx context.Context, provider string, code string, state string)) *MockOIDCService_CompleteLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockOIDCService_CompleteLogin_Call) Return(tokenPair *model.TokenPair, err error) *MockOIDCService_CompleteLogin_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockOIDCService_CompleteLogin_Call) RunAndReturn(run func(ctx context.Context, provider string, code string, state string) (*model.TokenPair, error)) *MockOIDCService_CompleteLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Providers provides a mock function for the type MockOIDCService
+func (_mock *MockOIDCService) Providers() []string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Providers")
+	}
+
+	var r0 []string
+	if returnFunc, ok := ret.Get(0).(func() []string); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	return r0
+}
+
+// MockOIDCService_Providers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Providers'
+type MockOIDCService_Providers_Call struct {
+	*mock.Call
+}
+
+// Providers is a helper method to define mock.On call
+func (_e *MockOIDCService_Expecter) Providers() *MockOIDCService_Providers_Call {
+	return &MockOIDCService_Providers_Call{Call: _e.mock.On("Providers")}
+}
+
+func (_c *MockOIDCService_Providers_Call) Run(run func()) *MockOIDCService_Providers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockOIDCService_Providers_Call) Return(strings []string) *MockOIDCService_Providers_Call {
+	_c.Call.Return(strings)
+	return _c
+}
+
+func (_c *MockOIDCService_Providers_Call) RunAndReturn(run func() []string) *MockOIDCService_Providers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartLogin provides a mock function for the type MockOIDCService
+func (_mock *MockOIDCService) StartLogin(ctx context.Context, provider string) (string, string, error) {
+	ret := _mock.Called(ctx, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartLogin")
+	}
+
+	var r0 string
+	var r1 string
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, string, error)); ok {
+		return returnFunc(ctx, provider)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, provider)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) string); ok {
+		r1 = returnFunc(ctx, provider)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = returnFunc(ctx, provider)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockOIDCService_StartLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartLogin'
+type MockOIDCService_StartLogin_Call struct {
+	*mock.Call
+}
+
+// StartLogin is a helper method to define mock.On call
+//   - ctx
+//   - provider
+func (_e *MockOIDCService_Expecter) StartLogin(ctx interface{}, provider interface{}) *MockOIDCService_StartLogin_Call {
+	return &MockOIDCService_StartLogin_Call{Call: _e.mock.On("StartLogin", ctx, provider)}
+}
+
+func (_c *MockOIDCService_StartLogin_Call) Run(run func(ctx context.Context, provider string)) *MockOIDCService_StartLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockOIDCService_StartLogin_Call) Return(authURL string, state string, err error) *MockOIDCService_StartLogin_Call {
+	_c.Call.Return(authURL, state, err)
+	return _c
+}
+
+func (_c *MockOIDCService_StartLogin_Call) RunAndReturn(run func(ctx context.Context, provider string) (string, string, error)) *MockOIDCService_StartLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOrderService creates a new instance of MockOrderService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderService {
+	mock := &MockOrderService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderService is an autogenerated mock type for the OrderService type
+type MockOrderService struct {
+	mock.Mock
+}
+
+type MockOrderService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderService) EXPECT() *MockOrderService_Expecter {
+	return &MockOrderService_Expecter{mock: &_m.Mock}
+}
+
+// AcceptReturn provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) AcceptReturn(ctx context.Context, id int64, req model.ResolveReturnRequest, user model.User) (*model.OrderReturn, error) {
+	ret := _mock.Called(ctx, id, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptReturn")
+	}
+
+	var r0 *model.OrderReturn
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ResolveReturnRequest, model.User) (*model.OrderReturn, error)); ok {
+		return returnFunc(ctx, id, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ResolveReturnRequest, model.User) *model.OrderReturn); ok {
+		r0 = returnFunc(ctx, id, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OrderReturn)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.ResolveReturnRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, id, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_AcceptReturn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcceptReturn'
+type MockOrderService_AcceptReturn_Call struct {
+	*mock.Call
+}
+
+// AcceptReturn is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - req
+//   - user
+func (_e *MockOrderService_Expecter) AcceptReturn(ctx interface{}, id interface{}, req interface{}, user interface{}) *MockOrderService_AcceptReturn_Call {
+	return &MockOrderService_AcceptReturn_Call{Call: _e.mock.On("AcceptReturn", ctx, id, req, user)}
+}
+
+func (_c *MockOrderService_AcceptReturn_Call) Run(run func(ctx context.Context, id int64, req model.ResolveReturnRequest, user model.User)) *MockOrderService_AcceptReturn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.ResolveReturnRequest), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_AcceptReturn_Call) Return(orderReturn *model.OrderReturn, err error) *MockOrderService_AcceptReturn_Call {
+	_c.Call.Return(orderReturn, err)
+	return _c
+}
+
+func (_c *MockOrderService_AcceptReturn_Call) RunAndReturn(run func(ctx context.Context, id int64, req model.ResolveReturnRequest, user model.User) (*model.OrderReturn, error)) *MockOrderService_AcceptReturn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CancelOrder provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) CancelOrder(ctx context.Context, id int64, req model.CancelOrderRequest, user model.User) (*model.Order, error) {
+	ret := _mock.Called(ctx, id, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelOrder")
+	}
+
+	var r0 *model.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.CancelOrderRequest, model.User) (*model.Order, error)); ok {
+		return returnFunc(ctx, id, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.CancelOrderRequest, model.User) *model.Order); ok {
+		r0 = returnFunc(ctx, id, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.CancelOrderRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, id, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_CancelOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelOrder'
+type MockOrderService_CancelOrder_Call struct {
+	*mock.Call
+}
+
+// CancelOrder is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - req
+//   - user
+func (_e *MockOrderService_Expecter) CancelOrder(ctx interface{}, id interface{}, req interface{}, user interface{}) *MockOrderService_CancelOrder_Call {
+	return &MockOrderService_CancelOrder_Call{Call: _e.mock.On("CancelOrder", ctx, id, req, user)}
+}
+
+func (_c *MockOrderService_CancelOrder_Call) Run(run func(ctx context.Context, id int64, req model.CancelOrderRequest, user model.User)) *MockOrderService_CancelOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.CancelOrderRequest), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_CancelOrder_Call) Return(order *model.Order, err error) *MockOrderService_CancelOrder_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_CancelOrder_Call) RunAndReturn(run func(ctx context.Context, id int64, req model.CancelOrderRequest, user model.User) (*model.Order, error)) *MockOrderService_CancelOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangeOrderStatus provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ChangeOrderStatus(ctx context.Context, id int64, req model.OrderStatusRequest, user model.User) (*model.Order, error) {
+	ret := _mock.Called(ctx, id, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeOrderStatus")
+	}
+
+	var r0 *model.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.OrderStatusRequest, model.User) (*model.Order, error)); ok {
+		return returnFunc(ctx, id, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.OrderStatusRequest, model.User) *model.Order); ok {
+		r0 = returnFunc(ctx, id, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.OrderStatusRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, id, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ChangeOrderStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangeOrderStatus'
+type MockOrderService_ChangeOrderStatus_Call struct {
+	*mock.Call
+}
+
+// ChangeOrderStatus is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - req
+//   - user
+func (_e *MockOrderService_Expecter) ChangeOrderStatus(ctx interface{}, id interface{}, req interface{}, user interface{}) *MockOrderService_ChangeOrderStatus_Call {
+	return &MockOrderService_ChangeOrderStatus_Call{Call: _e.mock.On("ChangeOrderStatus", ctx, id, req, user)}
+}
+
+func (_c *MockOrderService_ChangeOrderStatus_Call) Run(run func(ctx context.Context, id int64, req model.OrderStatusRequest, user model.User)) *MockOrderService_ChangeOrderStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.OrderStatusRequest), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ChangeOrderStatus_Call) Return(order *model.Order, err error) *MockOrderService_ChangeOrderStatus_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_ChangeOrderStatus_Call) RunAndReturn(run func(ctx context.Context, id int64, req model.OrderStatusRequest, user model.User) (*model.Order, error)) *MockOrderService_ChangeOrderStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Checkout provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) Checkout(ctx context.Context, userID int64) (*model.Order, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Checkout")
+	}
+
+	var r0 *model.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.Order, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.Order); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_Checkout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Checkout'
+type MockOrderService_Checkout_Call struct {
+	*mock.Call
+}
+
+// Checkout is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockOrderService_Expecter) Checkout(ctx interface{}, userID interface{}) *MockOrderService_Checkout_Call {
+	return &MockOrderService_Checkout_Call{Call: _e.mock.On("Checkout", ctx, userID)}
+}
+
+func (_c *MockOrderService_Checkout_Call) Run(run func(ctx context.Context, userID int64)) *MockOrderService_Checkout_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_Checkout_Call) Return(order *model.Order, err error) *MockOrderService_Checkout_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_Checkout_Call) RunAndReturn(run func(ctx context.Context, userID int64) (*model.Order, error)) *MockOrderService_Checkout_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrder provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrder(ctx context.Context, id int64, user model.User) (*model.Order, error) {
+	ret := _mock.Called(ctx, id, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrder")
+	}
+
+	var r0 *model.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.User) (*model.Order, error)); ok {
+		return returnFunc(ctx, id, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.User) *model.Order); ok {
+		r0 = returnFunc(ctx, id, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.User) error); ok {
+		r1 = returnFunc(ctx, id, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrder'
+type MockOrderService_GetOrder_Call struct {
+	*mock.Call
+}
+
+// GetOrder is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - user
+func (_e *MockOrderService_Expecter) GetOrder(ctx interface{}, id interface{}, user interface{}) *MockOrderService_GetOrder_Call {
+	return &MockOrderService_GetOrder_Call{Call: _e.mock.On("GetOrder", ctx, id, user)}
+}
+
+func (_c *MockOrderService_GetOrder_Call) Run(run func(ctx context.Context, id int64, user model.User)) *MockOrderService_GetOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrder_Call) Return(order *model.Order, err error) *MockOrderService_GetOrder_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrder_Call) RunAndReturn(run func(ctx context.Context, id int64, user model.User) (*model.Order, error)) *MockOrderService_GetOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOrders provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 []model.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.OrderFilter) ([]model.Order, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.OrderFilter) []model.Order); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.OrderFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ListOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrders'
+type MockOrderService_ListOrders_Call struct {
+	*mock.Call
+}
+
+// ListOrders is a helper method to define mock.On call
+//   - ctx
+//   - filter
+func (_e *MockOrderService_Expecter) ListOrders(ctx interface{}, filter interface{}) *MockOrderService_ListOrders_Call {
+	return &MockOrderService_ListOrders_Call{Call: _e.mock.On("ListOrders", ctx, filter)}
+}
+
+func (_c *MockOrderService_ListOrders_Call) Run(run func(ctx context.Context, filter model.OrderFilter)) *MockOrderService_ListOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ListOrders_Call) Return(orders []model.Order, err error) *MockOrderService_ListOrders_Call {
+	_c.Call.Return(orders, err)
+	return _c
+}
+
+func (_c *MockOrderService_ListOrders_Call) RunAndReturn(run func(ctx context.Context, filter model.OrderFilter) ([]model.Order, error)) *MockOrderService_ListOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListReturns provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ListReturns(ctx context.Context, filter model.ReturnFilter, user model.User) ([]model.OrderReturn, error) {
+	ret := _mock.Called(ctx, filter, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListReturns")
+	}
+
+	var r0 []model.OrderReturn
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ReturnFilter, model.User) ([]model.OrderReturn, error)); ok {
+		return returnFunc(ctx, filter, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ReturnFilter, model.User) []model.OrderReturn); ok {
+		r0 = returnFunc(ctx, filter, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OrderReturn)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.ReturnFilter, model.User) error); ok {
+		r1 = returnFunc(ctx, filter, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ListReturns_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListReturns'
+type MockOrderService_ListReturns_Call struct {
+	*mock.Call
+}
+
+// ListReturns is a helper method to define mock.On call
+//   - ctx
+//   - filter
+//   - user
+func (_e *MockOrderService_Expecter) ListReturns(ctx interface{}, filter interface{}, user interface{}) *MockOrderService_ListReturns_Call {
+	return &MockOrderService_ListReturns_Call{Call: _e.mock.On("ListReturns", ctx, filter, user)}
+}
+
+func (_c *MockOrderService_ListReturns_Call) Run(run func(ctx context.Context, filter model.ReturnFilter, user model.User)) *MockOrderService_ListReturns_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.ReturnFilter), args[2].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ListReturns_Call) Return(orderReturns []model.OrderReturn, err error) *MockOrderService_ListReturns_Call {
+	_c.Call.Return(orderReturns, err)
+	return _c
+}
+
+func (_c *MockOrderService_ListReturns_Call) RunAndReturn(run func(ctx context.Context, filter model.ReturnFilter, user model.User) ([]model.OrderReturn, error)) *MockOrderService_ListReturns_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RejectReturn provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) RejectReturn(ctx context.Context, id int64, req model.ResolveReturnRequest, user model.User) (*model.OrderReturn, error) {
+	ret := _mock.Called(ctx, id, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RejectReturn")
+	}
+
+	var r0 *model.OrderReturn
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ResolveReturnRequest, model.User) (*model.OrderReturn, error)); ok {
+		return returnFunc(ctx, id, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ResolveReturnRequest, model.User) *model.OrderReturn); ok {
+		r0 = returnFunc(ctx, id, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OrderReturn)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.ResolveReturnRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, id, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_RejectReturn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RejectReturn'
+type MockOrderService_RejectReturn_Call struct {
+	*mock.Call
+}
+
+// RejectReturn is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - req
+//   - user
+func (_e *MockOrderService_Expecter) RejectReturn(ctx interface{}, id interface{}, req interface{}, user interface{}) *MockOrderService_RejectReturn_Call {
+	return &MockOrderService_RejectReturn_Call{Call: _e.mock.On("RejectReturn", ctx, id, req, user)}
+}
+
+func (_c *MockOrderService_RejectReturn_Call) Run(run func(ctx context.Context, id int64, req model.ResolveReturnRequest, user model.User)) *MockOrderService_RejectReturn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.ResolveReturnRequest), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_RejectReturn_Call) Return(orderReturn *model.OrderReturn, err error) *MockOrderService_RejectReturn_Call {
+	_c.Call.Return(orderReturn, err)
+	return _c
+}
+
+func (_c *MockOrderService_RejectReturn_Call) RunAndReturn(run func(ctx context.Context, id int64, req model.ResolveReturnRequest, user model.User) (*model.OrderReturn, error)) *MockOrderService_RejectReturn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReleaseExpiredReservations provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseExpiredReservations")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ReleaseExpiredReservations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReleaseExpiredReservations'
+type MockOrderService_ReleaseExpiredReservations_Call struct {
+	*mock.Call
+}
+
+// ReleaseExpiredReservations is a helper method to define mock.On call
+//   - ctx
+func (_e *MockOrderService_Expecter) ReleaseExpiredReservations(ctx interface{}) *MockOrderService_ReleaseExpiredReservations_Call {
+	return &MockOrderService_ReleaseExpiredReservations_Call{Call: _e.mock.On("ReleaseExpiredReservations", ctx)}
+}
+
+func (_c *MockOrderService_ReleaseExpiredReservations_Call) Run(run func(ctx context.Context)) *MockOrderService_ReleaseExpiredReservations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ReleaseExpiredReservations_Call) Return(n int64, err error) *MockOrderService_ReleaseExpiredReservations_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockOrderService_ReleaseExpiredReservations_Call) RunAndReturn(run func(ctx context.Context) (int64, error)) *MockOrderService_ReleaseExpiredReservations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestReturn provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) RequestReturn(ctx context.Context, orderID int64, req model.ReturnRequest, user model.User) (*model.OrderReturn, error) {
+	ret := _mock.Called(ctx, orderID, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestReturn")
+	}
+
+	var r0 *model.OrderReturn
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ReturnRequest, model.User) (*model.OrderReturn, error)); ok {
+		return returnFunc(ctx, orderID, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ReturnRequest, model.User) *model.OrderReturn); ok {
+		r0 = returnFunc(ctx, orderID, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OrderReturn)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.ReturnRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, orderID, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_RequestReturn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestReturn'
+type MockOrderService_RequestReturn_Call struct {
+	*mock.Call
+}
+
+// RequestReturn is a helper method to define mock.On call
+//   - ctx
+//   - orderID
+//   - req
+//   - user
+func (_e *MockOrderService_Expecter) RequestReturn(ctx interface{}, orderID interface{}, req interface{}, user interface{}) *MockOrderService_RequestReturn_Call {
+	return &MockOrderService_RequestReturn_Call{Call: _e.mock.On("RequestReturn", ctx, orderID, req, user)}
+}
+
+func (_c *MockOrderService_RequestReturn_Call) Run(run func(ctx context.Context, orderID int64, req model.ReturnRequest, user model.User)) *MockOrderService_RequestReturn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.ReturnRequest), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockOrderService_RequestReturn_Call) Return(orderReturn *model.OrderReturn, err error) *MockOrderService_RequestReturn_Call {
+	_c.Call.Return(orderReturn, err)
+	return _c
+}
+
+func (_c *MockOrderService_RequestReturn_Call) RunAndReturn(run func(ctx context.Context, orderID int64, req model.ReturnRequest, user model.User) (*model.OrderReturn, error)) *MockOrderService_RequestReturn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockProductService creates a new instance of MockProductService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockProductService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockProductService {
+	mock := &MockProductService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockProductService is an autogenerated mock type for the ProductService type
+type MockProductService struct {
+	mock.Mock
+}
+
+type MockProductService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockProductService) EXPECT() *MockProductService_Expecter {
+	return &MockProductService_Expecter{mock: &_m.Mock}
+}
+
+// AddToCart provides a mock function for the type MockProductService
+func (_mock *MockProductService) AddToCart(ctx context.Context, productID int64, variantID int64, userID int64, quantity int) (*model.Cart, error) {
+	ret := _mock.Called(ctx, productID, variantID, userID, quantity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddToCart")
+	}
+
+	var r0 *model.Cart
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, int64, int) (*model.Cart, error)); ok {
+		return returnFunc(ctx, productID, variantID, userID, quantity)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, int64, int) *model.Cart); ok {
+		r0 = returnFunc(ctx, productID, variantID, userID, quantity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Cart)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, int64, int) error); ok {
+		r1 = returnFunc(ctx, productID, variantID, userID, quantity)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_AddToCart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddToCart'
+type MockProductService_AddToCart_Call struct {
+	*mock.Call
+}
+
+// AddToCart is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - variantID
+//   - userID
+//   - quantity
+func (_e *MockProductService_Expecter) AddToCart(ctx interface{}, productID interface{}, variantID interface{}, userID interface{}, quantity interface{}) *MockProductService_AddToCart_Call {
+	return &MockProductService_AddToCart_Call{Call: _e.mock.On("AddToCart", ctx, productID, variantID, userID, quantity)}
+}
+
+func (_c *MockProductService_AddToCart_Call) Run(run func(ctx context.Context, productID int64, variantID int64, userID int64, quantity int)) *MockProductService_AddToCart_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(int64), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockProductService_AddToCart_Call) Return(cart *model.Cart, err error) *MockProductService_AddToCart_Call {
+	_c.Call.Return(cart, err)
+	return _c
+}
+
+func (_c *MockProductService_AddToCart_Call) RunAndReturn(run func(ctx context.Context, productID int64, variantID int64, userID int64, quantity int) (*model.Cart, error)) *MockProductService_AddToCart_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BuyProduct provides a mock function for the type MockProductService
+func (_mock *MockProductService) BuyProduct(ctx context.Context, productID int64, variantID int64, userID int64) error {
+	ret := _mock.Called(ctx, productID, variantID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BuyProduct")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, int64) error); ok {
+		r0 = returnFunc(ctx, productID, variantID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockProductService_BuyProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BuyProduct'
+type MockProductService_BuyProduct_Call struct {
+	*mock.Call
+}
+
+// BuyProduct is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - variantID
+//   - userID
+func (_e *MockProductService_Expecter) BuyProduct(ctx interface{}, productID interface{}, variantID interface{}, userID interface{}) *MockProductService_BuyProduct_Call {
+	return &MockProductService_BuyProduct_Call{Call: _e.mock.On("BuyProduct", ctx, productID, variantID, userID)}
+}
+
+func (_c *MockProductService_BuyProduct_Call) Run(run func(ctx context.Context, productID int64, variantID int64, userID int64)) *MockProductService_BuyProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *MockProductService_BuyProduct_Call) Return(err error) *MockProductService_BuyProduct_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockProductService_BuyProduct_Call) RunAndReturn(run func(ctx context.Context, productID int64, variantID int64, userID int64) error) *MockProductService_BuyProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangeProductStatus provides a mock function for the type MockProductService
+func (_mock *MockProductService) ChangeProductStatus(ctx context.Context, productID int64, req model.ProductStatusRequest, user model.User) (*model.ProductStatusRequest, error) {
+	ret := _mock.Called(ctx, productID, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeProductStatus")
+	}
+
+	var r0 *model.ProductStatusRequest
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ProductStatusRequest, model.User) (*model.ProductStatusRequest, error)); ok {
+		return returnFunc(ctx, productID, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ProductStatusRequest, model.User) *model.ProductStatusRequest); ok {
+		r0 = returnFunc(ctx, productID, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductStatusRequest)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.ProductStatusRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, productID, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_ChangeProductStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangeProductStatus'
+type MockProductService_ChangeProductStatus_Call struct {
+	*mock.Call
+}
+
+// ChangeProductStatus is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - req
+//   - user
+func (_e *MockProductService_Expecter) ChangeProductStatus(ctx interface{}, productID interface{}, req interface{}, user interface{}) *MockProductService_ChangeProductStatus_Call {
+	return &MockProductService_ChangeProductStatus_Call{Call: _e.mock.On("ChangeProductStatus", ctx, productID, req, user)}
+}
+
+func (_c *MockProductService_ChangeProductStatus_Call) Run(run func(ctx context.Context, productID int64, req model.ProductStatusRequest, user model.User)) *MockProductService_ChangeProductStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.ProductStatusRequest), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_ChangeProductStatus_Call) Return(productStatusRequest *model.ProductStatusRequest, err error) *MockProductService_ChangeProductStatus_Call {
+	_c.Call.Return(productStatusRequest, err)
+	return _c
+}
+
+func (_c *MockProductService_ChangeProductStatus_Call) RunAndReturn(run func(ctx context.Context, productID int64, req model.ProductStatusRequest, user model.User) (*model.ProductStatusRequest, error)) *MockProductService_ChangeProductStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClearCart provides a mock function for the type MockProductService
+func (_mock *MockProductService) ClearCart(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClearCart")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockProductService_ClearCart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClearCart'
+type MockProductService_ClearCart_Call struct {
+	*mock.Call
+}
+
+// ClearCart is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockProductService_Expecter) ClearCart(ctx interface{}, userID interface{}) *MockProductService_ClearCart_Call {
+	return &MockProductService_ClearCart_Call{Call: _e.mock.On("ClearCart", ctx, userID)}
+}
+
+func (_c *MockProductService_ClearCart_Call) Run(run func(ctx context.Context, userID int64)) *MockProductService_ClearCart_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockProductService_ClearCart_Call) Return(err error) *MockProductService_ClearCart_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockProductService_ClearCart_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockProductService_ClearCart_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateProduct provides a mock function for the type MockProductService
+func (_mock *MockProductService) CreateProduct(ctx context.Context, ProductReq model.CreateProductRequest, seller model.User) (int64, error) {
+	ret := _mock.Called(ctx, ProductReq, seller)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateProduct")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.CreateProductRequest, model.User) (int64, error)); ok {
+		return returnFunc(ctx, ProductReq, seller)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.CreateProductRequest, model.User) int64); ok {
+		r0 = returnFunc(ctx, ProductReq, seller)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.CreateProductRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, ProductReq, seller)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_CreateProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateProduct'
+type MockProductService_CreateProduct_Call struct {
+	*mock.Call
+}
+
+// CreateProduct is a helper method to define mock.On call
+//   - ctx
+//   - ProductReq
+//   - seller
+func (_e *MockProductService_Expecter) CreateProduct(ctx interface{}, ProductReq interface{}, seller interface{}) *MockProductService_CreateProduct_Call {
+	return &MockProductService_CreateProduct_Call{Call: _e.mock.On("CreateProduct", ctx, ProductReq, seller)}
+}
+
+func (_c *MockProductService_CreateProduct_Call) Run(run func(ctx context.Context, ProductReq model.CreateProductRequest, seller model.User)) *MockProductService_CreateProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.CreateProductRequest), args[2].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_CreateProduct_Call) Return(n int64, err error) *MockProductService_CreateProduct_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockProductService_CreateProduct_Call) RunAndReturn(run func(ctx context.Context, ProductReq model.CreateProductRequest, seller model.User) (int64, error)) *MockProductService_CreateProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateVariant provides a mock function for the type MockProductService
+func (_mock *MockProductService) CreateVariant(ctx context.Context, productID int64, req model.VariantRequest, user model.User) (*model.ProductVariant, error) {
+	ret := _mock.Called(ctx, productID, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateVariant")
+	}
+
+	var r0 *model.ProductVariant
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.VariantRequest, model.User) (*model.ProductVariant, error)); ok {
+		return returnFunc(ctx, productID, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.VariantRequest, model.User) *model.ProductVariant); ok {
+		r0 = returnFunc(ctx, productID, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductVariant)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.VariantRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, productID, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_CreateVariant_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateVariant'
+type MockProductService_CreateVariant_Call struct {
+	*mock.Call
+}
+
+// CreateVariant is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - req
+//   - user
+func (_e *MockProductService_Expecter) CreateVariant(ctx interface{}, productID interface{}, req interface{}, user interface{}) *MockProductService_CreateVariant_Call {
+	return &MockProductService_CreateVariant_Call{Call: _e.mock.On("CreateVariant", ctx, productID, req, user)}
+}
+
+func (_c *MockProductService_CreateVariant_Call) Run(run func(ctx context.Context, productID int64, req model.VariantRequest, user model.User)) *MockProductService_CreateVariant_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.VariantRequest), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_CreateVariant_Call) Return(productVariant *model.ProductVariant, err error) *MockProductService_CreateVariant_Call {
+	_c.Call.Return(productVariant, err)
+	return _c
+}
+
+func (_c *MockProductService_CreateVariant_Call) RunAndReturn(run func(ctx context.Context, productID int64, req model.VariantRequest, user model.User) (*model.ProductVariant, error)) *MockProductService_CreateVariant_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteProduct provides a mock function for the type MockProductService
+func (_mock *MockProductService) DeleteProduct(ctx context.Context, id int64, user model.User) error {
+	ret := _mock.Called(ctx, id, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProduct")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.User) error); ok {
+		r0 = returnFunc(ctx, id, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockProductService_DeleteProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProduct'
+type MockProductService_DeleteProduct_Call struct {
+	*mock.Call
+}
+
+// DeleteProduct is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - user
+func (_e *MockProductService_Expecter) DeleteProduct(ctx interface{}, id interface{}, user interface{}) *MockProductService_DeleteProduct_Call {
+	return &MockProductService_DeleteProduct_Call{Call: _e.mock.On("DeleteProduct", ctx, id, user)}
+}
+
+func (_c *MockProductService_DeleteProduct_Call) Run(run func(ctx context.Context, id int64, user model.User)) *MockProductService_DeleteProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_DeleteProduct_Call) Return(err error) *MockProductService_DeleteProduct_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockProductService_DeleteProduct_Call) RunAndReturn(run func(ctx context.Context, id int64, user model.User) error) *MockProductService_DeleteProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteProductImage provides a mock function for the type MockProductService
+func (_mock *MockProductService) DeleteProductImage(ctx context.Context, productID int64, imageID int64, user model.User) error {
+	ret := _mock.Called(ctx, productID, imageID, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProductImage")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, model.User) error); ok {
+		r0 = returnFunc(ctx, productID, imageID, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockProductService_DeleteProductImage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProductImage'
+type MockProductService_DeleteProductImage_Call struct {
+	*mock.Call
+}
+
+// DeleteProductImage is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - imageID
+//   - user
+func (_e *MockProductService_Expecter) DeleteProductImage(ctx interface{}, productID interface{}, imageID interface{}, user interface{}) *MockProductService_DeleteProductImage_Call {
+	return &MockProductService_DeleteProductImage_Call{Call: _e.mock.On("DeleteProductImage", ctx, productID, imageID, user)}
+}
+
+func (_c *MockProductService_DeleteProductImage_Call) Run(run func(ctx context.Context, productID int64, imageID int64, user model.User)) *MockProductService_DeleteProductImage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_DeleteProductImage_Call) Return(err error) *MockProductService_DeleteProductImage_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockProductService_DeleteProductImage_Call) RunAndReturn(run func(ctx context.Context, productID int64, imageID int64, user model.User) error) *MockProductService_DeleteProductImage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteVariant provides a mock function for the type MockProductService
+func (_mock *MockProductService) DeleteVariant(ctx context.Context, productID int64, variantID int64, user model.User) error {
+	ret := _mock.Called(ctx, productID, variantID, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteVariant")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, model.User) error); ok {
+		r0 = returnFunc(ctx, productID, variantID, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockProductService_DeleteVariant_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteVariant'
+type MockProductService_DeleteVariant_Call struct {
+	*mock.Call
+}
+
+// DeleteVariant is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - variantID
+//   - user
+func (_e *MockProductService_Expecter) DeleteVariant(ctx interface{}, productID interface{}, variantID interface{}, user interface{}) *MockProductService_DeleteVariant_Call {
+	return &MockProductService_DeleteVariant_Call{Call: _e.mock.On("DeleteVariant", ctx, productID, variantID, user)}
+}
+
+func (_c *MockProductService_DeleteVariant_Call) Run(run func(ctx context.Context, productID int64, variantID int64, user model.User)) *MockProductService_DeleteVariant_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_DeleteVariant_Call) Return(err error) *MockProductService_DeleteVariant_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockProductService_DeleteVariant_Call) RunAndReturn(run func(ctx context.Context, productID int64, variantID int64, user model.User) error) *MockProductService_DeleteVariant_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCart provides a mock function for the type MockProductService
+func (_mock *MockProductService) GetCart(ctx context.Context, userID int64) (*model.Cart, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCart")
+	}
+
+	var r0 *model.Cart
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.Cart, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.Cart); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Cart)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_GetCart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCart'
+type MockProductService_GetCart_Call struct {
+	*mock.Call
+}
+
+// GetCart is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockProductService_Expecter) GetCart(ctx interface{}, userID interface{}) *MockProductService_GetCart_Call {
+	return &MockProductService_GetCart_Call{Call: _e.mock.On("GetCart", ctx, userID)}
+}
+
+func (_c *MockProductService_GetCart_Call) Run(run func(ctx context.Context, userID int64)) *MockProductService_GetCart_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockProductService_GetCart_Call) Return(cart *model.Cart, err error) *MockProductService_GetCart_Call {
+	_c.Call.Return(cart, err)
+	return _c
+}
+
+func (_c *MockProductService_GetCart_Call) RunAndReturn(run func(ctx context.Context, userID int64) (*model.Cart, error)) *MockProductService_GetCart_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProductByID provides a mock function for the type MockProductService
+func (_mock *MockProductService) GetProductByID(ctx context.Context, id int64) (*model.Product, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProductByID")
+	}
+
+	var r0 *model.Product
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.Product, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.Product); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Product)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_GetProductByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProductByID'
+type MockProductService_GetProductByID_Call struct {
+	*mock.Call
+}
+
+// GetProductByID is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockProductService_Expecter) GetProductByID(ctx interface{}, id interface{}) *MockProductService_GetProductByID_Call {
+	return &MockProductService_GetProductByID_Call{Call: _e.mock.On("GetProductByID", ctx, id)}
+}
+
+func (_c *MockProductService_GetProductByID_Call) Run(run func(ctx context.Context, id int64)) *MockProductService_GetProductByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockProductService_GetProductByID_Call) Return(product *model.Product, err error) *MockProductService_GetProductByID_Call {
+	_c.Call.Return(product, err)
+	return _c
+}
+
+func (_c *MockProductService_GetProductByID_Call) RunAndReturn(run func(ctx context.Context, id int64) (*model.Product, error)) *MockProductService_GetProductByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListProducts provides a mock function for the type MockProductService
+func (_mock *MockProductService) ListProducts(ctx context.Context, filter model.ProductFilter) (*model.ProductPage, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListProducts")
+	}
+
+	var r0 *model.ProductPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ProductFilter) (*model.ProductPage, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ProductFilter) *model.ProductPage); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductPage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.ProductFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_ListProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListProducts'
+type MockProductService_ListProducts_Call struct {
+	*mock.Call
+}
+
+// ListProducts is a helper method to define mock.On call
+//   - ctx
+//   - filter
+func (_e *MockProductService_Expecter) ListProducts(ctx interface{}, filter interface{}) *MockProductService_ListProducts_Call {
+	return &MockProductService_ListProducts_Call{Call: _e.mock.On("ListProducts", ctx, filter)}
+}
+
+func (_c *MockProductService_ListProducts_Call) Run(run func(ctx context.Context, filter model.ProductFilter)) *MockProductService_ListProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.ProductFilter))
+	})
+	return _c
+}
+
+func (_c *MockProductService_ListProducts_Call) Return(productPage *model.ProductPage, err error) *MockProductService_ListProducts_Call {
+	_c.Call.Return(productPage, err)
+	return _c
+}
+
+func (_c *MockProductService_ListProducts_Call) RunAndReturn(run func(ctx context.Context, filter model.ProductFilter) (*model.ProductPage, error)) *MockProductService_ListProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSellerProducts provides a mock function for the type MockProductService
+func (_mock *MockProductService) ListSellerProducts(ctx context.Context, filter model.SellerProductFilter) ([]model.Product, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSellerProducts")
+	}
+
+	var r0 []model.Product
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.SellerProductFilter) ([]model.Product, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.SellerProductFilter) []model.Product); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Product)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.SellerProductFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_ListSellerProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSellerProducts'
+type MockProductService_ListSellerProducts_Call struct {
+	*mock.Call
+}
+
+// ListSellerProducts is a helper method to define mock.On call
+//   - ctx
+//   - filter
+func (_e *MockProductService_Expecter) ListSellerProducts(ctx interface{}, filter interface{}) *MockProductService_ListSellerProducts_Call {
+	return &MockProductService_ListSellerProducts_Call{Call: _e.mock.On("ListSellerProducts", ctx, filter)}
+}
+
+func (_c *MockProductService_ListSellerProducts_Call) Run(run func(ctx context.Context, filter model.SellerProductFilter)) *MockProductService_ListSellerProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.SellerProductFilter))
+	})
+	return _c
+}
+
+func (_c *MockProductService_ListSellerProducts_Call) Return(products []model.Product, err error) *MockProductService_ListSellerProducts_Call {
+	_c.Call.Return(products, err)
+	return _c
+}
+
+func (_c *MockProductService_ListSellerProducts_Call) RunAndReturn(run func(ctx context.Context, filter model.SellerProductFilter) ([]model.Product, error)) *MockProductService_ListSellerProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PublishScheduledProducts provides a mock function for the type MockProductService
+func (_mock *MockProductService) PublishScheduledProducts(ctx context.Context) (int64, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishScheduledProducts")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_PublishScheduledProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PublishScheduledProducts'
+type MockProductService_PublishScheduledProducts_Call struct {
+	*mock.Call
+}
+
+// PublishScheduledProducts is a helper method to define mock.On call
+//   - ctx
+func (_e *MockProductService_Expecter) PublishScheduledProducts(ctx interface{}) *MockProductService_PublishScheduledProducts_Call {
+	return &MockProductService_PublishScheduledProducts_Call{Call: _e.mock.On("PublishScheduledProducts", ctx)}
+}
+
+func (_c *MockProductService_PublishScheduledProducts_Call) Run(run func(ctx context.Context)) *MockProductService_PublishScheduledProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockProductService_PublishScheduledProducts_Call) Return(n int64, err error) *MockProductService_PublishScheduledProducts_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockProductService_PublishScheduledProducts_Call) RunAndReturn(run func(ctx context.Context) (int64, error)) *MockProductService_PublishScheduledProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeDeletedProducts provides a mock function for the type MockProductService
+func (_mock *MockProductService) PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int64, error) {
+	ret := _mock.Called(ctx, retention)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeletedProducts")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Duration) (int64, error)); ok {
+		return returnFunc(ctx, retention)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Duration) int64); ok {
+		r0 = returnFunc(ctx, retention)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = returnFunc(ctx, retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_PurgeDeletedProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeDeletedProducts'
+type MockProductService_PurgeDeletedProducts_Call struct {
+	*mock.Call
+}
+
+// PurgeDeletedProducts is a helper method to define mock.On call
+//   - ctx
+//   - retention
+func (_e *MockProductService_Expecter) PurgeDeletedProducts(ctx interface{}, retention interface{}) *MockProductService_PurgeDeletedProducts_Call {
+	return &MockProductService_PurgeDeletedProducts_Call{Call: _e.mock.On("PurgeDeletedProducts", ctx, retention)}
+}
+
+func (_c *MockProductService_PurgeDeletedProducts_Call) Run(run func(ctx context.Context, retention time.Duration)) *MockProductService_PurgeDeletedProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockProductService_PurgeDeletedProducts_Call) Return(n int64, err error) *MockProductService_PurgeDeletedProducts_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockProductService_PurgeDeletedProducts_Call) RunAndReturn(run func(ctx context.Context, retention time.Duration) (int64, error)) *MockProductService_PurgeDeletedProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveFromCart provides a mock function for the type MockProductService
+func (_mock *MockProductService) RemoveFromCart(ctx context.Context, productID int64, variantID int64, userID int64) (*model.Cart, error) {
+	ret := _mock.Called(ctx, productID, variantID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveFromCart")
+	}
+
+	var r0 *model.Cart
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*model.Cart, error)); ok {
+		return returnFunc(ctx, productID, variantID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *model.Cart); ok {
+		r0 = returnFunc(ctx, productID, variantID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Cart)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = returnFunc(ctx, productID, variantID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_RemoveFromCart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveFromCart'
+type MockProductService_RemoveFromCart_Call struct {
+	*mock.Call
+}
+
+// RemoveFromCart is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - variantID
+//   - userID
+func (_e *MockProductService_Expecter) RemoveFromCart(ctx interface{}, productID interface{}, variantID interface{}, userID interface{}) *MockProductService_RemoveFromCart_Call {
+	return &MockProductService_RemoveFromCart_Call{Call: _e.mock.On("RemoveFromCart", ctx, productID, variantID, userID)}
+}
+
+func (_c *MockProductService_RemoveFromCart_Call) Run(run func(ctx context.Context, productID int64, variantID int64, userID int64)) *MockProductService_RemoveFromCart_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *MockProductService_RemoveFromCart_Call) Return(cart *model.Cart, err error) *MockProductService_RemoveFromCart_Call {
+	_c.Call.Return(cart, err)
+	return _c
+}
+
+func (_c *MockProductService_RemoveFromCart_Call) RunAndReturn(run func(ctx context.Context, productID int64, variantID int64, userID int64) (*model.Cart, error)) *MockProductService_RemoveFromCart_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReorderProductImages provides a mock function for the type MockProductService
+func (_mock *MockProductService) ReorderProductImages(ctx context.Context, productID int64, imageIDs []int64, user model.User) ([]model.ProductImage, error) {
+	ret := _mock.Called(ctx, productID, imageIDs, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReorderProductImages")
+	}
+
+	var r0 []model.ProductImage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, []int64, model.User) ([]model.ProductImage, error)); ok {
+		return returnFunc(ctx, productID, imageIDs, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, []int64, model.User) []model.ProductImage); ok {
+		r0 = returnFunc(ctx, productID, imageIDs, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ProductImage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, []int64, model.User) error); ok {
+		r1 = returnFunc(ctx, productID, imageIDs, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_ReorderProductImages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReorderProductImages'
+type MockProductService_ReorderProductImages_Call struct {
+	*mock.Call
+}
+
+// ReorderProductImages is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - imageIDs
+//   - user
+func (_e *MockProductService_Expecter) ReorderProductImages(ctx interface{}, productID interface{}, imageIDs interface{}, user interface{}) *MockProductService_ReorderProductImages_Call {
+	return &MockProductService_ReorderProductImages_Call{Call: _e.mock.On("ReorderProductImages", ctx, productID, imageIDs, user)}
+}
+
+func (_c *MockProductService_ReorderProductImages_Call) Run(run func(ctx context.Context, productID int64, imageIDs []int64, user model.User)) *MockProductService_ReorderProductImages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].([]int64), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_ReorderProductImages_Call) Return(productImages []model.ProductImage, err error) *MockProductService_ReorderProductImages_Call {
+	_c.Call.Return(productImages, err)
+	return _c
+}
+
+func (_c *MockProductService_ReorderProductImages_Call) RunAndReturn(run func(ctx context.Context, productID int64, imageIDs []int64, user model.User) ([]model.ProductImage, error)) *MockProductService_ReorderProductImages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreProduct provides a mock function for the type MockProductService
+func (_mock *MockProductService) RestoreProduct(ctx context.Context, id int64, user model.User) error {
+	ret := _mock.Called(ctx, id, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreProduct")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.User) error); ok {
+		r0 = returnFunc(ctx, id, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockProductService_RestoreProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreProduct'
+type MockProductService_RestoreProduct_Call struct {
+	*mock.Call
+}
+
+// RestoreProduct is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - user
+func (_e *MockProductService_Expecter) RestoreProduct(ctx interface{}, id interface{}, user interface{}) *MockProductService_RestoreProduct_Call {
+	return &MockProductService_RestoreProduct_Call{Call: _e.mock.On("RestoreProduct", ctx, id, user)}
+}
+
+func (_c *MockProductService_RestoreProduct_Call) Run(run func(ctx context.Context, id int64, user model.User)) *MockProductService_RestoreProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_RestoreProduct_Call) Return(err error) *MockProductService_RestoreProduct_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockProductService_RestoreProduct_Call) RunAndReturn(run func(ctx context.Context, id int64, user model.User) error) *MockProductService_RestoreProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchProducts provides a mock function for the type MockProductService
+func (_mock *MockProductService) SearchProducts(ctx context.Context, filter model.ProductFilter) (*model.ProductSearchPage, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchProducts")
+	}
+
+	var r0 *model.ProductSearchPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ProductFilter) (*model.ProductSearchPage, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.ProductFilter) *model.ProductSearchPage); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductSearchPage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.ProductFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_SearchProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchProducts'
+type MockProductService_SearchProducts_Call struct {
+	*mock.Call
+}
+
+// SearchProducts is a helper method to define mock.On call
+//   - ctx
+//   - filter
+func (_e *MockProductService_Expecter) SearchProducts(ctx interface{}, filter interface{}) *MockProductService_SearchProducts_Call {
+	return &MockProductService_SearchProducts_Call{Call: _e.mock.On("SearchProducts", ctx, filter)}
+}
+
+func (_c *MockProductService_SearchProducts_Call) Run(run func(ctx context.Context, filter model.ProductFilter)) *MockProductService_SearchProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.ProductFilter))
+	})
+	return _c
+}
+
+func (_c *MockProductService_SearchProducts_Call) Return(productSearchPage *model.ProductSearchPage, err error) *MockProductService_SearchProducts_Call {
+	_c.Call.Return(productSearchPage, err)
+	return _c
+}
+
+func (_c *MockProductService_SearchProducts_Call) RunAndReturn(run func(ctx context.Context, filter model.ProductFilter) (*model.ProductSearchPage, error)) *MockProductService_SearchProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateCartItem provides a mock function for the type MockProductService
+func (_mock *MockProductService) UpdateCartItem(ctx context.Context, productID int64, variantID int64, userID int64, quantity int) (*model.Cart, error) {
+	ret := _mock.Called(ctx, productID, variantID, userID, quantity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCartItem")
+	}
+
+	var r0 *model.Cart
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, int64, int) (*model.Cart, error)); ok {
+		return returnFunc(ctx, productID, variantID, userID, quantity)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, int64, int) *model.Cart); ok {
+		r0 = returnFunc(ctx, productID, variantID, userID, quantity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Cart)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, int64, int) error); ok {
+		r1 = returnFunc(ctx, productID, variantID, userID, quantity)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_UpdateCartItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateCartItem'
+type MockProductService_UpdateCartItem_Call struct {
+	*mock.Call
+}
+
+// UpdateCartItem is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - variantID
+//   - userID
+//   - quantity
+func (_e *MockProductService_Expecter) UpdateCartItem(ctx interface{}, productID interface{}, variantID interface{}, userID interface{}, quantity interface{}) *MockProductService_UpdateCartItem_Call {
+	return &MockProductService_UpdateCartItem_Call{Call: _e.mock.On("UpdateCartItem", ctx, productID, variantID, userID, quantity)}
+}
+
+func (_c *MockProductService_UpdateCartItem_Call) Run(run func(ctx context.Context, productID int64, variantID int64, userID int64, quantity int)) *MockProductService_UpdateCartItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(int64), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockProductService_UpdateCartItem_Call) Return(cart *model.Cart, err error) *MockProductService_UpdateCartItem_Call {
+	_c.Call.Return(cart, err)
+	return _c
+}
+
+func (_c *MockProductService_UpdateCartItem_Call) RunAndReturn(run func(ctx context.Context, productID int64, variantID int64, userID int64, quantity int) (*model.Cart, error)) *MockProductService_UpdateCartItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProduct provides a mock function for the type MockProductService
+func (_mock *MockProductService) UpdateProduct(ctx context.Context, productReq model.UpdateProductRequest, productID int64, user model.User) (int64, error) {
+	ret := _mock.Called(ctx, productReq, productID, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProduct")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UpdateProductRequest, int64, model.User) (int64, error)); ok {
+		return returnFunc(ctx, productReq, productID, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UpdateProductRequest, int64, model.User) int64); ok {
+		r0 = returnFunc(ctx, productReq, productID, user)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.UpdateProductRequest, int64, model.User) error); ok {
+		r1 = returnFunc(ctx, productReq, productID, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_UpdateProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProduct'
+type MockProductService_UpdateProduct_Call struct {
+	*mock.Call
+}
+
+// UpdateProduct is a helper method to define mock.On call
+//   - ctx
+//   - productReq
+//   - productID
+//   - user
+func (_e *MockProductService_Expecter) UpdateProduct(ctx interface{}, productReq interface{}, productID interface{}, user interface{}) *MockProductService_UpdateProduct_Call {
+	return &MockProductService_UpdateProduct_Call{Call: _e.mock.On("UpdateProduct", ctx, productReq, productID, user)}
+}
+
+func (_c *MockProductService_UpdateProduct_Call) Run(run func(ctx context.Context, productReq model.UpdateProductRequest, productID int64, user model.User)) *MockProductService_UpdateProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.UpdateProductRequest), args[2].(int64), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_UpdateProduct_Call) Return(n int64, err error) *MockProductService_UpdateProduct_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockProductService_UpdateProduct_Call) RunAndReturn(run func(ctx context.Context, productReq model.UpdateProductRequest, productID int64, user model.User) (int64, error)) *MockProductService_UpdateProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProductImage provides a mock function for the type MockProductService
+func (_mock *MockProductService) UpdateProductImage(ctx context.Context, productID int64, imageID int64, req model.UpdateImageRequest, user model.User) (*model.ProductImage, error) {
+	ret := _mock.Called(ctx, productID, imageID, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProductImage")
+	}
+
+	var r0 *model.ProductImage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, model.UpdateImageRequest, model.User) (*model.ProductImage, error)); ok {
+		return returnFunc(ctx, productID, imageID, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, model.UpdateImageRequest, model.User) *model.ProductImage); ok {
+		r0 = returnFunc(ctx, productID, imageID, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductImage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, model.UpdateImageRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, productID, imageID, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_UpdateProductImage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProductImage'
+type MockProductService_UpdateProductImage_Call struct {
+	*mock.Call
+}
+
+// UpdateProductImage is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - imageID
+//   - req
+//   - user
+func (_e *MockProductService_Expecter) UpdateProductImage(ctx interface{}, productID interface{}, imageID interface{}, req interface{}, user interface{}) *MockProductService_UpdateProductImage_Call {
+	return &MockProductService_UpdateProductImage_Call{Call: _e.mock.On("UpdateProductImage", ctx, productID, imageID, req, user)}
+}
+
+func (_c *MockProductService_UpdateProductImage_Call) Run(run func(ctx context.Context, productID int64, imageID int64, req model.UpdateImageRequest, user model.User)) *MockProductService_UpdateProductImage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(model.UpdateImageRequest), args[4].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_UpdateProductImage_Call) Return(productImage *model.ProductImage, err error) *MockProductService_UpdateProductImage_Call {
+	_c.Call.Return(productImage, err)
+	return _c
+}
+
+func (_c *MockProductService_UpdateProductImage_Call) RunAndReturn(run func(ctx context.Context, productID int64, imageID int64, req model.UpdateImageRequest, user model.User) (*model.ProductImage, error)) *MockProductService_UpdateProductImage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateVariant provides a mock function for the type MockProductService
+func (_mock *MockProductService) UpdateVariant(ctx context.Context, productID int64, variantID int64, req model.VariantRequest, user model.User) (*model.ProductVariant, error) {
+	ret := _mock.Called(ctx, productID, variantID, req, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVariant")
+	}
+
+	var r0 *model.ProductVariant
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, model.VariantRequest, model.User) (*model.ProductVariant, error)); ok {
+		return returnFunc(ctx, productID, variantID, req, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, model.VariantRequest, model.User) *model.ProductVariant); ok {
+		r0 = returnFunc(ctx, productID, variantID, req, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductVariant)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, model.VariantRequest, model.User) error); ok {
+		r1 = returnFunc(ctx, productID, variantID, req, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_UpdateVariant_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateVariant'
+type MockProductService_UpdateVariant_Call struct {
+	*mock.Call
+}
+
+// UpdateVariant is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - variantID
+//   - req
+//   - user
+func (_e *MockProductService_Expecter) UpdateVariant(ctx interface{}, productID interface{}, variantID interface{}, req interface{}, user interface{}) *MockProductService_UpdateVariant_Call {
+	return &MockProductService_UpdateVariant_Call{Call: _e.mock.On("UpdateVariant", ctx, productID, variantID, req, user)}
+}
+
+func (_c *MockProductService_UpdateVariant_Call) Run(run func(ctx context.Context, productID int64, variantID int64, req model.VariantRequest, user model.User)) *MockProductService_UpdateVariant_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(model.VariantRequest), args[4].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_UpdateVariant_Call) Return(productVariant *model.ProductVariant, err error) *MockProductService_UpdateVariant_Call {
+	_c.Call.Return(productVariant, err)
+	return _c
+}
+
+func (_c *MockProductService_UpdateVariant_Call) RunAndReturn(run func(ctx context.Context, productID int64, variantID int64, req model.VariantRequest, user model.User) (*model.ProductVariant, error)) *MockProductService_UpdateVariant_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UploadProductImage provides a mock function for the type MockProductService
+func (_mock *MockProductService) UploadProductImage(ctx context.Context, productID int64, upload model.ImageUpload, user model.User) (*model.ProductImage, error) {
+	ret := _mock.Called(ctx, productID, upload, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadProductImage")
+	}
+
+	var r0 *model.ProductImage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ImageUpload, model.User) (*model.ProductImage, error)); ok {
+		return returnFunc(ctx, productID, upload, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, model.ImageUpload, model.User) *model.ProductImage); ok {
+		r0 = returnFunc(ctx, productID, upload, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductImage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, model.ImageUpload, model.User) error); ok {
+		r1 = returnFunc(ctx, productID, upload, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockProductService_UploadProductImage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UploadProductImage'
+type MockProductService_UploadProductImage_Call struct {
+	*mock.Call
+}
+
+// UploadProductImage is a helper method to define mock.On call
+//   - ctx
+//   - productID
+//   - upload
+//   - user
+func (_e *MockProductService_Expecter) UploadProductImage(ctx interface{}, productID interface{}, upload interface{}, user interface{}) *MockProductService_UploadProductImage_Call {
+	return &MockProductService_UploadProductImage_Call{Call: _e.mock.On("UploadProductImage", ctx, productID, upload, user)}
+}
+
+func (_c *MockProductService_UploadProductImage_Call) Run(run func(ctx context.Context, productID int64, upload model.ImageUpload, user model.User)) *MockProductService_UploadProductImage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(model.ImageUpload), args[3].(model.User))
+	})
+	return _c
+}
+
+func (_c *MockProductService_UploadProductImage_Call) Return(productImage *model.ProductImage, err error) *MockProductService_UploadProductImage_Call {
+	_c.Call.Return(productImage, err)
+	return _c
+}
+
+func (_c *MockProductService_UploadProductImage_Call) RunAndReturn(run func(ctx context.Context, productID int64, upload model.ImageUpload, user model.User) (*model.ProductImage, error)) *MockProductService_UploadProductImage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockUserService creates a new instance of MockUserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockUserService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockUserService {
+	mock := &MockUserService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockUserService is an autogenerated mock type for the UserService type
+type MockUserService struct {
+	mock.Mock
+}
+
+type MockUserService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockUserService) EXPECT() *MockUserService_Expecter {
+	return &MockUserService_Expecter{mock: &_m.Mock}
+}
+
+// ChangePassword provides a mock function for the type MockUserService
+func (_mock *MockUserService) ChangePassword(ctx context.Context, user model.User, oldPassword string, newHashedPassword string) error {
+	ret := _mock.Called(ctx, user, oldPassword, newHashedPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, string, string) error); ok {
+		r0 = returnFunc(ctx, user, oldPassword, newHashedPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_ChangePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangePassword'
+type MockUserService_ChangePassword_Call struct {
+	*mock.Call
+}
+
+// ChangePassword is a helper method to define mock.On call
+//   - ctx
+//   - user
+//   - oldPassword
+//   - newHashedPassword
+func (_e *MockUserService_Expecter) ChangePassword(ctx interface{}, user interface{}, oldPassword interface{}, newHashedPassword interface{}) *MockUserService_ChangePassword_Call {
+	return &MockUserService_ChangePassword_Call{Call: _e.mock.On("ChangePassword", ctx, user, oldPassword, newHashedPassword)}
+}
+
+func (_c *MockUserService_ChangePassword_Call) Run(run func(ctx context.Context, user model.User, oldPassword string, newHashedPassword string)) *MockUserService_ChangePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.User), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ChangePassword_Call) Return(err error) *MockUserService_ChangePassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_ChangePassword_Call) RunAndReturn(run func(ctx context.Context, user model.User, oldPassword string, newHashedPassword string) error) *MockUserService_ChangePassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConfirmEmail provides a mock function for the type MockUserService
+func (_mock *MockUserService) ConfirmEmail(ctx context.Context, token string) error {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_ConfirmEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfirmEmail'
+type MockUserService_ConfirmEmail_Call struct {
+	*mock.Call
+}
+
+// ConfirmEmail is a helper method to define mock.On call
+//   - ctx
+//   - token
+func (_e *MockUserService_Expecter) ConfirmEmail(ctx interface{}, token interface{}) *MockUserService_ConfirmEmail_Call {
+	return &MockUserService_ConfirmEmail_Call{Call: _e.mock.On("ConfirmEmail", ctx, token)}
+}
+
+func (_c *MockUserService_ConfirmEmail_Call) Run(run func(ctx context.Context, token string)) *MockUserService_ConfirmEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ConfirmEmail_Call) Return(err error) *MockUserService_ConfirmEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_ConfirmEmail_Call) RunAndReturn(run func(ctx context.Context, token string) error) *MockUserService_ConfirmEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateUser provides a mock function for the type MockUserService
+func (_mock *MockUserService) CreateUser(ctx context.Context, usr model.UserRegister, hashedPassword string) (int64, error) {
+	ret := _mock.Called(ctx, usr, hashedPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUser")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserRegister, string) (int64, error)); ok {
+		return returnFunc(ctx, usr, hashedPassword)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserRegister, string) int64); ok {
+		r0 = returnFunc(ctx, usr, hashedPassword)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.UserRegister, string) error); ok {
+		r1 = returnFunc(ctx, usr, hashedPassword)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_CreateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUser'
+type MockUserService_CreateUser_Call struct {
+	*mock.Call
+}
+
+// CreateUser is a helper method to define mock.On call
+//   - ctx
+//   - usr
+//   - hashedPassword
+func (_e *MockUserService_Expecter) CreateUser(ctx interface{}, usr interface{}, hashedPassword interface{}) *MockUserService_CreateUser_Call {
+	return &MockUserService_CreateUser_Call{Call: _e.mock.On("CreateUser", ctx, usr, hashedPassword)}
+}
+
+func (_c *MockUserService_CreateUser_Call) Run(run func(ctx context.Context, usr model.UserRegister, hashedPassword string)) *MockUserService_CreateUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.UserRegister), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_CreateUser_Call) Return(n int64, err error) *MockUserService_CreateUser_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockUserService_CreateUser_Call) RunAndReturn(run func(ctx context.Context, usr model.UserRegister, hashedPassword string) (int64, error)) *MockUserService_CreateUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByEmail provides a mock function for the type MockUserService
+func (_mock *MockUserService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByEmail")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.User, error)); ok {
+		return returnFunc(ctx, email)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.User); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_GetUserByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByEmail'
+type MockUserService_GetUserByEmail_Call struct {
+	*mock.Call
+}
+
+// GetUserByEmail is a helper method to define mock.On call
+//   - ctx
+//   - email
+func (_e *MockUserService_Expecter) GetUserByEmail(ctx interface{}, email interface{}) *MockUserService_GetUserByEmail_Call {
+	return &MockUserService_GetUserByEmail_Call{Call: _e.mock.On("GetUserByEmail", ctx, email)}
 }
 
 func (_c *MockUserService_GetUserByEmail_Call) Run(run func(ctx context.Context, email string)) *MockUserService_GetUserByEmail_Call {
@@ -544,26 +3727,85 @@ func (_c *MockUserService_GetUserByEmail_Call) RunAndReturn(run func(ctx context
 	return _c
 }
 
+// IsTokenRevoked provides a mock function for the type MockUserService
+func (_mock *MockUserService) IsTokenRevoked(ctx context.Context, jti string, userID int64, version int64) (bool, error) {
+	ret := _mock.Called(ctx, jti, userID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsTokenRevoked")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64, int64) (bool, error)); ok {
+		return returnFunc(ctx, jti, userID, version)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64, int64) bool); ok {
+		r0 = returnFunc(ctx, jti, userID, version)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int64, int64) error); ok {
+		r1 = returnFunc(ctx, jti, userID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_IsTokenRevoked_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsTokenRevoked'
+type MockUserService_IsTokenRevoked_Call struct {
+	*mock.Call
+}
+
+// IsTokenRevoked is a helper method to define mock.On call
+//   - ctx
+//   - jti
+//   - userID
+//   - version
+func (_e *MockUserService_Expecter) IsTokenRevoked(ctx interface{}, jti interface{}, userID interface{}, version interface{}) *MockUserService_IsTokenRevoked_Call {
+	return &MockUserService_IsTokenRevoked_Call{Call: _e.mock.On("IsTokenRevoked", ctx, jti, userID, version)}
+}
+
+func (_c *MockUserService_IsTokenRevoked_Call) Run(run func(ctx context.Context, jti string, userID int64, version int64)) *MockUserService_IsTokenRevoked_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_IsTokenRevoked_Call) Return(b bool, err error) *MockUserService_IsTokenRevoked_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockUserService_IsTokenRevoked_Call) RunAndReturn(run func(ctx context.Context, jti string, userID int64, version int64) (bool, error)) *MockUserService_IsTokenRevoked_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LoginUser provides a mock function for the type MockUserService
-func (_mock *MockUserService) LoginUser(ctx context.Context, usr model.UserLogin) (string, error) {
-	ret := _mock.Called(ctx, usr)
+func (_mock *MockUserService) LoginUser(ctx context.Context, usr model.UserLogin, clientIP string) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, usr, clientIP)
 
 	if len(ret) == 0 {
 		panic("no return value specified for LoginUser")
 	}
 
-	var r0 string
+	var r0 *model.TokenPair
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserLogin) (string, error)); ok {
-		return returnFunc(ctx, usr)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserLogin, string) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, usr, clientIP)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserLogin) string); ok {
-		r0 = returnFunc(ctx, usr)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.UserLogin, string) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, usr, clientIP)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, model.UserLogin) error); ok {
-		r1 = returnFunc(ctx, usr)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.UserLogin, string) error); ok {
+		r1 = returnFunc(ctx, usr, clientIP)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -578,23 +3820,327 @@ type MockUserService_LoginUser_Call struct {
 // LoginUser is a helper method to define mock.On call
 //   - ctx
 //   - usr
-func (_e *MockUserService_Expecter) LoginUser(ctx interface{}, usr interface{}) *MockUserService_LoginUser_Call {
-	return &MockUserService_LoginUser_Call{Call: _e.mock.On("LoginUser", ctx, usr)}
+//   - clientIP
+func (_e *MockUserService_Expecter) LoginUser(ctx interface{}, usr interface{}, clientIP interface{}) *MockUserService_LoginUser_Call {
+	return &MockUserService_LoginUser_Call{Call: _e.mock.On("LoginUser", ctx, usr, clientIP)}
+}
+
+func (_c *MockUserService_LoginUser_Call) Run(run func(ctx context.Context, usr model.UserLogin, clientIP string)) *MockUserService_LoginUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.UserLogin), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_LoginUser_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_LoginUser_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockUserService_LoginUser_Call) RunAndReturn(run func(ctx context.Context, usr model.UserLogin, clientIP string) (*model.TokenPair, error)) *MockUserService_LoginUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogoutUser provides a mock function for the type MockUserService
+func (_mock *MockUserService) LogoutUser(ctx context.Context, userID int64, jti string, expiresAt time.Time, refreshToken string) error {
+	ret := _mock.Called(ctx, userID, jti, expiresAt, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LogoutUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, time.Time, string) error); ok {
+		r0 = returnFunc(ctx, userID, jti, expiresAt, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_LogoutUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogoutUser'
+type MockUserService_LogoutUser_Call struct {
+	*mock.Call
+}
+
+// LogoutUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - jti
+//   - expiresAt
+//   - refreshToken
+func (_e *MockUserService_Expecter) LogoutUser(ctx interface{}, userID interface{}, jti interface{}, expiresAt interface{}, refreshToken interface{}) *MockUserService_LogoutUser_Call {
+	return &MockUserService_LogoutUser_Call{Call: _e.mock.On("LogoutUser", ctx, userID, jti, expiresAt, refreshToken)}
+}
+
+func (_c *MockUserService_LogoutUser_Call) Run(run func(ctx context.Context, userID int64, jti string, expiresAt time.Time, refreshToken string)) *MockUserService_LogoutUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(time.Time), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_LogoutUser_Call) Return(err error) *MockUserService_LogoutUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_LogoutUser_Call) RunAndReturn(run func(ctx context.Context, userID int64, jti string, expiresAt time.Time, refreshToken string) error) *MockUserService_LogoutUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RefreshToken provides a mock function for the type MockUserService
+func (_mock *MockUserService) RefreshToken(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshToken")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, refreshToken)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_RefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RefreshToken'
+type MockUserService_RefreshToken_Call struct {
+	*mock.Call
+}
+
+// RefreshToken is a helper method to define mock.On call
+//   - ctx
+//   - refreshToken
+func (_e *MockUserService_Expecter) RefreshToken(ctx interface{}, refreshToken interface{}) *MockUserService_RefreshToken_Call {
+	return &MockUserService_RefreshToken_Call{Call: _e.mock.On("RefreshToken", ctx, refreshToken)}
+}
+
+func (_c *MockUserService_RefreshToken_Call) Run(run func(ctx context.Context, refreshToken string)) *MockUserService_RefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RefreshToken_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_RefreshToken_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
 }
 
-func (_c *MockUserService_LoginUser_Call) Run(run func(ctx context.Context, usr model.UserLogin)) *MockUserService_LoginUser_Call {
+func (_c *MockUserService_RefreshToken_Call) RunAndReturn(run func(ctx context.Context, refreshToken string) (*model.TokenPair, error)) *MockUserService_RefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestEmailVerification provides a mock function for the type MockUserService
+func (_mock *MockUserService) RequestEmailVerification(ctx context.Context, email string) error {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestEmailVerification")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RequestEmailVerification_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestEmailVerification'
+type MockUserService_RequestEmailVerification_Call struct {
+	*mock.Call
+}
+
+// RequestEmailVerification is a helper method to define mock.On call
+//   - ctx
+//   - email
+func (_e *MockUserService_Expecter) RequestEmailVerification(ctx interface{}, email interface{}) *MockUserService_RequestEmailVerification_Call {
+	return &MockUserService_RequestEmailVerification_Call{Call: _e.mock.On("RequestEmailVerification", ctx, email)}
+}
+
+func (_c *MockUserService_RequestEmailVerification_Call) Run(run func(ctx context.Context, email string)) *MockUserService_RequestEmailVerification_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RequestEmailVerification_Call) Return(err error) *MockUserService_RequestEmailVerification_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RequestEmailVerification_Call) RunAndReturn(run func(ctx context.Context, email string) error) *MockUserService_RequestEmailVerification_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestPasswordReset provides a mock function for the type MockUserService
+func (_mock *MockUserService) RequestPasswordReset(ctx context.Context, email string) error {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestPasswordReset")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RequestPasswordReset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestPasswordReset'
+type MockUserService_RequestPasswordReset_Call struct {
+	*mock.Call
+}
+
+// RequestPasswordReset is a helper method to define mock.On call
+//   - ctx
+//   - email
+func (_e *MockUserService_Expecter) RequestPasswordReset(ctx interface{}, email interface{}) *MockUserService_RequestPasswordReset_Call {
+	return &MockUserService_RequestPasswordReset_Call{Call: _e.mock.On("RequestPasswordReset", ctx, email)}
+}
+
+func (_c *MockUserService_RequestPasswordReset_Call) Run(run func(ctx context.Context, email string)) *MockUserService_RequestPasswordReset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RequestPasswordReset_Call) Return(err error) *MockUserService_RequestPasswordReset_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RequestPasswordReset_Call) RunAndReturn(run func(ctx context.Context, email string) error) *MockUserService_RequestPasswordReset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetPassword provides a mock function for the type MockUserService
+func (_mock *MockUserService) ResetPassword(ctx context.Context, token string, hashedPassword string) error {
+	ret := _mock.Called(ctx, token, hashedPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, token, hashedPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_ResetPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetPassword'
+type MockUserService_ResetPassword_Call struct {
+	*mock.Call
+}
+
+// ResetPassword is a helper method to define mock.On call
+//   - ctx
+//   - token
+//   - hashedPassword
+func (_e *MockUserService_Expecter) ResetPassword(ctx interface{}, token interface{}, hashedPassword interface{}) *MockUserService_ResetPassword_Call {
+	return &MockUserService_ResetPassword_Call{Call: _e.mock.On("ResetPassword", ctx, token, hashedPassword)}
+}
+
+func (_c *MockUserService_ResetPassword_Call) Run(run func(ctx context.Context, token string, hashedPassword string)) *MockUserService_ResetPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ResetPassword_Call) Return(err error) *MockUserService_ResetPassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_ResetPassword_Call) RunAndReturn(run func(ctx context.Context, token string, hashedPassword string) error) *MockUserService_ResetPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProfile provides a mock function for the type MockUserService
+func (_mock *MockUserService) UpdateProfile(ctx context.Context, user model.User, req model.UpdateProfileRequest) (*model.User, error) {
+	ret := _mock.Called(ctx, user, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProfile")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, model.UpdateProfileRequest) (*model.User, error)); ok {
+		return returnFunc(ctx, user, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.User, model.UpdateProfileRequest) *model.User); ok {
+		r0 = returnFunc(ctx, user, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, model.User, model.UpdateProfileRequest) error); ok {
+		r1 = returnFunc(ctx, user, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_UpdateProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProfile'
+type MockUserService_UpdateProfile_Call struct {
+	*mock.Call
+}
+
+// UpdateProfile is a helper method to define mock.On call
+//   - ctx
+//   - user
+//   - req
+func (_e *MockUserService_Expecter) UpdateProfile(ctx interface{}, user interface{}, req interface{}) *MockUserService_UpdateProfile_Call {
+	return &MockUserService_UpdateProfile_Call{Call: _e.mock.On("UpdateProfile", ctx, user, req)}
+}
+
+func (_c *MockUserService_UpdateProfile_Call) Run(run func(ctx context.Context, user model.User, req model.UpdateProfileRequest)) *MockUserService_UpdateProfile_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(model.UserLogin))
+		run(args[0].(context.Context), args[1].(model.User), args[2].(model.UpdateProfileRequest))
 	})
 	return _c
 }
 
-func (_c *MockUserService_LoginUser_Call) Return(s string, err error) *MockUserService_LoginUser_Call {
-	_c.Call.Return(s, err)
+func (_c *MockUserService_UpdateProfile_Call) Return(user *model.User, err error) *MockUserService_UpdateProfile_Call {
+	_c.Call.Return(user, err)
 	return _c
 }
 
-func (_c *MockUserService_LoginUser_Call) RunAndReturn(run func(ctx context.Context, usr model.UserLogin) (string, error)) *MockUserService_LoginUser_Call {
+func (_c *MockUserService_UpdateProfile_Call) RunAndReturn(run func(ctx context.Context, user model.User, req model.UpdateProfileRequest) (*model.User, error)) *MockUserService_UpdateProfile_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -4,97 +4,454 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/auth"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/mailer"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/pkg/utils"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
-	LoginUser(ctx context.Context, usr model.UserLogin) (string, error)
+	LoginUser(ctx context.Context, usr model.UserLogin, clientIP string) (*model.TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*model.TokenPair, error)
+	LogoutUser(ctx context.Context, userID int64, jti string, expiresAt time.Time, refreshToken string) error
+	IsTokenRevoked(ctx context.Context, jti string, userID, version int64) (bool, error)
 	CreateUser(ctx context.Context, usr model.UserRegister, hashedPassword string) (int64, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	RequestEmailVerification(ctx context.Context, email string) error
+	ConfirmEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, hashedPassword string) error
+	UpdateProfile(ctx context.Context, user model.User, req model.UpdateProfileRequest) (*model.User, error)
+	ChangePassword(ctx context.Context, user model.User, oldPassword, newHashedPassword string) error
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	loginRepo repository.LoginAttemptRepository
+	sessions  sessionIssuer
+	mail      mailer.Mailer
+	authCfg   config.AuthConfig
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
-}
-
-type JWTClaims struct {
-	UserID   int64  `json:"user_id"`
-	UserName string `json:"user_name"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
-	jwt.RegisteredClaims
+func NewUserService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository,
+	loginRepo repository.LoginAttemptRepository, signer auth.Signer, mail mailer.Mailer,
+	authCfg config.AuthConfig) UserService {
+	return &userService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		loginRepo: loginRepo,
+		sessions:  sessionIssuer{tokenRepo: tokenRepo, signer: signer, authCfg: authCfg},
+		mail:      mail,
+		authCfg:   authCfg,
+	}
 }
 
 func (s *userService) CreateUser(ctx context.Context, usr model.UserRegister, hashedPassword string) (int64, error) {
-	return s.userRepo.CreateUser(ctx, FromRequestToModel(usr), hashedPassword)
+	newUser := FromRequestToModel(usr)
+
+	userID, err := s.userRepo.CreateUser(ctx, newUser, hashedPassword)
+	if err != nil {
+		return -1, err
+	}
+
+	// The account exists even if the mail could not be sent: the user can ask for a new link
+	newUser.ID = userID
+	if err := s.sendVerificationEmail(ctx, &newUser); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", userID, err)
+	}
+
+	return userID, nil
 }
 
 func (s *userService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
 	return s.userRepo.GetUserByEmail(ctx, email)
 }
 
-func (s *userService) LoginUser(ctx context.Context, login model.UserLogin) (string, error) {
-	// 1. Get hashed password from repository
+func (s *userService) LoginUser(ctx context.Context, login model.UserLogin, clientIP string) (*model.TokenPair, error) {
+	limits := s.loginLimits(login.Email, clientIP)
+
+	// 1. Refuse to check passwords while the email or the client is locked out
+	if err := s.checkLoginLocks(ctx, limits); err != nil {
+		return nil, err
+	}
+
+	// 2. Get hashed password from repository
 	hashedPassword, err := s.userRepo.GetHashedPassword(ctx, login.Email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, s.registerLoginFailure(ctx, limits)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to get user credentials: %w", err)
+		return nil, fmt.Errorf("failed to get user credentials: %w", err)
 	}
 
-	// 2. Compare password with hash
+	// 3. Compare password with hash
 	err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(login.Password))
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, s.registerLoginFailure(ctx, limits)
+	}
+
+	// Only the email counter is reset: a valid login must not clear the failures of a whole IP
+	if err := s.loginRepo.Reset(ctx, limits[0].subject); err != nil {
+		return nil, err
 	}
 
-	// 3. Get full user details
+	// 4. Get full user details
 	user, err := s.userRepo.GetUserByEmail(ctx, login.Email)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user details: %w", err)
+		return nil, fmt.Errorf("failed to get user details: %w", err)
+	}
+
+	if user.BannedAt != nil {
+		return nil, ErrUserBanned
+	}
+
+	if s.authCfg.RequireEmailVerification && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
 	}
 
-	// 4. Generate JWT token
-	token, err := s.generateJWT(user)
+	// 5. Issue access and refresh tokens
+	return s.sessions.issueTokens(ctx, user)
+}
+
+func (s *userService) RefreshToken(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	// Refresh tokens are single use: consuming it rotates the session
+	userID, version, err := s.tokenRepo.ConsumeRefreshToken(ctx, utils.HashToken(refreshToken))
+	if errors.Is(err, repository.ErrTokenNotFound) {
+		return nil, ErrInvalidToken
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return nil, fmt.Errorf("failed to consume refresh token: %w", err)
 	}
 
-	return token, nil
+	current, err := s.tokenRepo.GetUserTokenVersion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if version < current {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user details: %w", err)
+	}
+
+	if user.BannedAt != nil {
+		return nil, ErrUserBanned
+	}
+
+	return s.sessions.issueTokens(ctx, user)
+}
+
+// LogoutUser revokes the access token and, when it is given, the refresh token of the user.
+// A refresh token of another user is refused and nothing is revoked.
+func (s *userService) LogoutUser(ctx context.Context, userID int64, jti string, expiresAt time.Time,
+	refreshToken string) error {
+
+	if refreshToken != "" {
+		err := s.tokenRepo.RevokeRefreshToken(ctx, utils.HashToken(refreshToken), userID)
+		if errors.Is(err, repository.ErrTokenNotOwned) {
+			return ErrInvalidToken
+		}
+		if err != nil && !errors.Is(err, repository.ErrTokenNotFound) {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	}
+
+	// Keep the revocation entry only while the access token could still be accepted
+	err := s.tokenRepo.RevokeAccessToken(ctx, jti, time.Until(expiresAt))
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
 }
 
-func (s *userService) generateJWT(user *model.User) (string, error) {
-	// Create JWT claims
-	claims := JWTClaims{
-		UserID:   user.ID,
-		UserName: user.UserName,
-		Email:    user.Email,
-		Role:     user.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "HomeBerries",
-		},
+func (s *userService) IsTokenRevoked(ctx context.Context, jti string, userID, version int64) (bool, error) {
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(ctx, jti)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	// All tokens of a user are revoked at once on ban, role or password change
+	current, err := s.tokenRepo.GetUserTokenVersion(ctx, userID)
+	if err != nil {
+		return false, err
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return version < current, nil
+}
+
+func (s *userService) RequestEmailVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		// Do not reveal which emails are registered
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user details: %w", err)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// ConfirmEmail verifies the email the token was sent to. A token sent to an email the user has
+// changed since is invalid.
+func (s *userService) ConfirmEmail(ctx context.Context, token string) error {
+	userID, email, err := s.tokenRepo.ConsumeOneTimeToken(ctx, repository.PurposeEmailVerification,
+		utils.HashToken(token))
+	if errors.Is(err, repository.ErrTokenNotFound) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to consume verification token: %w", err)
+	}
 
-	// Get secret key from environment
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", errors.New("JWT_SECRET not set in environment")
+	err = s.userRepo.MarkEmailVerified(ctx, userID, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrInvalidToken
 	}
 
-	// Sign and get the complete encoded token as string
-	return token.SignedString([]byte(secret))
+	return err
+}
+
+func (s *userService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		// Do not reveal which emails are registered
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user details: %w", err)
+	}
+
+	token, err := s.createOneTimeToken(ctx, repository.PurposePasswordReset, user, s.authCfg.PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("To set a new password open %s/user/password/reset?token=%s\n"+
+			"The link is valid for %s. If you did not ask for a reset, ignore this email.",
+			s.authCfg.AppBaseURL, token, s.authCfg.PasswordResetTokenTTL),
+	})
+}
+
+// ResetPassword sets a new password for the owner of the token. A token sent to an email the user
+// has changed since is invalid.
+func (s *userService) ResetPassword(ctx context.Context, token, hashedPassword string) error {
+	userID, email, err := s.tokenRepo.ConsumeOneTimeToken(ctx, repository.PurposePasswordReset, utils.HashToken(token))
+	if errors.Is(err, repository.ErrTokenNotFound) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user details: %w", err)
+	}
+	if user.Email != email {
+		return ErrInvalidToken
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password must lose access
+	return s.tokenRepo.RevokeUserTokens(ctx, userID)
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, user model.User,
+	req model.UpdateProfileRequest) (*model.User, error) {
+
+	userName, email := user.UserName, user.Email
+	if req.UserName != "" {
+		userName = req.UserName
+	}
+	if req.Email != "" {
+		email = req.Email
+	}
+
+	if userName == user.UserName && email == user.Email {
+		return &user, nil
+	}
+
+	err := s.userRepo.UpdateUserProfile(ctx, user.ID, userName, email)
+	if errors.Is(err, repository.ErrUserAlreadyExists) {
+		return nil, ErrUserAlreadyExists
+	}
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Issued tokens carry the old name and email, and handlers find users by the email claim
+	if err := s.tokenRepo.RevokeUserTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.userRepo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user details: %w", err)
+	}
+
+	if email != user.Email {
+		if err := s.sendVerificationEmail(ctx, updated); err != nil {
+			log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+
+	return updated, nil
+}
+
+func (s *userService) ChangePassword(ctx context.Context, user model.User, oldPassword, newHashedPassword string) error {
+	hashedPassword, err := s.userRepo.GetHashedPassword(ctx, user.Email)
+	if err != nil {
+		return fmt.Errorf("failed to get user credentials: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(oldPassword)); err != nil {
+		return ErrWrongPassword
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, newHashedPassword); err != nil {
+		return err
+	}
+
+	// Other sessions may belong to whoever knew the old password
+	return s.tokenRepo.RevokeUserTokens(ctx, user.ID)
+}
+
+// loginLimit is a failed login counter together with the number of failures it tolerates
+type loginLimit struct {
+	subject     string
+	maxAttempts int
+}
+
+// loginEmailSubject is the key of the failed login counter of an email
+func loginEmailSubject(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// loginLimits returns the email limit first, followed by the client IP limit if the IP is known
+func (s *userService) loginLimits(email, clientIP string) []loginLimit {
+	limits := []loginLimit{{loginEmailSubject(email), s.authCfg.LoginMaxAttempts}}
+	if clientIP != "" {
+		limits = append(limits, loginLimit{"ip:" + clientIP, s.authCfg.LoginMaxAttemptsPerIP})
+	}
+
+	return limits
+}
+
+func (s *userService) checkLoginLocks(ctx context.Context, limits []loginLimit) error {
+	var retryAfter time.Duration
+	for _, l := range limits {
+		lockedFor, err := s.loginRepo.LockedFor(ctx, l.subject)
+		if err != nil {
+			return err
+		}
+		retryAfter = max(retryAfter, lockedFor)
+	}
+
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// registerLoginFailure counts the failure for every limit and locks the ones that are exceeded.
+// It returns the error to report for the failed attempt.
+func (s *userService) registerLoginFailure(ctx context.Context, limits []loginLimit) error {
+	var retryAfter time.Duration
+	for _, l := range limits {
+		failures, err := s.loginRepo.RegisterFailure(ctx, l.subject, s.authCfg.LoginAttemptWindow)
+		if err != nil {
+			return err
+		}
+
+		if l.maxAttempts <= 0 || failures < int64(l.maxAttempts) {
+			continue
+		}
+
+		lockout := s.lockoutDuration(failures - int64(l.maxAttempts))
+		if lockout <= 0 {
+			continue
+		}
+		if err := s.loginRepo.Lock(ctx, l.subject, lockout); err != nil {
+			return err
+		}
+		retryAfter = max(retryAfter, lockout)
+	}
+
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	return ErrInvalidCredentials
+}
+
+// lockoutDuration doubles the base lockout for every failure over the limit
+func (s *userService) lockoutDuration(overLimit int64) time.Duration {
+	lockout := s.authCfg.LoginLockoutBase
+	for i := int64(0); i < overLimit && lockout < s.authCfg.LoginLockoutMax; i++ {
+		lockout *= 2
+	}
+
+	return min(lockout, s.authCfg.LoginLockoutMax)
+}
+
+func (s *userService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	token, err := s.createOneTimeToken(ctx, repository.PurposeEmailVerification, user, s.authCfg.VerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Hi %s! To confirm your email open %s/user/verify/confirm?token=%s\n"+
+			"The link is valid for %s.",
+			user.UserName, s.authCfg.AppBaseURL, token, s.authCfg.VerificationTokenTTL),
+	})
+}
+
+// createOneTimeToken stores the hash of a new random token for the current email of the user
+// and returns the token itself
+func (s *userService) createOneTimeToken(ctx context.Context, purpose string, user *model.User,
+	ttl time.Duration) (string, error) {
+
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s token: %w", purpose, err)
+	}
+
+	if err := s.tokenRepo.SaveOneTimeToken(ctx, purpose, utils.HashToken(token), user.ID, user.Email, ttl); err != nil {
+		return "", err
+	}
+
+	return token, nil
 }
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/mailer"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/model"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
+)
+
+// fakeUserRepo keeps one user in memory, the methods it does not override panic
+type fakeUserRepo struct {
+	repository.UserRepository
+	user     model.User
+	password string
+}
+
+func (r *fakeUserRepo) GetUserByEmail(_ context.Context, email string) (*model.User, error) {
+	if email != r.user.Email {
+		return nil, repository.ErrUserNotFound
+	}
+	user := r.user
+	return &user, nil
+}
+
+func (r *fakeUserRepo) GetUserByID(_ context.Context, id int64) (*model.User, error) {
+	if id != r.user.ID {
+		return nil, repository.ErrUserNotFound
+	}
+	user := r.user
+	return &user, nil
+}
+
+func (r *fakeUserRepo) UpdateUserProfile(_ context.Context, _ int64, userName, email string) error {
+	if email != r.user.Email {
+		r.user.EmailVerifiedAt = nil
+	}
+	r.user.UserName, r.user.Email = userName, email
+	return nil
+}
+
+// MarkEmailVerified only verifies the current email, as the query does
+func (r *fakeUserRepo) MarkEmailVerified(_ context.Context, id int64, email string) error {
+	if id != r.user.ID || email != r.user.Email {
+		return repository.ErrUserNotFound
+	}
+	now := time.Now()
+	r.user.EmailVerifiedAt = &now
+	return nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(_ context.Context, _ int64, passwordHash string) error {
+	r.password = passwordHash
+	return nil
+}
+
+// oneTimeToken is the owner of a one-time token and the email it was sent to
+type oneTimeToken struct {
+	userID int64
+	email  string
+}
+
+// fakeTokenRepo keeps the one-time tokens in memory
+type fakeTokenRepo struct {
+	repository.TokenRepository
+	tokens map[string]oneTimeToken
+}
+
+func (r *fakeTokenRepo) SaveOneTimeToken(_ context.Context, purpose, tokenHash string, userID int64,
+	email string, _ time.Duration) error {
+
+	r.tokens[purpose+"_"+tokenHash] = oneTimeToken{userID, email}
+	return nil
+}
+
+func (r *fakeTokenRepo) ConsumeOneTimeToken(_ context.Context, purpose, tokenHash string) (int64, string, error) {
+	token, ok := r.tokens[purpose+"_"+tokenHash]
+	if !ok {
+		return -1, "", repository.ErrTokenNotFound
+	}
+	delete(r.tokens, purpose+"_"+tokenHash)
+	return token.userID, token.email, nil
+}
+
+func (r *fakeTokenRepo) RevokeUserTokens(context.Context, int64) error {
+	return nil
+}
+
+// fakeMailer returns the token of the last link it was asked to send
+type fakeMailer struct {
+	lastToken string
+}
+
+var tokenInLink = regexp.MustCompile(`token=(\S+)`)
+
+func (m *fakeMailer) Send(_ context.Context, msg mailer.Message) error {
+	m.lastToken = tokenInLink.FindStringSubmatch(msg.Body)[1]
+	return nil
+}
+
+func newTestUserService() (*userService, *fakeUserRepo, *fakeMailer) {
+	users := &fakeUserRepo{user: model.User{ID: 1, UserName: "ivan", Email: "old@example.com"}}
+	tokens := &fakeTokenRepo{tokens: map[string]oneTimeToken{}}
+	mail := &fakeMailer{}
+	svc := NewUserService(users, tokens, nil, nil, mail, config.AuthConfig{
+		AppBaseURL:            "http://localhost",
+		VerificationTokenTTL:  time.Hour,
+		PasswordResetTokenTTL: time.Hour,
+	}).(*userService)
+
+	return svc, users, mail
+}
+
+func TestConfirmEmailAfterEmailChange(t *testing.T) {
+	ctx := context.Background()
+	svc, users, mail := newTestUserService()
+
+	assert.NoError(t, svc.RequestEmailVerification(ctx, "old@example.com"))
+	oldToken := mail.lastToken
+
+	_, err := svc.UpdateProfile(ctx, users.user, model.UpdateProfileRequest{Email: "new@example.com"})
+	assert.NoError(t, err)
+	newToken := mail.lastToken
+
+	assert.ErrorIs(t, svc.ConfirmEmail(ctx, oldToken), ErrInvalidToken)
+	assert.Nil(t, users.user.EmailVerifiedAt)
+
+	assert.NoError(t, svc.ConfirmEmail(ctx, newToken))
+	assert.NotNil(t, users.user.EmailVerifiedAt)
+}
+
+func TestResetPasswordAfterEmailChange(t *testing.T) {
+	ctx := context.Background()
+	svc, users, mail := newTestUserService()
+
+	assert.NoError(t, svc.RequestPasswordReset(ctx, "old@example.com"))
+	oldToken := mail.lastToken
+
+	_, err := svc.UpdateProfile(ctx, users.user, model.UpdateProfileRequest{Email: "new@example.com"})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, svc.ResetPassword(ctx, oldToken, "new-hash"), ErrInvalidToken)
+	assert.Empty(t, users.password)
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStorage keeps uploaded files, such as product images, and serves them at a public URL.
+// Keys are slash separated paths like "products/12/a1b2.jpg".
+type BlobStorage interface {
+	Put(ctx context.Context, key string, r io.Reader) (url string, err error)
+	Delete(ctx context.Context, key string) error
+	// KeyForURL returns the key of a URL returned by Put, ok is false for URLs hosted elsewhere
+	KeyForURL(url string) (key string, ok bool)
+}
+
+func New(driver, dir, publicURL string) (BlobStorage, error) {
+	switch driver {
+	case "", "local":
+		return NewLocalStorage(dir, publicURL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
+
+type localStorage struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalStorage returns a BlobStorage writing files under dir, which a web server
+// such as nginx serves at publicURL
+func NewLocalStorage(dir, publicURL string) (BlobStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating storage directory: %w", err)
+	}
+
+	return &localStorage{dir: dir, publicURL: strings.TrimSuffix(publicURL, "/")}, nil
+}
+
+// Put writes to a temporary file first, so the web server never serves a partial file
+func (s *localStorage) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return "", fmt.Errorf("error creating blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("error writing blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("error writing blob: %w", err)
+	}
+	// Temporary files are private, the web server has to read the blob
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", fmt.Errorf("error writing blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return "", fmt.Errorf("error storing blob: %w", err)
+	}
+
+	return s.publicURL + "/" + key, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *localStorage) KeyForURL(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.publicURL+"/")
+	if !ok {
+		return "", false
+	}
+	if _, err := s.path(key); err != nil {
+		return "", false
+	}
+
+	return key, true
+}
+
+// path returns the file of the key, refusing keys that would leave the storage directory
+func (s *localStorage) path(key string) (string, error) {
+	if key == "" || path.Clean(key) != key || strings.HasPrefix(key, "/") ||
+		key == ".." || strings.HasPrefix(key, "../") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	s, err := New("local", dir, "http://localhost/static/")
+	assert.NoError(t, err)
+
+	url, err := s.Put(ctx, "products/12/photo.jpg", strings.NewReader("jpeg data"))
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost/static/products/12/photo.jpg", url)
+
+	content, err := os.ReadFile(filepath.Join(dir, "products", "12", "photo.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg data", string(content))
+
+	key, ok := s.KeyForURL(url)
+	assert.True(t, ok)
+	assert.Equal(t, "products/12/photo.jpg", key)
+
+	_, ok = s.KeyForURL("https://cdn.example.com/photo.jpg")
+	assert.False(t, ok)
+
+	assert.NoError(t, s.Delete(ctx, key))
+	_, err = os.Stat(filepath.Join(dir, "products", "12", "photo.jpg"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Deleting twice is not an error
+	assert.NoError(t, s.Delete(ctx, key))
+}
+
+func TestLocalStorageRejectsKeysOutsideDir(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "http://localhost/static")
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", "products/../../secret", "a\\b"} {
+		_, err := s.Put(context.Background(), key, strings.NewReader("x"))
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+
+	_, ok := s.KeyForURL("http://localhost/static/../config.go")
+	assert.False(t, ok)
+}
+
+func TestUnknownDriver(t *testing.T) {
+	_, err := New("s3", "", "")
+	assert.Error(t, err)
+}
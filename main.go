@@ -5,13 +5,19 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/auth"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/config"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/controller"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/jobs"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/mailer"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/middleware"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/oidc"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/repository"
 	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/service"
+	"github.com/vvwind/2025-MAI-Backend-V-Vetrov/internal/storage"
 
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -58,23 +64,108 @@ func main() {
 	//Initialize repository with caching
 	productPGRepo := repository.NewPostgresProductRepository(dbPool, rdb)
 	userPGRepo := repository.NewPostgresUserRepository(dbPool)
+	tokenRedisRepo := repository.NewRedisTokenRepository(rdb)
+	loginAttemptRepo := repository.NewRedisLoginAttemptRepository(rdb)
+	apiKeyPGRepo := repository.NewPostgresAPIKeyRepository(dbPool)
+	identityPGRepo := repository.NewPostgresIdentityRepository(dbPool)
+	oidcStateRepo := repository.NewRedisOIDCStateRepository(rdb)
+	categoryPGRepo := repository.NewPostgresCategoryRepository(dbPool)
+	variantPGRepo := repository.NewPostgresVariantRepository(dbPool)
+	imagePGRepo := repository.NewPostgresImageRepository(dbPool)
+	attributePGRepo := repository.NewPostgresAttributeRepository(dbPool)
+	orderPGRepo := repository.NewPostgresOrderRepository(dbPool)
+
+	keyStore, err := loadKeyStore(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Unable to load token signing keys: %v", err)
+	}
+
+	oidcProviders := loadOIDCProviders(cfg.OIDC)
+
+	mail, err := mailer.New(cfg.Mailer.Driver, cfg.Mailer.Dir, cfg.Mailer.From)
+	if err != nil {
+		log.Fatalf("Unable to create mailer: %v", err)
+	}
+
+	blobs, err := storage.New(cfg.Storage.Driver, cfg.Storage.Dir, cfg.Storage.PublicURL)
+	if err != nil {
+		log.Fatalf("Unable to create blob storage: %v", err)
+	}
 
 	// Initialize services
-	productService := service.NewProductService(productPGRepo)
-	userService := service.NewUserService(userPGRepo)
+	productService := service.NewProductService(productPGRepo, categoryPGRepo, variantPGRepo, imagePGRepo,
+		attributePGRepo, blobs, cfg.Storage, cfg.Cart)
+	categoryService := service.NewCategoryService(categoryPGRepo, attributePGRepo)
+	orderService := service.NewOrderService(orderPGRepo, productPGRepo, cfg.Orders)
+	userService := service.NewUserService(userPGRepo, tokenRedisRepo, loginAttemptRepo, keyStore, mail, cfg.Auth)
+	adminService := service.NewAdminService(userPGRepo, productPGRepo, tokenRedisRepo, loginAttemptRepo, cfg.Auth)
+	apiKeyService := service.NewAPIKeyService(apiKeyPGRepo, userPGRepo)
+	accountService := service.NewAccountService(userPGRepo, productPGRepo, orderPGRepo, tokenRedisRepo, cfg.Auth,
+		cfg.Account)
+	oidcService := service.NewOIDCService(oidcProviders, userPGRepo, identityPGRepo, oidcStateRepo,
+		tokenRedisRepo, keyStore, cfg.Auth, cfg.OIDC)
 
 	// Initialize controllers
 	marketplaceController := controller.NewMarketplaceController(productService, userService)
+	adminController := controller.NewAdminController(adminService, userService)
+	categoryController := controller.NewCategoryController(categoryService)
+	orderController := controller.NewOrderController(orderService, userService)
+	accountController := controller.NewAccountController(accountService, userService)
+	apiKeyController := controller.NewAPIKeyController(apiKeyService, userService)
+	jwksController := controller.NewJWKSController(keyStore)
+	oidcController := controller.NewOIDCController(oidcService, strings.HasPrefix(cfg.Auth.AppBaseURL, "https://"))
+
+	// Start background jobs, they stop with the process
+	jobs.Start(context.Background(), jobs.Job{
+		Name:     "publish scheduled products",
+		Interval: cfg.Jobs.PublishInterval,
+		Run: func(ctx context.Context) error {
+			published, err := productService.PublishScheduledProducts(ctx)
+			if published > 0 {
+				log.Printf("Published %d scheduled products", published)
+			}
+			return err
+		},
+	}, jobs.Job{
+		Name:     "purge deleted products",
+		Interval: cfg.Jobs.PurgeInterval,
+		Run: func(ctx context.Context) error {
+			purged, err := productService.PurgeDeletedProducts(ctx, cfg.Jobs.DeletedProductRetention)
+			if purged > 0 {
+				log.Printf("Purged %d deleted products", purged)
+			}
+			return err
+		},
+	}, jobs.Job{
+		Name:     "release expired stock reservations",
+		Interval: cfg.Jobs.ReleaseInterval,
+		Run: func(ctx context.Context) error {
+			released, err := orderService.ReleaseExpiredReservations(ctx)
+			if released > 0 {
+				log.Printf("Cancelled %d orders with expired stock reservations", released)
+			}
+			return err
+		},
+	})
 
 	// Create router
 	router := mux.NewRouter()
 
 	// Register middleware
+	router.Use(middleware.RealIPMiddleware(cfg.Server.TrustedProxies))
 	router.Use(middleware.RecoveryMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 
 	// Register routes
-	marketplaceController.RegisterRoutes(router)
+	authMiddleware := middleware.AuthMiddleware(keyStore, userService, apiKeyService)
+	jwksController.RegisterRoutes(router)
+	oidcController.RegisterRoutes(router)
+	marketplaceController.RegisterRoutes(router, authMiddleware)
+	adminController.RegisterRoutes(router, authMiddleware)
+	categoryController.RegisterRoutes(router, authMiddleware)
+	orderController.RegisterRoutes(router, authMiddleware)
+	accountController.RegisterRoutes(router, authMiddleware)
+	apiKeyController.RegisterRoutes(router, authMiddleware)
 
 	// Start server
 	log.Printf("Server starting on port %s...", cfg.Server.Port)
@@ -82,3 +173,38 @@ func main() {
 		log.Fatalf("Could not start server: %v", err)
 	}
 }
+
+func loadKeyStore(cfg config.AuthConfig) (*auth.KeyStore, error) {
+	if cfg.JWTKeysDir == "" {
+		log.Println("JWT_KEYS_DIR is not set, signing tokens with a temporary key that is lost on restart")
+		return auth.NewEphemeralKeyStore()
+	}
+
+	keyStore, err := auth.LoadKeyStore(cfg.JWTKeysDir, cfg.JWTActiveKeyID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Signing tokens with key %q", keyStore.ActiveKeyID())
+
+	return keyStore, nil
+}
+
+// loadOIDCProviders discovers the configured providers. A provider that cannot be reached
+// is left out, so an outage at the provider does not keep the password login down.
+func loadOIDCProviders(cfg config.OIDCConfig) []*oidc.Provider {
+	providers := make([]*oidc.Provider, 0, len(cfg.Providers))
+	for _, providerCfg := range cfg.Providers {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		provider, err := oidc.NewProvider(ctx, providerCfg, nil)
+		cancel()
+		if err != nil {
+			log.Printf("Login with %s is disabled: %v", providerCfg.Name, err)
+			continue
+		}
+
+		log.Printf("Login with %s is enabled", providerCfg.Name)
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
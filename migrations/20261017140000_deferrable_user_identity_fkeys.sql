@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Profile updates rename users.email/user_name and their copies in one transaction
+ALTER TABLE users_creds ALTER CONSTRAINT users_creds_email_fkey DEFERRABLE INITIALLY IMMEDIATE;
+ALTER TABLE products ALTER CONSTRAINT products_seller_name_fkey DEFERRABLE INITIALLY IMMEDIATE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE products ALTER CONSTRAINT products_seller_name_fkey NOT DEFERRABLE;
+ALTER TABLE users_creds ALTER CONSTRAINT users_creds_email_fkey NOT DEFERRABLE;
+-- +goose StatementEnd
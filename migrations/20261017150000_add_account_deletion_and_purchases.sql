@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+
+-- Deleting a user must never silently take the seller's catalog with it
+ALTER TABLE products DROP CONSTRAINT products_seller_id_fkey;
+ALTER TABLE products ADD CONSTRAINT products_seller_id_fkey
+    FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE products DROP CONSTRAINT products_seller_name_fkey;
+ALTER TABLE products ADD CONSTRAINT products_seller_name_fkey
+    FOREIGN KEY (seller_name) REFERENCES users(user_name) ON DELETE RESTRICT DEFERRABLE INITIALLY IMMEDIATE;
+
+CREATE TABLE IF NOT EXISTS purchases (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id INT REFERENCES products(id) ON DELETE SET NULL,
+    title VARCHAR(100) NOT NULL,
+    price INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS purchases_user_id_idx ON purchases(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS purchases;
+
+ALTER TABLE products DROP CONSTRAINT products_seller_name_fkey;
+ALTER TABLE products ADD CONSTRAINT products_seller_name_fkey
+    FOREIGN KEY (seller_name) REFERENCES users(user_name) ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE;
+ALTER TABLE products DROP CONSTRAINT products_seller_id_fkey;
+ALTER TABLE products ADD CONSTRAINT products_seller_id_fkey
+    FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
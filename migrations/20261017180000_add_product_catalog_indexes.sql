@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keyset pagination sorts by created_at, so it cannot be NULL
+UPDATE products SET created_at = COALESCE(updated_at, NOW()) WHERE created_at IS NULL;
+ALTER TABLE products ALTER COLUMN created_at SET DEFAULT NOW();
+ALTER TABLE products ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS products_catalog_newest_idx ON products(created_at DESC, id DESC) WHERE NOT is_hidden;
+CREATE INDEX IF NOT EXISTS products_catalog_price_idx ON products(price, id) WHERE NOT is_hidden;
+CREATE INDEX IF NOT EXISTS products_catalog_title_idx ON products(title, id) WHERE NOT is_hidden;
+CREATE INDEX IF NOT EXISTS products_seller_id_idx ON products(seller_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS products_seller_id_idx;
+DROP INDEX IF EXISTS products_catalog_title_idx;
+DROP INDEX IF EXISTS products_catalog_price_idx;
+DROP INDEX IF EXISTS products_catalog_newest_idx;
+ALTER TABLE products ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE products ALTER COLUMN created_at DROP DEFAULT;
+-- +goose StatementEnd
@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    parent_id INT REFERENCES categories(id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (parent_id IS NULL OR parent_id <> id)
+);
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories(parent_id);
+
+ALTER TABLE products ADD COLUMN category_id INT REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS products_category_id_idx ON products(category_id);
+
+-- The free text categories become top level categories
+CREATE TEMP TABLE legacy_categories ON COMMIT DROP AS
+SELECT DISTINCT category AS name,
+       trim(both '-' FROM lower(regexp_replace(category, '[^a-zA-Z0-9]+', '-', 'g'))) AS slug,
+       NULL::INT AS category_id
+FROM products
+WHERE category IS NOT NULL AND category <> 'no_category';
+
+INSERT INTO categories (name, slug)
+SELECT DISTINCT ON (slug) left(name, 100), left(slug, 100)
+FROM legacy_categories
+WHERE slug <> ''
+ORDER BY slug, name
+ON CONFLICT (slug) DO NOTHING;
+
+UPDATE legacy_categories l SET category_id = c.id
+FROM categories c
+WHERE l.slug <> '' AND c.slug = left(l.slug, 100);
+
+-- Names without a single latin letter or digit get a slug made of their id
+WITH fallback AS (
+    SELECT nextval(pg_get_serial_sequence('categories', 'id')) AS id, name
+    FROM legacy_categories
+    WHERE category_id IS NULL
+), inserted AS (
+    INSERT INTO categories (id, name, slug)
+    SELECT id, left(name, 100), 'category-' || id FROM fallback
+)
+UPDATE legacy_categories l SET category_id = f.id
+FROM fallback f
+WHERE l.name = f.name;
+
+UPDATE products p SET category_id = l.category_id
+FROM legacy_categories l
+WHERE p.category = l.name;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM legacy_categories WHERE category_id IS NULL) THEN
+        RAISE EXCEPTION 'some product categories could not be mapped, products.category is kept';
+    END IF;
+END $$;
+
+ALTER TABLE products DROP COLUMN IF EXISTS category;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN category TEXT DEFAULT 'no_category';
+UPDATE products p SET category = c.name FROM categories c WHERE c.id = p.category_id;
+
+DROP INDEX IF EXISTS products_category_id_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS categories;
+-- +goose StatementEnd
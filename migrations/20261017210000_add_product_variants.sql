@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    options JSONB NOT NULL DEFAULT '{}',
+    price INTEGER NOT NULL CHECK (price >= 0),
+    amount INTEGER NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- Two variants of a product cannot have the same size and colour
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_options_idx ON product_variants(product_id, options);
+
+ALTER TABLE purchases ADD COLUMN variant_id INT REFERENCES product_variants(id) ON DELETE SET NULL;
+ALTER TABLE purchases ADD COLUMN sku VARCHAR(64);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE purchases DROP COLUMN IF EXISTS sku;
+ALTER TABLE purchases DROP COLUMN IF EXISTS variant_id;
+DROP TABLE IF EXISTS product_variants;
+-- +goose StatementEnd
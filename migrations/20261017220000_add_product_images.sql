@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    alt_text VARCHAR(200) NOT NULL DEFAULT '',
+    position INTEGER NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS product_images_product_id_idx ON product_images(product_id, position);
+-- products.product_image mirrors the primary image, so there can only be one
+CREATE UNIQUE INDEX IF NOT EXISTS product_images_primary_idx ON product_images(product_id) WHERE is_primary;
+
+INSERT INTO product_images (product_id, url, position, is_primary)
+SELECT id, product_image, 0, TRUE FROM products WHERE COALESCE(product_image, '') <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS product_images;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS category_attributes (
+    id SERIAL PRIMARY KEY,
+    category_id INT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    enum_values TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (category_id, code)
+);
+
+-- Values are keyed by attribute code, e.g. {"ram_gb": 16, "gpu": "RTX 3070"}
+ALTER TABLE products ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS products_attributes_idx ON products USING GIN (attributes jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS products_attributes_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS attributes;
+DROP TABLE IF EXISTS category_attributes;
+-- +goose StatementEnd
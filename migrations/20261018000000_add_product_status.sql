@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Existing listings stay live
+ALTER TABLE products ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'scheduled', 'published', 'archived'));
+ALTER TABLE products ADD COLUMN publish_at TIMESTAMP;
+ALTER TABLE products ADD CONSTRAINT products_publish_at_check
+    CHECK ((status = 'scheduled') = (publish_at IS NOT NULL));
+
+-- The scheduled publisher looks for due listings
+CREATE INDEX IF NOT EXISTS products_publish_at_idx ON products(publish_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS products_seller_status_idx ON products(seller_id, status, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS products_seller_status_idx;
+DROP INDEX IF EXISTS products_publish_at_idx;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_publish_at_check;
+ALTER TABLE products DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE products DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
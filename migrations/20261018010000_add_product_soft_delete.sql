@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Deleted products stay until the purge job removes them, so they can be restored
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS products_deleted_at_idx ON products(deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS products_deleted_at_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('pending', 'paid', 'shipped', 'delivered', 'cancelled'));
+
+-- When the order entered each status
+ALTER TABLE orders ADD COLUMN paid_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN shipped_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN delivered_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN cancelled_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS delivered_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipped_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS paid_at;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+-- +goose StatementEnd
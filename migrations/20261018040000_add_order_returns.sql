@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN cancel_reason TEXT;
+ALTER TABLE orders ADD COLUMN cancelled_by INT REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE order_items ADD COLUMN returned_quantity INTEGER NOT NULL DEFAULT 0
+    CHECK (returned_quantity >= 0 AND returned_quantity <= quantity);
+
+-- A return is asked for one line of a delivered order and accepted or rejected by its seller
+CREATE TABLE IF NOT EXISTS order_returns (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    order_item_id INT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    requested_by INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reason TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'accepted', 'rejected')),
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS order_returns_order_item_id_idx ON order_returns(order_item_id);
+CREATE INDEX IF NOT EXISTS order_returns_requested_by_idx ON order_returns(requested_by, created_at DESC);
+
+-- Refunds owed for cancelled paid orders and accepted returns, settled by the payment side
+CREATE TABLE IF NOT EXISTS refunds (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    return_id INT REFERENCES order_returns(id) ON DELETE SET NULL,
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    reason TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS refunds_order_id_idx ON refunds(order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS order_returns;
+ALTER TABLE order_items DROP COLUMN IF EXISTS returned_quantity;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_by;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancel_reason;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Reserved is the stock held by pending orders, which is taken out of amount once the order is paid
+ALTER TABLE products ADD COLUMN reserved INTEGER NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+ALTER TABLE product_variants ADD COLUMN reserved INTEGER NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+
+-- The stock on hand never drops below what pending orders hold
+UPDATE products SET amount = 0 WHERE amount < 0;
+ALTER TABLE products ADD CONSTRAINT products_amount_check CHECK (amount >= 0);
+ALTER TABLE products ADD CONSTRAINT products_reserved_amount_check CHECK (amount >= reserved);
+ALTER TABLE product_variants ADD CONSTRAINT product_variants_reserved_amount_check CHECK (amount >= reserved);
+
+-- A pending order holds the stock of its items until reserved_until. Orders placed before
+-- reservations took their stock right away and have no reserved_until.
+ALTER TABLE orders ADD COLUMN reserved_until TIMESTAMP;
+CREATE INDEX IF NOT EXISTS orders_reserved_until_idx ON orders(reserved_until) WHERE reserved_until IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS orders_reserved_until_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS reserved_until;
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS product_variants_reserved_amount_check;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_reserved_amount_check;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_amount_check;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS reserved;
+ALTER TABLE products DROP COLUMN IF EXISTS reserved;
+-- +goose StatementEnd
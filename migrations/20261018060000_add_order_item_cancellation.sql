@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A seller cancels their own lines of an order shared with other sellers before it is shipped
+ALTER TABLE order_items ADD COLUMN cancelled_at TIMESTAMP;
+ALTER TABLE order_items ADD COLUMN cancel_reason TEXT;
+
+-- Refunds of cancelled lines name the line, refunds of returns name it through their return
+ALTER TABLE refunds ADD COLUMN order_item_id INT REFERENCES order_items(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refunds DROP COLUMN IF EXISTS order_item_id;
+ALTER TABLE order_items DROP COLUMN IF EXISTS cancel_reason;
+ALTER TABLE order_items DROP COLUMN IF EXISTS cancelled_at;
+-- +goose StatementEnd